@@ -11,7 +11,10 @@ import (
 	_ "github.com/micro-plat/hydra/registry/watcher/wvalue"
 
 	_ "github.com/micro-plat/hydra/hydra/cmds/conf"
+	_ "github.com/micro-plat/hydra/hydra/cmds/db"
+	_ "github.com/micro-plat/hydra/hydra/cmds/flags"
 	_ "github.com/micro-plat/hydra/hydra/cmds/install"
+	_ "github.com/micro-plat/hydra/hydra/cmds/registry"
 	_ "github.com/micro-plat/hydra/hydra/cmds/remove"
 	_ "github.com/micro-plat/hydra/hydra/cmds/run"
 	_ "github.com/micro-plat/hydra/hydra/cmds/update"
@@ -51,3 +51,20 @@ func (s *StandardCache) GetCache(names ...string) (c ICache, err error) {
 	}
 	return obj.(ICache), nil
 }
+
+//GetOrLoad 从缓存获取数据,不存在或已过期时调用loader加载并保存,通过WithCacheName指定缓存名称
+func (s *StandardCache) GetOrLoad(key string, expiresAt int, loader Loader, opts ...LoadOption) (string, error) {
+	o := &loadOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	names := []string{}
+	if o.name != "" {
+		names = append(names, o.name)
+	}
+	c, err := s.GetCache(names...)
+	if err != nil {
+		return "", err
+	}
+	return GetOrLoad(c, key, expiresAt, loader, opts...)
+}
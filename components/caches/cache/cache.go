@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type ICacheExt interface {
@@ -23,6 +25,53 @@ type ICache interface {
 	Close() error
 }
 
+//IExtendCache 扩展缓存接口,通过类型断言获取:
+//if ext, ok := c.(cache.IExtendCache); ok {...}
+type IExtendCache interface {
+	ICache
+
+	//GetTTL 获取剩余过期时间,永不过期时返回0,key不存在时返回-1
+	GetTTL(key string) (time.Duration, error)
+
+	//CompareAndSwap 当前值与old相同时更新为new,old为空表示key不存在
+	CompareAndSwap(key string, old string, new string, expiresAt int) (bool, error)
+
+	//CompareAndDelete 当前值与old相同时删除
+	CompareAndDelete(key string, old string) (bool, error)
+
+	//GetObject 获取json数据并转换为对象,key不存在时返回false
+	GetObject(key string, v interface{}) (bool, error)
+
+	//SetObject 将对象转换为json后保存
+	SetObject(key string, v interface{}, expiresAt int) error
+
+	HGet(key string, field string) (string, error)
+	HSet(key string, field string, value string) error
+	HDel(key string, fields ...string) error
+	SAdd(key string, members ...string) error
+	SMembers(key string) ([]string, error)
+}
+
+//Marshal 将对象转换为json串
+func Marshal(v interface{}) (string, error) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("缓存对象转换为json失败:%w", err)
+	}
+	return string(buff), nil
+}
+
+//Unmarshal 将json串转换为对象,内容为空时返回false
+func Unmarshal(value string, v interface{}) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, fmt.Errorf("缓存数据不是有效的json:%w", err)
+	}
+	return true, nil
+}
+
 //Resover 定义配置文件转换方法
 type Resover interface {
 	Resolve(conf string) (ICache, error)
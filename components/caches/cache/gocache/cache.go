@@ -2,6 +2,7 @@ package gocache
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -9,12 +10,15 @@ import (
 	"github.com/micro-plat/hydra/components/caches/cache"
 	vargocache "github.com/micro-plat/hydra/conf/vars/cache/gocache"
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/types"
 	gocache "github.com/zkfy/go-cache"
 )
 
 //Proto Proto
 const Proto = "gocache"
 
+var _ cache.IExtendCache = &Client{}
+
 // Client redis配置文件
 type Client struct {
 	lock    sync.Mutex
@@ -54,7 +58,7 @@ func (c *Client) Get(key string) (string, error) {
 	if !ok {
 		return "", nil
 	}
-	return v.(string), nil
+	return types.GetString(v), nil
 }
 
 //Decrement 增加变量的值
@@ -101,11 +105,8 @@ func (c *Client) procInt64val(key string) (err error) {
 func (c *Client) Gets(key ...string) (r []string, err error) {
 	r = make([]string, 0, len(key))
 	for _, k := range key {
-		v, ok := c.client.Get(k)
-		if !ok {
-			v = ""
-		}
-		r = append(r, v.(string))
+		v, _ := c.client.Get(k)
+		r = append(r, types.GetString(v))
 	}
 	return r, nil
 
@@ -162,3 +163,174 @@ func (s *cacheResolver) Resolve(conf string) (cache.ICache, error) {
 func init() {
 	cache.Register("gocache", &cacheResolver{})
 }
+
+//GetTTL 获取剩余过期时间,永不过期时返回0,key不存在时返回-1
+func (c *Client) GetTTL(key string) (time.Duration, error) {
+	_, exp, ok := c.client.GetWithExpiration(key)
+	if !ok {
+		return -1, nil
+	}
+	if exp.IsZero() {
+		return 0, nil
+	}
+	return time.Until(exp), nil
+}
+
+//CompareAndSwap 当前值与old相同时更新为new,old为空表示key不存在
+func (c *Client) CompareAndSwap(key string, old string, new string, expiresAt int) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	v, ok := c.client.Get(key)
+	if (!ok && old != "") || (ok && types.GetString(v) != old) {
+		return false, nil
+	}
+	c.client.Set(key, new, time.Second*time.Duration(expiresAt))
+	return true, nil
+}
+
+//CompareAndDelete 当前值与old相同时删除
+func (c *Client) CompareAndDelete(key string, old string) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	v, ok := c.client.Get(key)
+	if !ok || types.GetString(v) != old {
+		return false, nil
+	}
+	c.client.Delete(key)
+	return true, nil
+}
+
+//GetObject 获取json数据并转换为对象,key不存在时返回false
+func (c *Client) GetObject(key string, v interface{}) (bool, error) {
+	value, err := c.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return cache.Unmarshal(value, v)
+}
+
+//SetObject 将对象转换为json后保存
+func (c *Client) SetObject(key string, v interface{}, expiresAt int) error {
+	value, err := cache.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, value, expiresAt)
+}
+
+//HGet 获取hash字段的值,字段不存在时返回空
+func (c *Client) HGet(key string, field string) (string, error) {
+	h, err := c.getHash(key)
+	if err != nil {
+		return "", err
+	}
+	return h[field], nil
+}
+
+//HSet 设置hash字段的值
+func (c *Client) HSet(key string, field string, value string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	h, err := c.getHash(key)
+	if err != nil {
+		return err
+	}
+	nh := make(map[string]string, len(h)+1)
+	for k, v := range h {
+		nh[k] = v
+	}
+	nh[field] = value
+	c.replace(key, nh)
+	return nil
+}
+
+//HDel 删除hash字段
+func (c *Client) HDel(key string, fields ...string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	h, err := c.getHash(key)
+	if err != nil || len(h) == 0 {
+		return err
+	}
+	nh := make(map[string]string, len(h))
+	for k, v := range h {
+		nh[k] = v
+	}
+	for _, f := range fields {
+		delete(nh, f)
+	}
+	c.replace(key, nh)
+	return nil
+}
+
+//SAdd 添加set成员
+func (c *Client) SAdd(key string, members ...string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	s, err := c.getSet(key)
+	if err != nil {
+		return err
+	}
+	ns := make(map[string]struct{}, len(s)+len(members))
+	for k := range s {
+		ns[k] = struct{}{}
+	}
+	for _, m := range members {
+		ns[m] = struct{}{}
+	}
+	c.replace(key, ns)
+	return nil
+}
+
+//SMembers 获取set全部成员
+func (c *Client) SMembers(key string) ([]string, error) {
+	s, err := c.getSet(key)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]string, 0, len(s))
+	for k := range s {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return r, nil
+}
+
+//getHash 获取hash数据,hash与set修改时替换为新对象,读取时无需加锁
+func (c *Client) getHash(key string) (map[string]string, error) {
+	v, ok := c.client.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	h, ok := v.(map[string]string)
+	if !ok {
+		return nil, fmt.Errorf("%s的值不是hash类型", key)
+	}
+	return h, nil
+}
+
+func (c *Client) getSet(key string) (map[string]struct{}, error) {
+	v, ok := c.client.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	s, ok := v.(map[string]struct{})
+	if !ok {
+		return nil, fmt.Errorf("%s的值不是set类型", key)
+	}
+	return s, nil
+}
+
+//replace 替换数据并保留原过期时间
+func (c *Client) replace(key string, v interface{}) {
+	_, exp, ok := c.client.GetWithExpiration(key)
+	if !ok {
+		c.client.SetDefault(key, v)
+		return
+	}
+	if exp.IsZero() {
+		c.client.Set(key, v, gocache.NoExpiration)
+		return
+	}
+	c.client.Set(key, v, time.Until(exp))
+}
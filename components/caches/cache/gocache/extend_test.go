@@ -0,0 +1,116 @@
+package gocache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestClient_CompareAndSwap(t *testing.T) {
+	c, _ := NewByOpts()
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want bool
+	}{
+		{name: "1. key不存在时旧值为空", old: "", new: "1", want: true},
+		{name: "2. key已存在时旧值为空", old: "", new: "2", want: false},
+		{name: "3. 旧值不一致", old: "2", new: "3", want: false},
+		{name: "4. 旧值一致", old: "1", new: "3", want: true},
+	}
+	for _, tt := range tests {
+		ok, err := c.CompareAndSwap("cas", tt.old, tt.new, 60)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.want, ok, tt.name)
+	}
+	v, _ := c.Get("cas")
+	assert.Equal(t, "3", v, "最终值")
+}
+
+func TestClient_CompareAndDelete(t *testing.T) {
+	c, _ := NewByOpts()
+	c.Set("cad", "1", 60)
+	tests := []struct {
+		name string
+		old  string
+		want bool
+	}{
+		{name: "1. 旧值不一致", old: "2", want: false},
+		{name: "2. 旧值一致", old: "1", want: true},
+		{name: "3. key不存在", old: "1", want: false},
+	}
+	for _, tt := range tests {
+		ok, err := c.CompareAndDelete("cad", tt.old)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.want, ok, tt.name)
+	}
+	assert.Equal(t, false, c.Exists("cad"), "已删除")
+}
+
+func TestClient_GetTTL(t *testing.T) {
+	c, _ := NewByOpts()
+	c.Set("ttl", "1", 60)
+	c.client.Set("forever", "1", -1)
+	tests := []struct {
+		name string
+		key  string
+		min  time.Duration
+		max  time.Duration
+	}{
+		{name: "1. key不存在", key: "none", min: -1, max: -1},
+		{name: "2. 永不过期", key: "forever", min: 0, max: 0},
+		{name: "3. 有过期时间", key: "ttl", min: time.Second * 59, max: time.Second * 60},
+	}
+	for _, tt := range tests {
+		ttl, err := c.GetTTL(tt.key)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, true, ttl >= tt.min && ttl <= tt.max, tt.name, ttl)
+	}
+}
+
+func TestClient_Object(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	c, _ := NewByOpts()
+	assert.Equal(t, nil, c.SetObject("user", &user{Name: "colin", Age: 18}, 60), "保存对象")
+	u := &user{}
+	ok, err := c.GetObject("user", u)
+	assert.Equal(t, nil, err, "获取对象")
+	assert.Equal(t, true, ok, "对象存在")
+	assert.Equal(t, &user{Name: "colin", Age: 18}, u, "对象内容")
+
+	ok, err = c.GetObject("none", u)
+	assert.Equal(t, nil, err, "获取不存在的对象")
+	assert.Equal(t, false, ok, "对象不存在")
+
+	c.Set("str", "abc", 60)
+	_, err = c.GetObject("str", u)
+	assert.NotEqual(t, nil, err, "非json数据")
+}
+
+func TestClient_HashAndSet(t *testing.T) {
+	c, _ := NewByOpts()
+	assert.Equal(t, nil, c.HSet("h", "a", "1"), "设置hash字段")
+	assert.Equal(t, nil, c.HSet("h", "b", "2"), "设置hash字段")
+	v, _ := c.HGet("h", "a")
+	assert.Equal(t, "1", v, "获取hash字段")
+	assert.Equal(t, nil, c.HDel("h", "a"), "删除hash字段")
+	v, _ = c.HGet("h", "a")
+	assert.Equal(t, "", v, "已删除的hash字段")
+	v, _ = c.HGet("h", "b")
+	assert.Equal(t, "2", v, "未删除的hash字段")
+
+	assert.Equal(t, nil, c.SAdd("s", "b", "a", "b"), "添加set成员")
+	members, err := c.SMembers("s")
+	assert.Equal(t, nil, err, "获取set成员")
+	assert.Equal(t, []string{"a", "b"}, members, "set成员去重")
+
+	_, err = c.HGet("s", "a")
+	assert.NotEqual(t, nil, err, "类型不一致")
+	_, err = c.SMembers("h")
+	assert.NotEqual(t, nil, err, "类型不一致")
+}
@@ -13,6 +13,8 @@ import (
 //Proto Proto
 const Proto = "memcached"
 
+var _ cache.IExtendCache = &Client{}
+
 // Client memcache配置文件
 type Client struct {
 	servers []string
@@ -155,6 +157,109 @@ func (c *Client) Close() error {
 	return nil
 }
 
+//GetTTL memcached不支持获取过期时间
+func (c *Client) GetTTL(key string) (time.Duration, error) {
+	return 0, errNotSupport("GetTTL")
+}
+
+//CompareAndSwap 当前值与old相同时更新为new,old为空表示key不存在
+func (c *Client) CompareAndSwap(key string, old string, new string, expiresAt int) (bool, error) {
+	item, err := c.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		if old != "" {
+			return false, nil
+		}
+		err = c.Add(key, new, expiresAt)
+		if err == memcache.ErrNotStored {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	if err != nil {
+		return false, err
+	}
+	if string(item.Value) != old {
+		return false, nil
+	}
+	item.Value = []byte(new)
+	item.Expiration = int32(expiresAt)
+	err = c.client.CompareAndSwap(item)
+	if err == memcache.ErrCASConflict || err == memcache.ErrNotStored {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//CompareAndDelete 当前值与old相同时删除,通过cas将数据设置为立即过期
+func (c *Client) CompareAndDelete(key string, old string) (bool, error) {
+	item, err := c.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if string(item.Value) != old {
+		return false, nil
+	}
+	item.Expiration = -1
+	err = c.client.CompareAndSwap(item)
+	if err == memcache.ErrCASConflict || err == memcache.ErrNotStored || err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//GetObject 获取json数据并转换为对象,key不存在时返回false
+func (c *Client) GetObject(key string, v interface{}) (bool, error) {
+	value, err := c.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return cache.Unmarshal(value, v)
+}
+
+//SetObject 将对象转换为json后保存
+func (c *Client) SetObject(key string, v interface{}, expiresAt int) error {
+	value, err := cache.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, value, expiresAt)
+}
+
+//HGet memcached不支持hash
+func (c *Client) HGet(key string, field string) (string, error) {
+	return "", errNotSupport("HGet")
+}
+
+//HSet memcached不支持hash
+func (c *Client) HSet(key string, field string, value string) error {
+	return errNotSupport("HSet")
+}
+
+//HDel memcached不支持hash
+func (c *Client) HDel(key string, fields ...string) error {
+	return errNotSupport("HDel")
+}
+
+//SAdd memcached不支持set
+func (c *Client) SAdd(key string, members ...string) error {
+	return errNotSupport("SAdd")
+}
+
+//SMembers memcached不支持set
+func (c *Client) SMembers(key string) ([]string, error) {
+	return nil, errNotSupport("SMembers")
+}
+
+func errNotSupport(method string) error {
+	return fmt.Errorf("memcached不支持%s操作", method)
+}
+
 type mresolver struct {
 }
 
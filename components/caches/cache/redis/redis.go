@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	goredis "github.com/go-redis/redis"
 	"github.com/micro-plat/hydra/components/caches/cache"
 	"github.com/micro-plat/hydra/components/pkgs/redis"
 	"github.com/micro-plat/hydra/conf/vars/cache/cacheredis"
@@ -14,6 +15,8 @@ import (
 //Proto Proto
 const Proto = "redis"
 
+var _ cache.IExtendCache = &Client{}
+
 // Client redis配置文件
 type Client struct {
 	servers []string
@@ -127,6 +130,9 @@ func (c *Client) Delete(key string) error {
 		}
 		return nil
 	}
+	if _, ok := c.client.UniversalClient.(*goredis.ClusterClient); ok {
+		return c.deleteFromCluster(key)
+	}
 	_, err := c.client.Eval(`
     local keys=redis.call('KEYS',KEYS[1])
     if (#keys==0) then
@@ -136,6 +142,19 @@ func (c *Client) Delete(key string) error {
 	return err
 }
 
+//deleteFromCluster 集群模式下逐个主节点查找并删除匹配的KEY,避免跨slot删除
+func (c *Client) deleteFromCluster(pattern string) error {
+	return c.client.ForEachNode(func(node goredis.Cmdable) error {
+		iter := node.Scan(0, pattern, 100).Iterator()
+		for iter.Next() {
+			if err := c.client.Del(iter.Val()).Err(); err != nil {
+				return fmt.Errorf("%v(%s)", err, iter.Val())
+			}
+		}
+		return iter.Err()
+	})
+}
+
 //Exists 查询指定的KEY是否存在
 func (c *Client) Exists(key string) bool {
 	r, err := c.client.Exists(key).Result()
@@ -166,3 +185,107 @@ func (s *redisResolver) Resolve(configData string) (cache.ICache, error) {
 func init() {
 	cache.Register(Proto, &redisResolver{})
 }
+
+//casScript 当前值与旧值相同时更新,旧值为空表示key不存在
+const casScript = `
+local v = redis.call('GET', KEYS[1])
+if (v == false and ARGV[1] == '') or v == ARGV[1] then
+	if tonumber(ARGV[3]) > 0 then
+		redis.call('SET', KEYS[1], ARGV[2], 'EX', ARGV[3])
+	else
+		redis.call('SET', KEYS[1], ARGV[2])
+	end
+	return 1
+end
+return 0`
+
+//cadScript 当前值与旧值相同时删除
+const cadScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+//GetTTL 获取剩余过期时间,永不过期时返回0,key不存在时返回-1
+func (c *Client) GetTTL(key string) (time.Duration, error) {
+	ttl, err := c.client.TTL(key).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch ttl {
+	case -time.Second:
+		return 0, nil
+	case -2 * time.Second:
+		return -1, nil
+	}
+	return ttl, nil
+}
+
+//CompareAndSwap 当前值与old相同时更新为new,old为空表示key不存在
+func (c *Client) CompareAndSwap(key string, old string, new string, expiresAt int) (bool, error) {
+	n, err := c.client.Eval(casScript, []string{key}, old, new, expiresAt).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+//CompareAndDelete 当前值与old相同时删除
+func (c *Client) CompareAndDelete(key string, old string) (bool, error) {
+	n, err := c.client.Eval(cadScript, []string{key}, old).Int64()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+//GetObject 获取json数据并转换为对象,key不存在时返回false
+func (c *Client) GetObject(key string, v interface{}) (bool, error) {
+	value, err := c.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return cache.Unmarshal(value, v)
+}
+
+//SetObject 将对象转换为json后保存
+func (c *Client) SetObject(key string, v interface{}, expiresAt int) error {
+	value, err := cache.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, value, expiresAt)
+}
+
+//HGet 获取hash字段的值,字段不存在时返回空
+func (c *Client) HGet(key string, field string) (string, error) {
+	v, err := c.client.HGet(key, field).Result()
+	if err == goredis.Nil {
+		return "", nil
+	}
+	return v, err
+}
+
+//HSet 设置hash字段的值
+func (c *Client) HSet(key string, field string, value string) error {
+	return c.client.HSet(key, field, value).Err()
+}
+
+//HDel 删除hash字段
+func (c *Client) HDel(key string, fields ...string) error {
+	return c.client.HDel(key, fields...).Err()
+}
+
+//SAdd 添加set成员
+func (c *Client) SAdd(key string, members ...string) error {
+	values := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		values = append(values, m)
+	}
+	return c.client.SAdd(key, values...).Err()
+}
+
+//SMembers 获取set全部成员
+func (c *Client) SMembers(key string) ([]string, error) {
+	return c.client.SMembers(key).Result()
+}
@@ -0,0 +1,56 @@
+package tiered
+
+import (
+	"github.com/micro-plat/hydra/components/pkgs/redis"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+)
+
+//notifier 失效通知的发布与订阅
+type notifier interface {
+	Publish(channel string, message string) error
+	Subscribe(channel string, callback func(message string)) error
+	Close() error
+}
+
+//redisNotifier 基于redis发布订阅的失效通知
+type redisNotifier struct {
+	client *redis.Client
+	closer []func() error
+}
+
+func newRedisNotifier(cfg *varredis.Redis) (*redisNotifier, error) {
+	client, err := redis.NewByConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &redisNotifier{client: client}, nil
+}
+
+//Publish 发布消息
+func (n *redisNotifier) Publish(channel string, message string) error {
+	return n.client.Publish(channel, message).Err()
+}
+
+//Subscribe 订阅消息,连接断开后由客户端自动重新订阅
+func (n *redisNotifier) Subscribe(channel string, callback func(message string)) error {
+	ps := n.client.Subscribe(channel)
+	if _, err := ps.Receive(); err != nil {
+		ps.Close()
+		return err
+	}
+	n.closer = append(n.closer, ps.Close)
+	go func() {
+		for m := range ps.Channel() {
+			callback(m.Payload)
+		}
+	}()
+	return nil
+}
+
+//Close 关闭订阅与连接
+func (n *redisNotifier) Close() error {
+	for _, c := range n.closer {
+		c()
+	}
+	return n.client.Close()
+}
@@ -0,0 +1,350 @@
+package tiered
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/components/caches/cache"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/vars/cache/cacheredis"
+	vartiered "github.com/micro-plat/hydra/conf/vars/cache/tiered"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/utility"
+	gocache "github.com/zkfy/go-cache"
+)
+
+//Proto Proto
+const Proto = vartiered.Proto
+
+var _ cache.IExtendCache = &Client{}
+
+//Client 二级缓存,读取时优先使用本地缓存,修改时同步修改二级缓存并通知其它节点删除本地缓存
+type Client struct {
+	id      string
+	l1      *gocache.Cache
+	l2      cache.ICache
+	n       notifier
+	channel string
+	expires time.Duration
+	log     logger.ILogger
+}
+
+//invalidation 失效通知消息
+type invalidation struct {
+	Node string   `json:"node"`
+	Keys []string `json:"keys"`
+}
+
+//NewByConfig 根据配置创建二级缓存,二级缓存与失效通知使用的redis从var配置中获取
+func NewByConfig(cfg *vartiered.Tiered) (m *Client, err error) {
+	varConf, err := app.Cache.GetVarConf()
+	if err != nil {
+		return nil, err
+	}
+	l2conf, err := cfg.GetL2Conf(varConf)
+	if err != nil {
+		return nil, err
+	}
+	proto := l2conf.GetString("proto")
+	var rcfg *varredis.Redis
+	switch {
+	case cfg.Redis != "":
+		if rcfg, err = varredis.GetConf(varConf, cfg.Redis); err != nil {
+			return nil, err
+		}
+	case proto == "redis":
+		rcfg = varredis.NewByRaw(cacheredis.NewByRaw(string(l2conf.GetRaw())).GetRaw())
+	default:
+		return nil, fmt.Errorf("二级缓存%s的类型为%s,需指定发送失效通知的redis", cfg.L2, proto)
+	}
+	l2, err := cache.New(proto, string(l2conf.GetRaw()))
+	if err != nil {
+		return nil, err
+	}
+	n, err := newRedisNotifier(rcfg)
+	if err != nil {
+		l2.Close()
+		return nil, err
+	}
+	if m, err = newClient(cfg, l2, n); err != nil {
+		l2.Close()
+		n.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func newClient(cfg *vartiered.Tiered, l2 cache.ICache, n notifier) (*Client, error) {
+	expires := time.Duration(cfg.Expiration) * time.Second
+	if expires <= 0 {
+		expires = time.Minute
+	}
+	c := &Client{
+		id:      utility.GetGUID(),
+		l1:      gocache.New(expires, expires*2),
+		l2:      l2,
+		n:       n,
+		channel: cfg.Channel,
+		expires: expires,
+		log:     logger.New("cache.tiered"),
+	}
+	if err := n.Subscribe(c.channel, c.receive); err != nil {
+		return nil, fmt.Errorf("订阅缓存失效通知失败:%w", err)
+	}
+	return c, nil
+}
+
+//GetServers 获取服务器列表
+func (c *Client) GetServers() []string {
+	if ext, ok := c.l2.(cache.ICacheExt); ok {
+		return ext.GetServers()
+	}
+	return nil
+}
+
+//GetProto 获取服务类型
+func (c *Client) GetProto() string {
+	return Proto
+}
+
+//Get 获取数据,本地缓存不存在时从二级缓存获取并保存到本地
+func (c *Client) Get(key string) (string, error) {
+	if v, ok := c.l1.Get(key); ok {
+		return v.(string), nil
+	}
+	v, err := c.l2.Get(key)
+	if err != nil || v == "" {
+		return v, err
+	}
+	c.l1.Set(key, v, c.expires)
+	return v, nil
+}
+
+//Decrement 减少变量的值
+func (c *Client) Decrement(key string, delta int64) (n int64, err error) {
+	if n, err = c.l2.Decrement(key, delta); err != nil {
+		return
+	}
+	c.invalidate(key)
+	return
+}
+
+//Increment 增加变量的值
+func (c *Client) Increment(key string, delta int64) (n int64, err error) {
+	if n, err = c.l2.Increment(key, delta); err != nil {
+		return
+	}
+	c.invalidate(key)
+	return
+}
+
+//Gets 获取多条数据,直接从二级缓存获取
+func (c *Client) Gets(key ...string) (r []string, err error) {
+	return c.l2.Gets(key...)
+}
+
+//Add 添加数据,已存在时报错
+func (c *Client) Add(key string, value string, expiresAt int) error {
+	if err := c.l2.Add(key, value, expiresAt); err != nil {
+		return err
+	}
+	c.invalidate(key)
+	return nil
+}
+
+//Set 更新数据,没有则添加
+func (c *Client) Set(key string, value string, expiresAt int) error {
+	if err := c.l2.Set(key, value, expiresAt); err != nil {
+		return err
+	}
+	c.invalidate(key)
+	c.l1.Set(key, value, c.getExpires(expiresAt))
+	return nil
+}
+
+//Delete 删除指定的key,key包含*时清空本地缓存
+func (c *Client) Delete(key string) error {
+	if err := c.l2.Delete(key); err != nil {
+		return err
+	}
+	c.invalidate(key)
+	return nil
+}
+
+//Exists 查询key是否存在
+func (c *Client) Exists(key string) bool {
+	if _, ok := c.l1.Get(key); ok {
+		return true
+	}
+	return c.l2.Exists(key)
+}
+
+//Delay 延长数据在二级缓存中的时间
+func (c *Client) Delay(key string, expiresAt int) error {
+	return c.l2.Delay(key, expiresAt)
+}
+
+//Close 关闭失效通知与二级缓存
+func (c *Client) Close() error {
+	c.n.Close()
+	c.l1.Flush()
+	return c.l2.Close()
+}
+
+//GetTTL 获取二级缓存中的剩余过期时间
+func (c *Client) GetTTL(key string) (time.Duration, error) {
+	ext, err := c.extend()
+	if err != nil {
+		return 0, err
+	}
+	return ext.GetTTL(key)
+}
+
+//CompareAndSwap 当前值与old相同时更新为new,更新成功后通知其它节点
+func (c *Client) CompareAndSwap(key string, old string, new string, expiresAt int) (bool, error) {
+	ext, err := c.extend()
+	if err != nil {
+		return false, err
+	}
+	ok, err := ext.CompareAndSwap(key, old, new, expiresAt)
+	if ok {
+		c.invalidate(key)
+	}
+	return ok, err
+}
+
+//CompareAndDelete 当前值与old相同时删除
+func (c *Client) CompareAndDelete(key string, old string) (bool, error) {
+	ext, err := c.extend()
+	if err != nil {
+		return false, err
+	}
+	ok, err := ext.CompareAndDelete(key, old)
+	if ok {
+		c.invalidate(key)
+	}
+	return ok, err
+}
+
+//GetObject 获取json数据并转换为对象,key不存在时返回false
+func (c *Client) GetObject(key string, v interface{}) (bool, error) {
+	value, err := c.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return cache.Unmarshal(value, v)
+}
+
+//SetObject 将对象转换为json后保存
+func (c *Client) SetObject(key string, v interface{}, expiresAt int) error {
+	value, err := cache.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, value, expiresAt)
+}
+
+//HGet 获取hash字段的值,hash数据不进行本地缓存
+func (c *Client) HGet(key string, field string) (string, error) {
+	ext, err := c.extend()
+	if err != nil {
+		return "", err
+	}
+	return ext.HGet(key, field)
+}
+
+//HSet 设置hash字段的值
+func (c *Client) HSet(key string, field string, value string) error {
+	ext, err := c.extend()
+	if err != nil {
+		return err
+	}
+	return ext.HSet(key, field, value)
+}
+
+//HDel 删除hash字段
+func (c *Client) HDel(key string, fields ...string) error {
+	ext, err := c.extend()
+	if err != nil {
+		return err
+	}
+	return ext.HDel(key, fields...)
+}
+
+//SAdd 添加set成员,set数据不进行本地缓存
+func (c *Client) SAdd(key string, members ...string) error {
+	ext, err := c.extend()
+	if err != nil {
+		return err
+	}
+	return ext.SAdd(key, members...)
+}
+
+//SMembers 获取set全部成员
+func (c *Client) SMembers(key string) ([]string, error) {
+	ext, err := c.extend()
+	if err != nil {
+		return nil, err
+	}
+	return ext.SMembers(key)
+}
+
+func (c *Client) extend() (cache.IExtendCache, error) {
+	if ext, ok := c.l2.(cache.IExtendCache); ok {
+		return ext, nil
+	}
+	return nil, fmt.Errorf("二级缓存不支持扩展操作")
+}
+
+//getExpires 本地缓存时间不超过二级缓存的过期时间
+func (c *Client) getExpires(expiresAt int) time.Duration {
+	expires := time.Duration(expiresAt) * time.Second
+	if expiresAt > 0 && expires < c.expires {
+		return expires
+	}
+	return c.expires
+}
+
+//invalidate 删除本地缓存并通知其它节点
+func (c *Client) invalidate(keys ...string) {
+	c.remove(keys...)
+	buff, _ := json.Marshal(&invalidation{Node: c.id, Keys: keys})
+	if err := c.n.Publish(c.channel, string(buff)); err != nil {
+		c.log.Errorf("发送缓存失效通知失败:%v", err)
+	}
+}
+
+func (c *Client) receive(message string) {
+	msg := &invalidation{}
+	if err := json.Unmarshal([]byte(message), msg); err != nil {
+		c.log.Errorf("缓存失效通知格式有误:%v", err)
+		return
+	}
+	if msg.Node == c.id {
+		return
+	}
+	c.remove(msg.Keys...)
+}
+
+func (c *Client) remove(keys ...string) {
+	for _, key := range keys {
+		if strings.Contains(key, "*") {
+			c.l1.Flush()
+			return
+		}
+		c.l1.Delete(key)
+	}
+}
+
+type cacheResolver struct {
+}
+
+func (s *cacheResolver) Resolve(conf string) (cache.ICache, error) {
+	return NewByConfig(vartiered.NewByRaw(conf))
+}
+func init() {
+	cache.Register(Proto, &cacheResolver{})
+}
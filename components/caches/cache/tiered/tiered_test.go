@@ -0,0 +1,99 @@
+package tiered
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/micro-plat/hydra/components/caches/cache/gocache"
+	vartiered "github.com/micro-plat/hydra/conf/vars/cache/tiered"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//bus 进程内的发布订阅,用于测试
+type bus struct {
+	lock sync.Mutex
+	subs map[string][]func(string)
+}
+
+func (b *bus) Publish(channel string, message string) error {
+	b.lock.Lock()
+	subs := b.subs[channel]
+	b.lock.Unlock()
+	for _, f := range subs {
+		f(message)
+	}
+	return nil
+}
+
+func (b *bus) Subscribe(channel string, callback func(message string)) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.subs[channel] = append(b.subs[channel], callback)
+	return nil
+}
+
+func (b *bus) Close() error {
+	return nil
+}
+
+func newNodes(t *testing.T) (*Client, *Client) {
+	l2, err := gocache.NewByOpts()
+	assert.Equal(t, nil, err, "创建二级缓存")
+	b := &bus{subs: map[string][]func(string){}}
+	cfg := vartiered.New("redis")
+	n1, err := newClient(cfg, l2, b)
+	assert.Equal(t, nil, err, "创建节点1")
+	n2, err := newClient(cfg, l2, b)
+	assert.Equal(t, nil, err, "创建节点2")
+	return n1, n2
+}
+
+func TestClient_Invalidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		change func(c *Client, key string) error
+		want   string
+	}{
+		{name: "1. 修改数据", key: "k1", change: func(c *Client, key string) error { return c.Set(key, "2", 0) }, want: "2"},
+		{name: "2. 删除数据", key: "k2", change: func(c *Client, key string) error { return c.Delete(key) }, want: ""},
+		{name: "3. 模糊删除数据", key: "k3", change: func(c *Client, key string) error { return c.Delete("k*") }, want: "1"},
+		{name: "4. 增加数值", key: "k4", change: func(c *Client, key string) error { _, err := c.Increment(key, 1); return err }, want: "2"},
+		{name: "5. 减少数值", key: "k5", change: func(c *Client, key string) error { _, err := c.Decrement(key, 1); return err }, want: "0"},
+	}
+	for _, tt := range tests {
+		n1, n2 := newNodes(t)
+		assert.Equal(t, nil, n1.Set(tt.key, "1", 0), tt.name)
+		v, err := n2.Get(tt.key)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, "1", v, tt.name, "节点2缓存到本地")
+		_, ok := n2.l1.Get(tt.key)
+		assert.Equal(t, true, ok, tt.name, "节点2本地缓存存在")
+
+		assert.Equal(t, nil, tt.change(n1, tt.key), tt.name)
+		_, ok = n2.l1.Get(tt.key)
+		assert.Equal(t, false, ok, tt.name, "节点2本地缓存已失效")
+		if tt.want == "1" {
+			continue //gocache不支持模糊删除,仅验证本地缓存失效
+		}
+		v, err = n2.Get(tt.key)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.want, v, tt.name, "节点2获取到最新数据")
+	}
+}
+
+func TestClient_Expires(t *testing.T) {
+	n1, _ := newNodes(t)
+	tests := []struct {
+		name      string
+		expiresAt int
+		want      int
+	}{
+		{name: "1. 未设置过期时间使用本地缓存过期时间", expiresAt: 0, want: 60},
+		{name: "2. 过期时间小于本地缓存过期时间", expiresAt: 10, want: 10},
+		{name: "3. 过期时间大于本地缓存过期时间", expiresAt: 120, want: 60},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, int(n1.getExpires(tt.expiresAt).Seconds()), tt.name)
+	}
+}
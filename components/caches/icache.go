@@ -5,8 +5,12 @@ import "github.com/micro-plat/hydra/components/caches/cache"
 //ICache 缓存接口
 type ICache = cache.ICache
 
+//IExtendCache 扩展缓存接口,支持过期时间查询、CAS、对象、hash与set操作
+type IExtendCache = cache.IExtendCache
+
 //IComponentCache Component Cache
 type IComponentCache interface {
 	GetRegularCache(names ...string) (c ICache)
 	GetCache(names ...string) (c ICache, err error)
+	GetOrLoad(key string, expiresAt int, loader Loader, opts ...LoadOption) (string, error)
 }
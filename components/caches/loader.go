@@ -0,0 +1,195 @@
+package caches
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//Loader 缓存数据加载函数
+type Loader func() (string, error)
+
+//LoadOption 加载选项
+type LoadOption func(*loadOption)
+
+type loadOption struct {
+	name  string
+	lock  int
+	stale int
+	beta  float64
+}
+
+//WithCacheName 指定缓存名称,未指定时使用默认缓存
+func WithCacheName(name string) LoadOption {
+	return func(o *loadOption) {
+		o.name = name
+	}
+}
+
+//WithLoadLock 加载前通过缓存获取锁(秒),使多个节点同一时间只有一个节点加载数据,其它节点等待加载结果
+func WithLoadLock(expire int) LoadOption {
+	return func(o *loadOption) {
+		o.lock = expire
+	}
+}
+
+//WithStale 数据过期后继续保留的时长(秒),加载失败时返回过期的数据
+func WithStale(stale int) LoadOption {
+	return func(o *loadOption) {
+		o.stale = stale
+	}
+}
+
+//WithEarlyRefresh 过期前按概率提前在后台刷新数据,beta越大越早刷新,通常为1
+func WithEarlyRefresh(beta float64) LoadOption {
+	return func(o *loadOption) {
+		o.beta = beta
+	}
+}
+
+//entry 缓存中保存的数据,记录逻辑过期时间与加载耗时
+type entry struct {
+	Value   string `json:"v"`
+	Expires int64  `json:"e,omitempty"`
+	Delta   int64  `json:"d,omitempty"`
+}
+
+func (e *entry) expired(now time.Time) bool {
+	return e.Expires > 0 && now.UnixNano()/int64(time.Millisecond) >= e.Expires
+}
+
+//shouldRefresh 按XFetch算法判断是否提前刷新
+func (e *entry) shouldRefresh(now time.Time, beta float64) bool {
+	if beta <= 0 || e.Expires == 0 {
+		return false
+	}
+	gap := -float64(e.Delta) * beta * math.Log(rand.Float64())
+	return float64(now.UnixNano()/int64(time.Millisecond))+gap >= float64(e.Expires)
+}
+
+//call 正在进行的加载
+type call struct {
+	wg    sync.WaitGroup
+	value string
+	err   error
+}
+
+//group 合并同一进程中对相同key的并发加载
+type group struct {
+	lock  sync.Mutex
+	calls map[string]*call
+}
+
+var loading = &group{calls: make(map[string]*call)}
+
+func (g *group) do(key string, f func() (string, error)) (string, error) {
+	g.lock.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.lock.Unlock()
+		c.wg.Wait()
+		return c.value, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.lock.Unlock()
+
+	defer func() {
+		g.lock.Lock()
+		delete(g.calls, key)
+		g.lock.Unlock()
+		c.wg.Done()
+	}()
+	c.value, c.err = f()
+	return c.value, c.err
+}
+
+//GetOrLoad 从缓存获取数据,不存在或已过期时调用loader加载并保存,同一进程中相同key的并发加载只执行一次。
+//通过GetOrLoad保存的数据包含过期时间等信息,应使用GetOrLoad读取
+func GetOrLoad(c ICache, key string, expiresAt int, loader Loader, opts ...LoadOption) (string, error) {
+	o := &loadOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	flight := fmt.Sprintf("%p:%s", c, key)
+	now := time.Now()
+	e := getEntry(c, key)
+	if e != nil && !e.expired(now) {
+		if e.shouldRefresh(now, o.beta) {
+			go loading.do(flight, func() (string, error) {
+				return load(c, key, expiresAt, loader, o, nil)
+			})
+		}
+		return e.Value, nil
+	}
+	value, err := loading.do(flight, func() (string, error) {
+		return load(c, key, expiresAt, loader, o, e)
+	})
+	if err != nil && e != nil {
+		global.Def.Log().Warnf("加载缓存%s失败,返回过期数据:%v", key, err)
+		return e.Value, nil
+	}
+	return value, err
+}
+
+func load(c ICache, key string, expiresAt int, loader Loader, o *loadOption, old *entry) (string, error) {
+	if o.lock > 0 {
+		lockKey := key + ":loading"
+		owner := utility.GetGUID()
+		ok, err := TryLock(c, lockKey, owner, o.lock)
+		if err != nil {
+			global.Def.Log().Warnf("获取缓存%s的加载锁失败:%v", key, err)
+		}
+		if ok {
+			defer Unlock(c, lockKey, owner)
+		} else if e := wait(c, key, old, time.Duration(o.lock)*time.Second); e != nil {
+			return e.Value, nil
+		}
+	}
+	start := time.Now()
+	value, err := loader()
+	if err != nil {
+		return "", err
+	}
+	e := &entry{Value: value, Delta: int64(time.Since(start) / time.Millisecond)}
+	ttl := expiresAt
+	if expiresAt > 0 {
+		e.Expires = time.Now().Add(time.Duration(expiresAt)*time.Second).UnixNano() / int64(time.Millisecond)
+		ttl = expiresAt + o.stale
+	}
+	buff, _ := json.Marshal(e)
+	if err := c.Set(key, string(buff), ttl); err != nil {
+		return "", fmt.Errorf("保存缓存%s失败:%w", key, err)
+	}
+	return value, nil
+}
+
+//wait 等待其它节点加载完成,超时返回nil
+func wait(c ICache, key string, old *entry, timeout time.Duration) *entry {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 50)
+		if e := getEntry(c, key); e != nil && !e.expired(time.Now()) && (old == nil || e.Expires != old.Expires) {
+			return e
+		}
+	}
+	return nil
+}
+
+func getEntry(c ICache, key string) *entry {
+	raw, err := c.Get(key)
+	if err != nil || raw == "" {
+		return nil
+	}
+	e := &entry{}
+	if err := json.Unmarshal([]byte(raw), e); err != nil {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,126 @@
+package caches
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/caches/cache/gocache"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestGetOrLoad_Coalesce(t *testing.T) {
+	c, _ := gocache.NewByOpts()
+	var n int32
+	loader := func() (string, error) {
+		atomic.AddInt32(&n, 1)
+		time.Sleep(time.Millisecond * 50)
+		return "v", nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := GetOrLoad(c, "coalesce", 60, loader)
+			assert.Equal(t, nil, err, "并发加载")
+			assert.Equal(t, "v", v, "并发加载")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&n), "并发加载只执行一次")
+
+	v, err := GetOrLoad(c, "coalesce", 60, loader)
+	assert.Equal(t, nil, err, "从缓存获取")
+	assert.Equal(t, "v", v, "从缓存获取")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&n), "缓存有效时不加载")
+}
+
+func TestGetOrLoad_Stale(t *testing.T) {
+	tests := []struct {
+		name    string
+		stale   int
+		loadErr error
+		want    string
+		wantErr bool
+	}{
+		{name: "1. 加载成功返回新数据", stale: 60, want: "new"},
+		{name: "2. 加载失败返回过期数据", stale: 60, loadErr: errors.New("err"), want: "old"},
+		{name: "3. 未保留过期数据时返回错误", stale: 0, loadErr: errors.New("err"), want: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, _ := gocache.NewByOpts()
+		_, err := GetOrLoad(c, "stale", 60, func() (string, error) { return "old", nil }, WithStale(tt.stale))
+		assert.Equal(t, nil, err, tt.name)
+		expire(t, c, "stale", tt.stale)
+
+		v, err := GetOrLoad(c, "stale", 60, func() (string, error) { return "new", tt.loadErr }, WithStale(tt.stale))
+		assert.Equal(t, tt.wantErr, err != nil, tt.name)
+		assert.Equal(t, tt.want, v, tt.name)
+	}
+}
+
+func TestGetOrLoad_EarlyRefresh(t *testing.T) {
+	c, _ := gocache.NewByOpts()
+	GetOrLoad(c, "early", 60, func() (string, error) { return "old", nil })
+	e := getEntry(c, "early")
+	e.Delta = int64(time.Hour * 1000 / time.Millisecond)
+	setEntry(t, c, "early", e, 60)
+
+	v, err := GetOrLoad(c, "early", 60, func() (string, error) { return "new", nil }, WithEarlyRefresh(1))
+	assert.Equal(t, nil, err, "提前刷新")
+	assert.Equal(t, "old", v, "提前刷新时返回当前数据")
+	for i := 0; i < 100 && getEntry(c, "early").Value != "new"; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.Equal(t, "new", getEntry(c, "early").Value, "后台刷新完成")
+}
+
+func TestGetOrLoad_Lock(t *testing.T) {
+	c, _ := gocache.NewByOpts()
+	c.Add("locked:loading", "1", 3)
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		setEntry(t, c, "locked", &entry{Value: "other", Expires: time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond)}, 60)
+	}()
+	var n int32
+	v, err := GetOrLoad(c, "locked", 60, func() (string, error) {
+		atomic.AddInt32(&n, 1)
+		return "self", nil
+	}, WithLoadLock(3))
+	assert.Equal(t, nil, err, "等待其它节点加载")
+	assert.Equal(t, "other", v, "返回其它节点加载的数据")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&n), "未执行加载")
+}
+
+func TestGetOrLoad_LockOwner(t *testing.T) {
+	c, _ := gocache.NewByOpts()
+	v, err := GetOrLoad(c, "owner", 60, func() (string, error) {
+		//加载超时,锁已过期并被其它节点获取
+		c.Set("owner:loading", "other", 3)
+		return "self", nil
+	}, WithLoadLock(3))
+	assert.Equal(t, nil, err, "加载数据")
+	assert.Equal(t, "self", v, "加载数据")
+	lock, _ := c.Get("owner:loading")
+	assert.Equal(t, "other", lock, "不释放其它节点获取的锁")
+}
+
+//expire 将数据修改为已过期
+func expire(t *testing.T, c ICache, key string, stale int) {
+	e := getEntry(c, key)
+	e.Expires = time.Now().Add(-time.Second).UnixNano() / int64(time.Millisecond)
+	if stale == 0 {
+		c.Delete(key)
+		return
+	}
+	setEntry(t, c, key, e, stale)
+}
+
+func setEntry(t *testing.T, c ICache, key string, e *entry, ttl int) {
+	buff, _ := json.Marshal(e)
+	assert.Equal(t, nil, c.Set(key, string(buff), ttl), "保存缓存")
+}
@@ -0,0 +1,29 @@
+package caches
+
+//TryLock 通过缓存获取锁,锁不存在时写入持有者并设置过期时间(秒)。
+//扩展缓存通过CompareAndSwap原子写入,保证多个节点同一时间只有一个节点获取到锁
+func TryLock(c ICache, key string, owner string, expire int) (bool, error) {
+	if ext, ok := c.(IExtendCache); ok {
+		return ext.CompareAndSwap(key, "", owner, expire)
+	}
+	if err := c.Add(key, owner, expire); err != nil {
+		if c.Exists(key) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+//Unlock 释放锁,锁已过期并被其它节点获取时不删除
+func Unlock(c ICache, key string, owner string) error {
+	if ext, ok := c.(IExtendCache); ok {
+		_, err := ext.CompareAndDelete(key, owner)
+		return err
+	}
+	v, err := c.Get(key)
+	if err != nil || v != owner {
+		return err
+	}
+	return c.Delete(key)
+}
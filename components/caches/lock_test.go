@@ -0,0 +1,53 @@
+package caches
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/micro-plat/hydra/components/caches/cache/gocache"
+	"github.com/micro-plat/hydra/components/caches/cache/redis"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestTryLock(t *testing.T) {
+	m, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer m.Close()
+	r, err := redis.NewByOpts(varredis.WithAddrs(m.Addr()))
+	assert.Equal(t, nil, err, "创建redis缓存")
+	defer r.Close()
+	g, _ := gocache.NewByOpts()
+
+	for _, c := range []ICache{r, g} {
+		name := c.(interface{ GetProto() string }).GetProto()
+
+		//多个节点同时获取锁,只有一个节点获取成功
+		var n int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ok, err := TryLock(c, "lock", fmt.Sprint(i), 60)
+				assert.Equal(t, nil, err, name, "1. 获取锁")
+				if ok {
+					atomic.AddInt32(&n, 1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), n, name, "1. 只有一个节点获取到锁")
+
+		//锁已过期并被其它节点获取时不删除
+		assert.Equal(t, nil, c.Set("lock", "other", 60), name, "2. 其它节点获取锁")
+		assert.Equal(t, nil, Unlock(c, "lock", "self"), name, "2. 释放锁")
+		v, _ := c.Get("lock")
+		assert.Equal(t, "other", v, name, "2. 不删除其它节点的锁")
+		assert.Equal(t, nil, Unlock(c, "lock", "other"), name, "3. 持有者释放锁")
+		assert.Equal(t, false, c.Exists("lock"), name, "3. 持有者释放锁")
+	}
+}
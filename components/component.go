@@ -9,14 +9,21 @@ import (
 	"github.com/micro-plat/hydra/components/container"
 	"github.com/micro-plat/hydra/components/dbs"
 	"github.com/micro-plat/hydra/components/dlock"
+	"github.com/micro-plat/hydra/components/election"
+	"github.com/micro-plat/hydra/components/flags"
 	"github.com/micro-plat/hydra/components/http"
+	"github.com/micro-plat/hydra/components/pkgs/redis"
 	"github.com/micro-plat/hydra/components/queues"
 	"github.com/micro-plat/hydra/components/rpcs"
 	"github.com/micro-plat/hydra/components/uuid"
+	"github.com/micro-plat/hydra/conf"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
 	"github.com/micro-plat/hydra/context"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
 
+	_ "github.com/micro-plat/hydra/components/queues/mq/amqp"
 	_ "github.com/micro-plat/hydra/components/queues/mq/lmq"
 	_ "github.com/micro-plat/hydra/components/queues/mq/mqtt"
 	_ "github.com/micro-plat/hydra/components/queues/mq/redis"
@@ -31,7 +38,10 @@ type IComponent interface {
 	Cache() caches.IComponentCache
 	HTTP() http.IComponentHTTPClient
 	DB() dbs.IComponentDB
-	DLock(name string) (dlock.ILock, error)
+	DLock(name string, opts ...dlock.Option) (dlock.ILock, error)
+	RedisLock(name string, redisName string, opts ...dlock.Option) (dlock.ILock, error)
+	Election(name string, opts ...election.Option) (election.IElection, error)
+	Flags() (flags.IFlags, error)
 	UUID() uuid.UUID
 }
 
@@ -46,12 +56,14 @@ type Component struct {
 	cache      caches.IComponentCache
 	db         dbs.IComponentDB
 	httpClient http.IComponentHTTPClient
+	elections  cmap.ConcurrentMap
 }
 
 //NewComponent 创建组件
 func NewComponent() *Component {
 	c := &Component{
-		c: container.NewContainer(),
+		c:         container.NewContainer(),
+		elections: cmap.New(4),
 	}
 	c.rpc = rpcs.NewStandardRPC(c.c)
 	c.queue = queues.NewStandardQueue(c.c)
@@ -91,9 +103,43 @@ func (c *Component) HTTP() http.IComponentHTTPClient {
 	return c.httpClient
 }
 
-//DLock 获取分布式鍞
-func (c *Component) DLock(name string) (dlock.ILock, error) {
-	return dlock.NewLock(registry.Join(global.Def.PlatName, "dlock", name), global.Def.RegistryAddr, context.Current().Log())
+//DLock 获取基于注册中心的分布式鍞
+func (c *Component) DLock(name string, opts ...dlock.Option) (dlock.ILock, error) {
+	return dlock.NewLock(name, global.Def.RegistryAddr, context.Current().Log(), opts...)
+}
+
+//RedisLock 获取基于redis的分布式鍞,redisName为/var/redis下的节点名
+func (c *Component) RedisLock(name string, redisName string, opts ...dlock.Option) (dlock.ILock, error) {
+	obj, err := c.c.GetOrCreate(varredis.TypeNodeName, redisName, func(conf *conf.RawConf, keys ...string) (interface{}, error) {
+		if conf.IsEmpty() {
+			return nil, fmt.Errorf("节点/%s/%s未配置，或不可用", varredis.TypeNodeName, redisName)
+		}
+		return redis.NewByConfig(varredis.NewByRaw(string(conf.GetRaw())))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dlock.NewRedisLock(name, obj.(*redis.Client), opts...), nil
+}
+
+//Election 获取基于注册中心的主节点选举,相同名称返回同一选举对象
+func (c *Component) Election(name string, opts ...election.Option) (election.IElection, error) {
+	_, obj, err := c.elections.SetIfAbsentCb(name, func(i ...interface{}) (interface{}, error) {
+		r, err := registry.GetRegistry(global.Def.RegistryAddr, global.Def.Log())
+		if err != nil {
+			return nil, err
+		}
+		return election.New(name, r, opts...), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(election.IElection), nil
+}
+
+//Flags 获取当前平台的功能开关
+func (c *Component) Flags() (flags.IFlags, error) {
+	return flags.Get()
 }
 
 //UUID 获取全局唯一编号
@@ -49,7 +49,16 @@ func (s *StandardDB) GetDB(names ...string) (d IDB, err error) {
 		if err = conf.ToStruct(&dbConf); err != nil {
 			return nil, fmt.Errorf("数据库[%s/%s]配置有误：%w", dbTypeNode, name, err)
 		}
-		return db.NewDB(dbConf.Provider, dbConf.ConnString, dbConf.MaxOpen, dbConf.MaxIdle, dbConf.LifeTime)
+		var d IDB
+		if len(dbConf.Replicas) > 0 {
+			d, err = NewReplicaDB(&dbConf)
+		} else {
+			d, err = db.NewDB(dbConf.Provider, dbConf.ConnString, dbConf.MaxOpen, dbConf.MaxIdle, dbConf.LifeTime)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return NewTraceDB(name, &dbConf, d), nil
 	})
 	if err != nil {
 		return nil, err
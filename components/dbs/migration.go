@@ -0,0 +1,345 @@
+package dbs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/components/dlock"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/security/md5"
+)
+
+//MigrationTable 记录已执行迁移脚本的数据表
+const MigrationTable = "schema_migrations"
+
+//使用varchar类型以兼容mysql,oracle,postgres,sqlite等数据库
+var createMigrationTable = fmt.Sprintf(`create table %s(
+version varchar(32) not null primary key,
+name varchar(128) not null,
+checksum varchar(32) not null,
+applied_at varchar(20) not null)`, MigrationTable)
+
+//migrateLockTimeout 等待其它节点执行迁移的最长时间
+const migrateLockTimeout = time.Minute * 10
+
+//MigrationStatus 迁移脚本执行状态
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt string
+
+	//Modified 脚本已执行,但注册的脚本内容与执行时不一致
+	Modified bool
+
+	//Missing 脚本已执行,但当前程序未注册
+	Missing bool
+}
+
+type applied struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt string
+}
+
+//Migrator 版本化数据库迁移,按版本号顺序执行未执行的脚本,并记录到schema_migrations表
+type Migrator struct {
+	db         IDB
+	migrations []*global.Migration
+	dryRun     bool
+	lock       dlock.ILock
+}
+
+//MigrateOption 迁移配置选项
+type MigrateOption func(*Migrator)
+
+//WithDryRun 只返回待执行的脚本,不修改数据库
+func WithDryRun() MigrateOption {
+	return func(m *Migrator) {
+		m.dryRun = true
+	}
+}
+
+//WithLock 执行或回滚前获取分布式锁,多个节点同时部署时依次执行,后获取锁的节点不再重复执行脚本
+func WithLock(lock dlock.ILock) MigrateOption {
+	return func(m *Migrator) {
+		m.lock = lock
+	}
+}
+
+//NewMigrator 构建数据库迁移对象
+func NewMigrator(db IDB, migrations []*global.Migration, opts ...MigrateOption) *Migrator {
+	if t, ok := db.(*TraceDB); ok {
+		db = t.db
+	}
+	if r, ok := db.(*ReplicaDB); ok {
+		db = r.Primary()
+	}
+	m := &Migrator{db: db, migrations: migrations}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+//Checksum 获取迁移脚本的校验码
+func Checksum(m *global.Migration) string {
+	return md5.Encrypt(strings.Join(m.Up, ";"))
+}
+
+//Status 获取所有迁移脚本的执行状态
+func (m *Migrator) Status() ([]*MigrationStatus, error) {
+	records, err := m.getApplied()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		s := &MigrationStatus{Version: mg.Version, Name: mg.Name}
+		if r, ok := records[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.appliedAt
+			s.Modified = r.checksum != Checksum(mg)
+			delete(records, mg.Version)
+		}
+		list = append(list, s)
+	}
+	for _, r := range records {
+		list = append(list, &MigrationStatus{Version: r.version, Name: r.name, Applied: true, AppliedAt: r.appliedAt, Missing: true})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+//Migrate 按版本号顺序执行所有未执行的脚本,返回已执行(dry-run时为待执行)的脚本。
+//已执行脚本的内容被修改时不执行任何脚本。
+//每个脚本在一个事务中执行,mysql等数据库的DDL语句会隐式提交,失败时需人工处理
+func (m *Migrator) Migrate() ([]*global.Migration, error) {
+	if err := m.acquire(); err != nil {
+		return nil, err
+	}
+	defer m.release()
+	records, err := m.getApplied()
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]*global.Migration, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		r, ok := records[mg.Version]
+		if !ok {
+			pending = append(pending, mg)
+			continue
+		}
+		if r.checksum != Checksum(mg) {
+			return nil, fmt.Errorf("迁移脚本[%d]%s已执行,但内容已被修改", mg.Version, mg.Name)
+		}
+	}
+	if m.dryRun {
+		return pending, nil
+	}
+	if len(pending) > 0 {
+		if err := m.createTable(); err != nil {
+			return nil, err
+		}
+	}
+	done := make([]*global.Migration, 0, len(pending))
+	for _, mg := range pending {
+		if err := m.up(mg); err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+//Rollback 按版本号倒序回滚最近执行的steps个脚本,返回已回滚(dry-run时为待回滚)的脚本
+func (m *Migrator) Rollback(steps int) ([]*global.Migration, error) {
+	if err := m.acquire(); err != nil {
+		return nil, err
+	}
+	defer m.release()
+	records, err := m.getApplied()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int64, 0, len(records))
+	for v := range records {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	targets := make([]*global.Migration, 0, len(versions))
+	for _, v := range versions {
+		mg := m.get(v)
+		if mg == nil {
+			return nil, fmt.Errorf("迁移脚本[%d]%s未注册,无法回滚", v, records[v].name)
+		}
+		if len(mg.Down) == 0 {
+			return nil, fmt.Errorf("迁移脚本[%d]%s未提供回滚脚本", v, mg.Name)
+		}
+		targets = append(targets, mg)
+	}
+	if m.dryRun {
+		return targets, nil
+	}
+	done := make([]*global.Migration, 0, len(targets))
+	for _, mg := range targets {
+		if err := m.down(mg); err != nil {
+			return done, err
+		}
+		done = append(done, mg)
+	}
+	return done, nil
+}
+
+//acquire 获取迁移锁,dry-run或未设置锁时不获取
+func (m *Migrator) acquire() error {
+	if m.dryRun || m.lock == nil {
+		return nil
+	}
+	if err := m.lock.Lock(migrateLockTimeout); err != nil {
+		return fmt.Errorf("获取数据库迁移锁失败:%w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) release() {
+	if m.dryRun || m.lock == nil {
+		return
+	}
+	m.lock.Unlock()
+}
+
+func (m *Migrator) up(mg *global.Migration) (err error) {
+	trans, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			trans.Rollback()
+			err = fmt.Errorf("执行迁移脚本[%d]%s失败:%w", mg.Version, mg.Name, err)
+		}
+	}()
+	for _, sql := range mg.Up {
+		if _, err = trans.Execute(sql, nil); err != nil {
+			return err
+		}
+	}
+	_, err = trans.Execute(fmt.Sprintf("insert into %s(version,name,checksum,applied_at) values(@version,@name,@checksum,@applied_at)", MigrationTable),
+		map[string]interface{}{
+			"version":    fmt.Sprint(mg.Version),
+			"name":       mg.Name,
+			"checksum":   Checksum(mg),
+			"applied_at": time.Now().Format("2006-01-02 15:04:05"),
+		})
+	if err != nil {
+		return err
+	}
+	return trans.Commit()
+}
+
+func (m *Migrator) down(mg *global.Migration) (err error) {
+	trans, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			trans.Rollback()
+			err = fmt.Errorf("回滚迁移脚本[%d]%s失败:%w", mg.Version, mg.Name, err)
+		}
+	}()
+	for _, sql := range mg.Down {
+		if _, err = trans.Execute(sql, nil); err != nil {
+			return err
+		}
+	}
+	_, err = trans.Execute(fmt.Sprintf("delete from %s where version=@version", MigrationTable),
+		map[string]interface{}{"version": fmt.Sprint(mg.Version)})
+	if err != nil {
+		return err
+	}
+	return trans.Commit()
+}
+
+func (m *Migrator) get(version int64) *global.Migration {
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return mg
+		}
+	}
+	return nil
+}
+
+//getApplied 获取已执行的脚本,记录表不存在时视为未执行任何脚本
+func (m *Migrator) getApplied() (map[int64]*applied, error) {
+	records := make(map[int64]*applied)
+	ok, err := m.hasTable()
+	if err != nil || !ok {
+		return records, err
+	}
+	rows, err := m.db.Query(fmt.Sprintf("select version,name,checksum,applied_at from %s", MigrationTable), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		version := row.GetInt64("version", -1)
+		if version < 0 {
+			return nil, fmt.Errorf("%s中的版本号%s不正确", MigrationTable, row.GetString("version"))
+		}
+		records[version] = &applied{
+			version:   version,
+			name:      row.GetString("name"),
+			checksum:  row.GetString("checksum"),
+			appliedAt: row.GetString("applied_at"),
+		}
+	}
+	return records, nil
+}
+
+//hasTable 记录表是否存在,只有表不存在的错误视为未创建,连接、权限等错误直接返回
+func (m *Migrator) hasTable() (bool, error) {
+	_, err := m.db.Scalar(fmt.Sprintf("select count(1) from %s", MigrationTable), nil)
+	if err == nil {
+		return true, nil
+	}
+	if isNoTable(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("查询%s表失败:%w", MigrationTable, err)
+}
+
+//noTableErrors 各数据库表不存在时的错误信息:sqlite,mysql,postgres,oracle
+var noTableErrors = []string{"no such table", "doesn't exist", "does not exist", "ora-00942"}
+
+func isNoTable(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, e := range noTableErrors {
+		if strings.Contains(msg, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) createTable() error {
+	ok, err := m.hasTable()
+	if err != nil || ok {
+		return err
+	}
+	if _, err := m.db.Execute(createMigrationTable, nil); err != nil {
+		return fmt.Errorf("创建%s表失败:%w", MigrationTable, err)
+	}
+	return nil
+}
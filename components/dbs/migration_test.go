@@ -0,0 +1,111 @@
+package dbs
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/micro-plat/hydra/components/dlock"
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/db"
+)
+
+func newMigrations(up2 string) []*global.Migration {
+	return []*global.Migration{
+		{Version: 1, Name: "create_user", Up: []string{"create table user(id integer)"}, Down: []string{"drop table user"}},
+		{Version: 2, Name: "create_order", Up: []string{up2}, Down: []string{"drop table orders"}},
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	d, err := db.NewDB(sqlite.Provider, "file:migration?mode=memory&cache=shared", 1, 1, 600)
+	assert.Equal(t, nil, err, "创建数据库")
+
+	//dry-run不修改数据库
+	list, err := NewMigrator(d, newMigrations("create table orders(id integer)"), WithDryRun()).Migrate()
+	assert.Equal(t, nil, err, "1. dry-run执行迁移")
+	assert.Equal(t, 2, len(list), "1. dry-run执行迁移")
+	_, err = d.Scalar("select count(1) from "+MigrationTable, nil)
+	assert.NotEqual(t, nil, err, "1. dry-run不创建记录表")
+
+	//执行迁移
+	m := NewMigrator(d, newMigrations("create table orders(id integer)"))
+	list, err = m.Migrate()
+	assert.Equal(t, nil, err, "2. 执行迁移")
+	assert.Equal(t, 2, len(list), "2. 执行迁移")
+	_, err = d.Scalar("select count(1) from orders", nil)
+	assert.Equal(t, nil, err, "2. 执行迁移")
+
+	list, err = m.Migrate()
+	assert.Equal(t, nil, err, "3. 重复执行迁移")
+	assert.Equal(t, 0, len(list), "3. 重复执行迁移")
+
+	//已执行脚本被修改
+	_, err = NewMigrator(d, newMigrations("create table orders(id integer,name text)")).Migrate()
+	assert.NotEqual(t, nil, err, "4. 已执行脚本被修改")
+	status, err := NewMigrator(d, newMigrations("create table orders(id integer,name text)")).Status()
+	assert.Equal(t, nil, err, "4. 已执行脚本被修改")
+	assert.Equal(t, false, status[0].Modified, "4. 已执行脚本被修改")
+	assert.Equal(t, true, status[1].Modified, "4. 已执行脚本被修改")
+
+	//回滚
+	list, err = m.Rollback(1)
+	assert.Equal(t, nil, err, "5. 回滚最近的脚本")
+	assert.Equal(t, int64(2), list[0].Version, "5. 回滚最近的脚本")
+	_, err = d.Scalar("select count(1) from orders", nil)
+	assert.NotEqual(t, nil, err, "5. 回滚最近的脚本")
+	status, _ = m.Status()
+	assert.Equal(t, true, status[0].Applied, "5. 回滚最近的脚本")
+	assert.Equal(t, false, status[1].Applied, "5. 回滚最近的脚本")
+
+	//未注册的脚本无法回滚
+	_, err = NewMigrator(d, nil).Rollback(1)
+	assert.NotEqual(t, nil, err, "6. 未注册的脚本无法回滚")
+	status, _ = NewMigrator(d, nil).Status()
+	assert.Equal(t, true, status[0].Missing, "6. 未注册的脚本")
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	d, err := db.NewDB(sqlite.Provider, "file:migration_lock?mode=memory&cache=shared", 2, 2, 600)
+	assert.Equal(t, nil, err, "创建数据库")
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+
+	//多个节点同时执行迁移,每个脚本只执行一次
+	slow := "create table orders as with recursive c(x) as (select 1 union all select x+1 from c where x<100000) select x as id from c"
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	start := make(chan struct{})
+	applied := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := NewMigrator(d, newMigrations(slow), WithLock(dlock.NewLockByRegistry("migrate", r)))
+			<-start
+			list, err := m.Migrate()
+			assert.Equal(t, nil, err, "1. 多个节点同时执行迁移")
+			lock.Lock()
+			applied += len(list)
+			lock.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, 2, applied, "1. 每个脚本只执行一次")
+}
+
+func TestMigrator_QueryError(t *testing.T) {
+	d, err := db.NewDB(sqlite.Provider, "file:migration_err?mode=memory&cache=shared", 1, 1, 600)
+	assert.Equal(t, nil, err, "创建数据库")
+	d.Close()
+
+	//连接错误不视为记录表不存在
+	_, err = NewMigrator(d, newMigrations("create table orders(id integer)")).Status()
+	assert.NotEqual(t, nil, err, "1. 查询记录表失败时返回错误")
+	_, err = NewMigrator(d, newMigrations("create table orders(id integer)")).Migrate()
+	assert.NotEqual(t, nil, err, "2. 查询记录表失败时不执行迁移")
+}
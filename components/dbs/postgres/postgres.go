@@ -0,0 +1,8 @@
+//Package postgres 引入postgresql数据库驱动,使用时需在项目中引用:
+//import _ "github.com/micro-plat/hydra/components/dbs/postgres"
+//SQL语句中的@name、#name按顺序转换为$1,$2...参数
+package postgres
+
+import (
+	_ "github.com/lib/pq"
+)
@@ -0,0 +1,202 @@
+package dbs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//forcePrimaryKey 请求元数据中强制使用主库的标识
+const forcePrimaryKey = "__db_force_primary_"
+
+//defCheckInterval 只读库健康检查间隔
+const defCheckInterval = time.Second * 5
+
+//ForcePrimary 当前请求后续的查询均使用主库,用于写入后需立即读取最新数据的场景
+func ForcePrimary() {
+	if ctx, ok := context.GetContext(); ok {
+		ctx.Meta().SetValue(forcePrimaryKey, true)
+	}
+}
+
+func isForcePrimary() bool {
+	if ctx, ok := context.GetContext(); ok {
+		return ctx.Meta().GetBool(forcePrimaryKey)
+	}
+	return false
+}
+
+//ReplicaDB 读写分离数据库,查询语句在健康的只读库间轮询执行,写入、存储过程及事务使用主库
+type ReplicaDB struct {
+	primary  IDB
+	replicas []*replica
+	index    uint32
+	maxLag   int
+	lagSQL   string
+	pingSQL  string
+	closeCh  chan struct{}
+	once     sync.Once
+}
+
+type replica struct {
+	db      IDB
+	healthy int32
+}
+
+var _ IDB = &ReplicaDB{}
+
+//NewReplicaDB 根据配置创建读写分离数据库
+func NewReplicaDB(cnf *xdb.DB) (*ReplicaDB, error) {
+	primary, err := db.NewDB(cnf.Provider, cnf.ConnString, cnf.MaxOpen, cnf.MaxIdle, cnf.LifeTime)
+	if err != nil {
+		return nil, err
+	}
+	r := newReplicaDB(primary, cnf.MaxLag, cnf.LagSQL, getPingSQL(cnf.Provider))
+	for _, connString := range cnf.Replicas {
+		//只读库连接失败时由健康检查标记为不可用,恢复后自动启用
+		rdb, err := db.NewDB(cnf.Provider, connString, cnf.MaxOpen, cnf.MaxIdle, cnf.LifeTime)
+		if rdb == nil {
+			r.Close()
+			return nil, fmt.Errorf("创建只读库失败:%w", err)
+		}
+		r.add(rdb)
+	}
+	r.check()
+	go r.loopCheck()
+	return r, nil
+}
+
+func newReplicaDB(primary IDB, maxLag int, lagSQL string, pingSQL string) *ReplicaDB {
+	return &ReplicaDB{
+		primary: primary,
+		maxLag:  maxLag,
+		lagSQL:  lagSQL,
+		pingSQL: pingSQL,
+		closeCh: make(chan struct{}),
+	}
+}
+
+func (r *ReplicaDB) add(d IDB) {
+	r.replicas = append(r.replicas, &replica{db: d, healthy: 1})
+}
+
+//Primary 获取主库
+func (r *ReplicaDB) Primary() IDB {
+	return r.primary
+}
+
+//Query 在只读库中查询数据
+func (r *ReplicaDB) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return r.reader().Query(sql, input)
+}
+
+//Scalar 在只读库中查询首行首列数据
+func (r *ReplicaDB) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	return r.reader().Scalar(sql, input)
+}
+
+//Execute 在主库中执行语句
+func (r *ReplicaDB) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	return r.primary.Execute(sql, input)
+}
+
+//Executes 在主库中执行语句
+func (r *ReplicaDB) Executes(sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	return r.primary.Executes(sql, input)
+}
+
+//ExecuteBatch 在主库中批量执行语句
+func (r *ReplicaDB) ExecuteBatch(sql []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return r.primary.ExecuteBatch(sql, input)
+}
+
+//ExecuteSP 在主库中执行存储过程
+func (r *ReplicaDB) ExecuteSP(procName string, input map[string]interface{}, output ...interface{}) (row int64, err error) {
+	return r.primary.ExecuteSP(procName, input, output...)
+}
+
+//Begin 在主库中创建事务
+func (r *ReplicaDB) Begin() (db.IDBTrans, error) {
+	return r.primary.Begin()
+}
+
+//Close 关闭主库与只读库
+func (r *ReplicaDB) Close() {
+	r.once.Do(func() {
+		close(r.closeCh)
+		r.primary.Close()
+		for _, rp := range r.replicas {
+			rp.db.Close()
+		}
+	})
+}
+
+//reader 轮询获取健康的只读库,无可用只读库或当前请求强制使用主库时返回主库
+func (r *ReplicaDB) reader() IDB {
+	if len(r.replicas) == 0 || isForcePrimary() {
+		return r.primary
+	}
+	start := atomic.AddUint32(&r.index, 1)
+	for i := 0; i < len(r.replicas); i++ {
+		rp := r.replicas[(int(start)+i)%len(r.replicas)]
+		if atomic.LoadInt32(&rp.healthy) == 1 {
+			return rp.db
+		}
+	}
+	return r.primary
+}
+
+func (r *ReplicaDB) loopCheck() {
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-time.After(defCheckInterval):
+			r.check()
+		}
+	}
+}
+
+//check 检查只读库是否可用,配置了延迟查询语句时延迟超过最大延迟的只读库不可用
+func (r *ReplicaDB) check() {
+	for i, rp := range r.replicas {
+		healthy := int32(1)
+		if err := r.checkReplica(rp); err != nil {
+			healthy = 0
+		}
+		if old := atomic.SwapInt32(&rp.healthy, healthy); old != healthy {
+			global.Def.Log().Infof("只读库[%d]状态变更:%v", i, healthy == 1)
+		}
+	}
+}
+
+func (r *ReplicaDB) checkReplica(rp *replica) error {
+	if r.lagSQL == "" {
+		_, err := rp.db.Scalar(r.pingSQL, nil)
+		return err
+	}
+	lag, err := rp.db.Scalar(r.lagSQL, nil)
+	if err != nil {
+		return err
+	}
+	if r.maxLag > 0 && types.GetInt(lag) > r.maxLag {
+		return fmt.Errorf("只读库延迟%v秒超过%d秒", lag, r.maxLag)
+	}
+	return nil
+}
+
+func getPingSQL(provider string) string {
+	switch strings.ToLower(provider) {
+	case "ora", "oracle":
+		return "select 1 from dual"
+	}
+	return "select 1"
+}
@@ -0,0 +1,63 @@
+package dbs
+
+import (
+	"fmt"
+	"testing"
+
+	_ "github.com/micro-plat/hydra/components/dbs/sqlite"
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/db"
+)
+
+func newMemoryDB(t *testing.T, name string, lag int) string {
+	connString := fmt.Sprintf("file:%s?mode=memory&cache=shared", name)
+	d, err := db.NewDB(sqlite.Provider, connString, 1, 1, 600)
+	assert.Equal(t, nil, err, name)
+	d.Execute("create table node(name varchar(32))", nil)
+	d.Execute("insert into node(name) values(@name)", map[string]interface{}{"name": name})
+	d.Execute("create table lag(v integer)", nil)
+	d.Execute("insert into lag(v) values(@v)", map[string]interface{}{"v": lag})
+	return connString
+}
+
+func TestReplicaDB(t *testing.T) {
+	tests := []struct {
+		name   string
+		lags   []int
+		maxLag int
+		want   map[string]int
+	}{
+		{name: "1. 查询在只读库间轮询", lags: []int{0, 0}, want: map[string]int{"r1_1": 2, "r1_2": 2}},
+		{name: "2. 延迟超过阈值的只读库不参与查询", lags: []int{0, 100}, maxLag: 10, want: map[string]int{"r2_1": 4}},
+		{name: "3. 只读库均不可用时使用主库", lags: []int{100, 100}, maxLag: 10, want: map[string]int{"p3": 4}},
+	}
+	for i, tt := range tests {
+		cnf := xdb.New(sqlite.Provider, newMemoryDB(t, fmt.Sprintf("p%d", i+1), 0))
+		replicas := []string{}
+		for j, lag := range tt.lags {
+			replicas = append(replicas, newMemoryDB(t, fmt.Sprintf("r%d_%d", i+1, j+1), lag))
+		}
+		xdb.WithReplicas(replicas...)(cnf)
+		if tt.maxLag > 0 {
+			xdb.WithMaxLag(tt.maxLag, "select v from lag")(cnf)
+		}
+		r, err := NewReplicaDB(cnf)
+		assert.Equal(t, nil, err, tt.name)
+
+		got := map[string]int{}
+		for k := 0; k < 4; k++ {
+			name, err := r.Scalar("select name from node", nil)
+			assert.Equal(t, nil, err, tt.name)
+			got[fmt.Sprint(name)]++
+		}
+		assert.Equal(t, tt.want, got, tt.name)
+
+		_, err = r.Execute("insert into node(name) values('new')", nil)
+		assert.Equal(t, nil, err, tt.name)
+		count, _ := r.Primary().Scalar("select count(1) from node", nil)
+		assert.Equal(t, "2", fmt.Sprint(count), tt.name, "写入主库")
+		r.Close()
+	}
+}
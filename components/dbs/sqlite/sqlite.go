@@ -0,0 +1,57 @@
+//Package sqlite 引入sqlite数据库驱动(需开启cgo),使用时需在项目中引用:
+//import _ "github.com/micro-plat/hydra/components/dbs/sqlite"
+//SQL语句中的@name、#name转换为?参数
+package sqlite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
+	"github.com/micro-plat/lib4go/db/tpl"
+)
+
+//tplContext sqlite模板,使用||连接like参数
+type tplContext struct {
+	prefix string
+}
+
+//GetSQLContext 获取查询串
+func (o tplContext) GetSQLContext(t string, input map[string]interface{}) (query string, args []interface{}) {
+	f := func() string {
+		return o.prefix
+	}
+	query, args, _ = tpl.AnalyzeTPL(t, input, f, o.like)
+	return query, args
+}
+
+//GetSPContext sqlite不支持存储过程,按SQL语句处理
+func (o tplContext) GetSPContext(t string, input map[string]interface{}) (query string, args []interface{}) {
+	return o.GetSQLContext(t, input)
+}
+
+func (o tplContext) like(key string, prefix func() string) string {
+	return fmt.Sprintf("'%%'||%s||'%%'", prefix())
+}
+
+//Replace 替换SQL中的占位符
+func (o tplContext) Replace(sql string, args []interface{}) (r string) {
+	if strings.EqualFold(sql, "") || args == nil {
+		return sql
+	}
+	word := regexp.MustCompile(fmt.Sprintf(`\%s([,|\ ;)]|$)`, o.prefix))
+	index := -1
+	return word.ReplaceAllStringFunc(sql, func(s string) string {
+		index++
+		if index >= len(args) {
+			return "NULL" + s[1:]
+		}
+		return fmt.Sprintf("'%v'%s", args[index], s[1:])
+	})
+}
+
+func init() {
+	tpl.Register(sqlite.Provider, tplContext{prefix: "?"})
+}
@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/types"
+)
+
+func TestTPLContext_GetSQLContext(t *testing.T) {
+	tests := []struct {
+		name      string
+		tpl       string
+		input     map[string]interface{}
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{name: "1. @参数转换为?", tpl: "select * from t where id=@id and name=@name", input: map[string]interface{}{"id": 1, "name": "a"},
+			wantQuery: "select * from t where id=? and name=?", wantArgs: []interface{}{1, "a"}},
+		{name: "2. #参数直接替换", tpl: "select * from #table where id=@id", input: map[string]interface{}{"table": "t", "id": 1},
+			wantQuery: "select * from t where id=?", wantArgs: []interface{}{1}},
+		{name: "3. like条件使用||连接", tpl: "select * from t where 1=1 ?name", input: map[string]interface{}{"name": "a"},
+			wantQuery: "select * from t where 1=1 and name like '%'||?||'%'", wantArgs: []interface{}{"a"}},
+	}
+	ctx := tplContext{prefix: "?"}
+	for _, tt := range tests {
+		query, args := ctx.GetSQLContext(tt.tpl, tt.input)
+		assert.Equal(t, tt.wantQuery, query, tt.name)
+		assert.Equal(t, tt.wantArgs, args, tt.name)
+	}
+}
+
+func TestDB(t *testing.T) {
+	cnf := sqlite.NewBy(":memory:")
+	xdb, err := db.NewDB(cnf.Provider, cnf.ConnString, 1, 1, 600)
+	assert.Equal(t, nil, err, "创建数据库")
+	defer xdb.Close()
+
+	_, err = xdb.Execute("create table user(id integer primary key, name varchar(32))", nil)
+	assert.Equal(t, nil, err, "创建表")
+	for i, name := range []string{"colin", "lily", "lucy"} {
+		row, err := xdb.Execute("insert into user(id,name) values(@id,@name)", map[string]interface{}{"id": i + 1, "name": name})
+		assert.Equal(t, nil, err, "添加数据")
+		assert.Equal(t, int64(1), row, "添加数据")
+	}
+	rows, err := xdb.Query("select id,name from user where 1=1 ?name order by id", map[string]interface{}{"name": "li"})
+	assert.Equal(t, nil, err, "模糊查询")
+	assert.Equal(t, 2, rows.Len(), "模糊查询")
+	assert.Equal(t, "colin", rows.Get(0).GetString("name"), "模糊查询")
+
+	count, err := xdb.Scalar("select count(1) from user where id>@id", map[string]interface{}{"id": 1})
+	assert.Equal(t, nil, err, "查询数量")
+	assert.Equal(t, 2, types.GetInt(count), "查询数量")
+}
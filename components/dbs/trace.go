@@ -0,0 +1,269 @@
+package dbs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/db"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//defSlowThreshold 默认慢查询阈值
+const defSlowThreshold = time.Millisecond * 500
+
+//maskValue 脱敏后的参数值
+const maskValue = "******"
+
+//defMasks 默认需脱敏的参数名
+var defMasks = []string{"password", "pwd", "secret", "token"}
+
+//TraceDB 记录语句执行情况的数据库,语句、参数(已脱敏)、影响行数及耗时记录到当前请求的链路跟踪及日志中,
+//超过阈值的记录为慢查询,并按语句上报执行耗时
+type TraceDB struct {
+	db IDB
+	*dbTracer
+}
+
+//TraceTrans 记录语句执行情况的数据库事务
+type TraceTrans struct {
+	trans db.IDBTrans
+	*dbTracer
+}
+
+type dbTracer struct {
+	name     string
+	provider string
+	host     string
+	slow     time.Duration
+	masks    []string
+}
+
+var _ IDB = &TraceDB{}
+var _ db.IDBTrans = &TraceTrans{}
+
+//NewTraceDB 构建记录语句执行情况的数据库
+func NewTraceDB(name string, cnf *xdb.DB, d IDB) *TraceDB {
+	t := &dbTracer{
+		name:     name,
+		provider: cnf.Provider,
+		host:     global.LocalIP(),
+		slow:     defSlowThreshold,
+		masks:    make([]string, 0, len(defMasks)+len(cnf.Masks)),
+	}
+	if cnf.SlowThreshold > 0 {
+		t.slow = time.Duration(cnf.SlowThreshold) * time.Millisecond
+	}
+	for _, m := range append(defMasks, cnf.Masks...) {
+		t.masks = append(t.masks, strings.ToLower(m))
+	}
+	return &TraceDB{db: d, dbTracer: t}
+}
+
+//Query 查询数据
+func (t *TraceDB) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return query(t.dbTracer, t.db, sql, input)
+}
+
+//Scalar 查询首行首列
+func (t *TraceDB) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	return scalar(t.dbTracer, t.db, sql, input)
+}
+
+//Execute 执行语句
+func (t *TraceDB) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	return execute(t.dbTracer, t.db, sql, input)
+}
+
+//Executes 执行语句,返回自增编号及影响行数
+func (t *TraceDB) Executes(sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	return executes(t.dbTracer, t.db, sql, input)
+}
+
+//ExecuteBatch 批量执行语句
+func (t *TraceDB) ExecuteBatch(sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return executeBatch(t.dbTracer, t.db, sqls, input)
+}
+
+//ExecuteSP 执行存储过程
+func (t *TraceDB) ExecuteSP(procName string, input map[string]interface{}, output ...interface{}) (row int64, err error) {
+	t.trace("sp", procName, input, func() (int64, error) {
+		row, err = t.db.ExecuteSP(procName, input, output...)
+		return row, err
+	})
+	return
+}
+
+//Begin 开始事务
+func (t *TraceDB) Begin() (db.IDBTrans, error) {
+	trans, err := t.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &TraceTrans{trans: trans, dbTracer: t.dbTracer}, nil
+}
+
+//Close 关闭数据库
+func (t *TraceDB) Close() {
+	t.db.Close()
+}
+
+//Query 查询数据
+func (t *TraceTrans) Query(sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return query(t.dbTracer, t.trans, sql, input)
+}
+
+//Scalar 查询首行首列
+func (t *TraceTrans) Scalar(sql string, input map[string]interface{}) (data interface{}, err error) {
+	return scalar(t.dbTracer, t.trans, sql, input)
+}
+
+//Execute 执行语句
+func (t *TraceTrans) Execute(sql string, input map[string]interface{}) (row int64, err error) {
+	return execute(t.dbTracer, t.trans, sql, input)
+}
+
+//Executes 执行语句,返回自增编号及影响行数
+func (t *TraceTrans) Executes(sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	return executes(t.dbTracer, t.trans, sql, input)
+}
+
+//ExecuteBatch 批量执行语句
+func (t *TraceTrans) ExecuteBatch(sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	return executeBatch(t.dbTracer, t.trans, sqls, input)
+}
+
+//Rollback 回滚事务
+func (t *TraceTrans) Rollback() error {
+	return t.trans.Rollback()
+}
+
+//Commit 提交事务
+func (t *TraceTrans) Commit() error {
+	return t.trans.Commit()
+}
+
+func query(t *dbTracer, e db.IDBExecuter, sql string, input map[string]interface{}) (data db.QueryRows, err error) {
+	t.trace("query", sql, input, func() (int64, error) {
+		data, err = e.Query(sql, input)
+		return int64(len(data)), err
+	})
+	return
+}
+
+func scalar(t *dbTracer, e db.IDBExecuter, sql string, input map[string]interface{}) (data interface{}, err error) {
+	t.trace("scalar", sql, input, func() (int64, error) {
+		data, err = e.Scalar(sql, input)
+		return 1, err
+	})
+	return
+}
+
+func execute(t *dbTracer, e db.IDBExecuter, sql string, input map[string]interface{}) (row int64, err error) {
+	t.trace("execute", sql, input, func() (int64, error) {
+		row, err = e.Execute(sql, input)
+		return row, err
+	})
+	return
+}
+
+func executes(t *dbTracer, e db.IDBExecuter, sql string, input map[string]interface{}) (lastInsertID int64, affectedRow int64, err error) {
+	t.trace("execute", sql, input, func() (int64, error) {
+		lastInsertID, affectedRow, err = e.Executes(sql, input)
+		return affectedRow, err
+	})
+	return
+}
+
+func executeBatch(t *dbTracer, e db.IDBExecuter, sqls []string, input map[string]interface{}) (data db.QueryRows, err error) {
+	t.trace("batch", strings.Join(sqls, ";"), input, func() (int64, error) {
+		data, err = e.ExecuteBatch(sqls, input)
+		return int64(len(data)), err
+	})
+	return
+}
+
+//trace 执行语句并记录语句、参数、影响行数及耗时
+func (t *dbTracer) trace(op string, sql string, input map[string]interface{}, fn func() (int64, error)) {
+	var log logger.ILogger = global.Def.Log()
+	var span context.ITraceSpan
+	if ctx, ok := context.GetContext(); ok {
+		log = ctx.Log()
+		if ctx.Tracer().Available() {
+			span = ctx.Tracer().NewSpan("db." + op)
+			span.Start()
+			defer span.End()
+		}
+	}
+
+	start := time.Now()
+	rows, err := fn()
+	elapsed := time.Since(start)
+
+	statement := compact(sql)
+	params := t.mask(input)
+	if span != nil {
+		span.Tag("db.type", t.provider)
+		span.Tag("db.instance", t.name)
+		span.Tag("db.statement", statement)
+		span.Tag("db.bind_vars", fmt.Sprint(params))
+		span.Tag("db.rows", fmt.Sprint(rows))
+		if err != nil {
+			span.Tag("error", err.Error())
+		}
+	}
+	log.Debugf("db.%s[%s] %s %v rows:%d %v", op, t.name, statement, params, rows, elapsed)
+	if elapsed >= t.slow {
+		log.Warnf("慢查询db.%s[%s]耗时%v超过%v: %s %v", op, t.name, elapsed, t.slow, statement, params)
+	}
+
+	timerName := metrics.MakeName("db.statement", metrics.TIMER, "db", t.name, "host", t.host, "op", op, "sql", fingerprint(statement))
+	metrics.GetOrRegisterTimer(timerName, metrics.DefaultRegistry).Update(elapsed)
+}
+
+//mask 对参数名包含脱敏关键字的参数值进行脱敏
+func (t *dbTracer) mask(input map[string]interface{}) map[string]interface{} {
+	if len(input) == 0 {
+		return input
+	}
+	params := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		params[k] = v
+		name := strings.ToLower(k)
+		for _, m := range t.masks {
+			if strings.Contains(name, m) {
+				params[k] = maskValue
+				break
+			}
+		}
+	}
+	return params
+}
+
+//compact 合并语句中的空白字符
+func compact(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+//maxFingerprint 语句指纹最大长度
+const maxFingerprint = 128
+
+var (
+	literalRegexp = regexp.MustCompile(`'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b|[@#$&|]\w+`)
+	inListRegexp  = regexp.MustCompile(`(?i)\bin\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+)
+
+//fingerprint 获取语句指纹,将常量与参数替换为?并截断过长语句,用于统计项名称,避免统计项随语句内容无限增长
+func fingerprint(statement string) string {
+	s := literalRegexp.ReplaceAllString(statement, "?")
+	s = inListRegexp.ReplaceAllString(s, "in (?)")
+	if r := []rune(s); len(r) > maxFingerprint {
+		s = string(r[:maxFingerprint])
+	}
+	return s
+}
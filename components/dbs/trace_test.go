@@ -0,0 +1,84 @@
+package dbs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	xdb "github.com/micro-plat/hydra/conf/vars/db"
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/db"
+)
+
+func TestTraceDB_mask(t *testing.T) {
+	tests := []struct {
+		name  string
+		masks []string
+		input map[string]interface{}
+		want  map[string]interface{}
+	}{
+		{name: "1. 无参数", input: nil, want: nil},
+		{name: "2. 默认脱敏规则", input: map[string]interface{}{"user_name": "colin", "Password": "123", "access_token": "abc"},
+			want: map[string]interface{}{"user_name": "colin", "Password": maskValue, "access_token": maskValue}},
+		{name: "3. 自定义脱敏规则", masks: []string{"Mobile"}, input: map[string]interface{}{"user_name": "colin", "mobile_no": "138"},
+			want: map[string]interface{}{"user_name": "colin", "mobile_no": maskValue}},
+	}
+	for _, tt := range tests {
+		cnf := xdb.New(sqlite.Provider, "", xdb.WithMasks(tt.masks...))
+		got := NewTraceDB("db", cnf, nil).mask(tt.input)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
+
+func TestTraceDB_fingerprint(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		want      string
+	}{
+		{name: "1. 无常量语句", statement: "select name from user", want: "select name from user"},
+		{name: "2. 字符串与数字常量", statement: "select name from user where name='co''lin' and age>18.5 and t1.id=2", want: "select name from user where name=? and age>? and t1.id=?"},
+		{name: "3. 语句参数", statement: "select name from user where name=@name and id=#id and code=$1", want: "select name from user where name=? and id=? and code=?"},
+		{name: "4. in列表", statement: "select name from user where id in (1, 2,3) or name IN ('a','b')", want: "select name from user where id in (?) or name in (?)"},
+		{name: "5. 过长语句截断", statement: "select " + strings.Repeat("a,", 100) + "b from user", want: ("select " + strings.Repeat("a,", 100))[:maxFingerprint]},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, fingerprint(tt.statement), tt.name)
+	}
+}
+
+func TestTraceDB(t *testing.T) {
+	connString := "file:trace?mode=memory&cache=shared"
+	d, err := db.NewDB(sqlite.Provider, connString, 1, 1, 600)
+	assert.Equal(t, nil, err, "创建数据库")
+
+	tdb := NewTraceDB("trace", xdb.New(sqlite.Provider, connString, xdb.WithSlowThreshold(1)), d)
+	_, err = tdb.Execute("create table user(name varchar(32))", nil)
+	assert.Equal(t, nil, err, "1. 执行语句")
+
+	trans, err := tdb.Begin()
+	assert.Equal(t, nil, err, "2. 开始事务")
+	row, err := trans.Execute("insert into user(name) values(@name)", map[string]interface{}{"name": "colin"})
+	assert.Equal(t, nil, err, "2. 事务中执行语句")
+	assert.Equal(t, int64(1), row, "2. 事务中执行语句")
+	assert.Equal(t, nil, trans.Commit(), "2. 提交事务")
+
+	rows, err := tdb.Query("select name from   user", nil)
+	assert.Equal(t, nil, err, "3. 查询数据")
+	assert.Equal(t, "colin", rows.Get(0).GetString("name"), "3. 查询数据")
+
+	timerName := metrics.MakeName("db.statement", metrics.TIMER, "db", "trace", "host", tdb.host, "op", "query", "sql", "select name from user")
+	timer, ok := metrics.DefaultRegistry.Get(timerName).(metrics.Timer)
+	assert.Equal(t, true, ok, "4. 上报语句耗时")
+	assert.Equal(t, int64(1), timer.Count(), "4. 上报语句耗时")
+
+	_, err = tdb.Query("select name from user where name='colin'", nil)
+	assert.Equal(t, nil, err, "5. 查询数据")
+	_, err = tdb.Query("select name from user where name='jack'", nil)
+	assert.Equal(t, nil, err, "5. 查询数据")
+	timerName = metrics.MakeName("db.statement", metrics.TIMER, "db", "trace", "host", tdb.host, "op", "query", "sql", "select name from user where name=?")
+	timer, ok = metrics.DefaultRegistry.Get(timerName).(metrics.Timer)
+	assert.Equal(t, true, ok, "5. 常量不同的语句按指纹合并统计")
+	assert.Equal(t, int64(2), timer.Count(), "5. 常量不同的语句按指纹合并统计")
+}
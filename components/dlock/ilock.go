@@ -1,10 +1,36 @@
 package dlock
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 //ILock 分布式鍞
 type ILock interface {
+	//TryLock 尝试获取锁,未获取到时立即返回错误
 	TryLock() (err error)
+
+	//Lock 获取锁,超时(默认1分钟)未获取到时返回错误
 	Lock(timeout ...time.Duration) (err error)
+
+	//Unlock 释放锁
 	Unlock()
+
+	//TryAcquire 尝试获取锁,获取成功时返回fencing token
+	TryAcquire() (token int64, err error)
+
+	//Acquire 获取锁直到成功或ctx取消,获取成功时返回fencing token
+	Acquire(ctx context.Context) (token int64, err error)
+
+	//Release 释放锁,重入获取的锁需释放相同次数
+	Release() error
+
+	//Token 当前持有锁的fencing token,未持有锁时返回0
+	Token() int64
+
+	//Lost 租约续期失败,锁已被其它节点获取时关闭
+	Lost() <-chan struct{}
+
+	//Holder 查询当前持有者的数据及fencing token,锁未被持有时token为0
+	Holder() (data string, token int64, err error)
 }
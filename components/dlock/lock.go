@@ -0,0 +1,211 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//backend 锁的存储实现
+type backend interface {
+	//acquire 尝试获取锁,获取成功时返回fencing token
+	acquire(owner string, ttl time.Duration) (token int64, ok bool, err error)
+
+	//renew 续期,锁已不属于当前持有者时返回false
+	renew(owner string, token int64, ttl time.Duration) (bool, error)
+
+	//release 释放锁
+	release(owner string, token int64) error
+
+	//cancel 放弃获取锁
+	cancel()
+
+	//holder 查询当前持有者
+	holder() (data string, token int64, err error)
+}
+
+//Lock 基于租约的分布式锁,持有期间自动续期,获取成功时返回单调递增的fencing token,
+//同一锁对象可重入获取
+type Lock struct {
+	name  string
+	owner string
+	b     backend
+	opts  *options
+	acq   sync.Mutex
+	mu    sync.Mutex
+	holds int
+	token int64
+	lost  chan struct{}
+	stop  chan struct{}
+}
+
+var _ ILock = &Lock{}
+
+func newLock(name string, b backend, o *options) *Lock {
+	return &Lock{
+		name:  name,
+		owner: utility.GetGUID(),
+		b:     b,
+		opts:  o,
+		lost:  make(chan struct{}),
+	}
+}
+
+//TryLock 尝试获取锁,未获取到时立即返回错误
+func (l *Lock) TryLock() (err error) {
+	_, err = l.TryAcquire()
+	return err
+}
+
+//Lock 获取锁,超时(默认1分钟)未获取到时返回错误
+func (l *Lock) Lock(timeout ...time.Duration) (err error) {
+	deadline := time.Minute
+	if len(timeout) > 0 {
+		deadline = timeout[0]
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	return err
+}
+
+//Unlock 释放锁
+func (l *Lock) Unlock() {
+	l.Release()
+}
+
+//TryAcquire 尝试获取锁,获取成功时返回fencing token
+func (l *Lock) TryAcquire() (token int64, err error) {
+	l.acq.Lock()
+	defer l.acq.Unlock()
+	if token, ok := l.reenter(); ok {
+		return token, nil
+	}
+	token, ok, err := l.b.acquire(l.owner, l.opts.ttl)
+	if err != nil {
+		l.b.cancel()
+		return 0, fmt.Errorf("获取分布式锁%s失败:%w", l.name, err)
+	}
+	if !ok {
+		l.b.cancel()
+		return 0, fmt.Errorf("未获取到分布式锁%s", l.name)
+	}
+	l.hold(token)
+	return token, nil
+}
+
+//Acquire 获取锁直到成功或ctx取消,获取成功时返回fencing token
+func (l *Lock) Acquire(ctx context.Context) (token int64, err error) {
+	l.acq.Lock()
+	defer l.acq.Unlock()
+	if token, ok := l.reenter(); ok {
+		return token, nil
+	}
+	for {
+		token, ok, err := l.b.acquire(l.owner, l.opts.ttl)
+		if err != nil {
+			l.b.cancel()
+			return 0, fmt.Errorf("获取分布式锁%s失败:%w", l.name, err)
+		}
+		if ok {
+			l.hold(token)
+			return token, nil
+		}
+		select {
+		case <-ctx.Done():
+			l.b.cancel()
+			return 0, fmt.Errorf("未获取到分布式锁%s:%w", l.name, ctx.Err())
+		case <-time.After(l.opts.retry):
+		}
+	}
+}
+
+//Release 释放锁,重入获取的锁需释放相同次数
+func (l *Lock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holds == 0 {
+		return fmt.Errorf("未持有分布式锁%s", l.name)
+	}
+	l.holds--
+	if l.holds > 0 {
+		return nil
+	}
+	close(l.stop)
+	token := l.token
+	l.token = 0
+	return l.b.release(l.owner, token)
+}
+
+//Token 当前持有锁的fencing token,未持有锁时返回0
+func (l *Lock) Token() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.token
+}
+
+//Lost 租约续期失败,锁已被其它节点获取时关闭
+func (l *Lock) Lost() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lost
+}
+
+//Holder 查询当前持有者的数据及fencing token,锁未被持有时token为0
+func (l *Lock) Holder() (data string, token int64, err error) {
+	return l.b.holder()
+}
+
+func (l *Lock) reenter() (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holds == 0 {
+		return 0, false
+	}
+	l.holds++
+	return l.token, true
+}
+
+func (l *Lock) hold(token int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holds = 1
+	l.token = token
+	l.lost = make(chan struct{})
+	l.stop = make(chan struct{})
+	go l.renew(token, l.stop, l.lost)
+}
+
+//renew 每隔ttl/3续期一次,续期出错时在租约到期前持续重试
+func (l *Lock) renew(token int64, stop chan struct{}, lost chan struct{}) {
+	ticker := time.NewTicker(l.opts.ttl / 3)
+	defer ticker.Stop()
+	expire := time.Now().Add(l.opts.ttl)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ok, err := l.b.renew(l.owner, token, l.opts.ttl)
+			if err == nil && ok {
+				expire = time.Now().Add(l.opts.ttl)
+				continue
+			}
+			if err != nil && time.Now().Before(expire) {
+				continue
+			}
+			l.mu.Lock()
+			if l.token == token && l.holds > 0 {
+				l.holds = 0
+				l.token = 0
+				close(l.stop)
+				close(lost)
+			}
+			l.mu.Unlock()
+			return
+		}
+	}
+}
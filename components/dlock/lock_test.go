@@ -0,0 +1,156 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestLock(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+
+	a := NewLockByRegistry("order", r)
+	b := NewLockByRegistry("order", r)
+
+	t1, err := a.TryAcquire()
+	assert.Equal(t, nil, err, "1. 获取锁")
+	assert.Equal(t, true, t1 > 0, "1. 获取锁返回fencing token")
+	_, err = b.TryAcquire()
+	assert.NotEqual(t, nil, err, "1. 锁已被持有")
+
+	t2, err := a.TryAcquire()
+	assert.Equal(t, nil, err, "2. 重入获取锁")
+	assert.Equal(t, t1, t2, "2. 重入获取锁")
+	assert.Equal(t, nil, a.Release(), "2. 释放重入的锁")
+	_, err = b.TryAcquire()
+	assert.NotEqual(t, nil, err, "2. 释放重入的锁后仍持有锁")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	_, err = b.Acquire(ctx)
+	assert.NotEqual(t, nil, err, "3. ctx超时未获取到锁")
+
+	assert.Equal(t, nil, a.Release(), "4. 释放锁")
+	assert.NotEqual(t, nil, a.Release(), "4. 重复释放锁")
+	t3, err := b.TryAcquire()
+	assert.Equal(t, nil, err, "4. 释放后其它竞争者获取锁")
+	assert.Equal(t, true, t3 > t1, "4. fencing token单调递增")
+	assert.Equal(t, nil, b.Release(), "4. 释放锁")
+
+	t4, err := a.TryAcquire()
+	assert.Equal(t, nil, err, "5. 释放后可再次获取锁")
+	assert.Equal(t, true, t4 > t3, "5. fencing token单调递增")
+	assert.Equal(t, nil, a.Release(), "5. 释放锁")
+}
+
+func TestLock_expire(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+
+	//租约已过期的节点被删除
+	root := registry.Join("dlock", global.Current().GetPlatName(), "expire")
+	_, err = r.CreateSeqNode(registry.Join(root, "dlock_"), `{"owner":"crashed","expire":1}`)
+	assert.Equal(t, nil, err, "1. 创建过期节点")
+	a := NewLockByRegistry("expire", r, WithTTL(time.Millisecond*300))
+	_, err = a.TryAcquire()
+	assert.Equal(t, nil, err, "1. 租约过期的节点不阻塞获取锁")
+
+	//续期失败时通知锁已丢失
+	children, _, _ := r.GetChildren(root)
+	for _, name := range children {
+		r.Delete(registry.Join(root, name))
+	}
+	select {
+	case <-a.Lost():
+	case <-time.After(time.Second):
+		t.Error("2. 续期失败未通知锁已丢失")
+	}
+	assert.Equal(t, int64(0), a.Token(), "2. 锁丢失后不再持有锁")
+}
+
+func TestLock_corrupt(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+
+	//无法解析的节点视为已过期并删除
+	root := registry.Join("dlock", global.Current().GetPlatName(), "corrupt")
+	path, err := r.CreateSeqNode(registry.Join(root, "dlock_"), `not json`)
+	assert.Equal(t, nil, err, "1. 创建无法解析的节点")
+	a := NewLockByRegistry("corrupt", r)
+	_, err = a.TryAcquire()
+	assert.Equal(t, nil, err, "1. 无法解析的节点不阻塞获取锁")
+	ok, _ := r.Exists(path)
+	assert.Equal(t, false, ok, "1. 无法解析的节点已删除")
+	assert.Equal(t, nil, a.Release(), "1. 释放锁")
+}
+
+type flakyRegistry struct {
+	registry.IRegistry
+}
+
+func (f *flakyRegistry) GetValue(path string) ([]byte, int32, error) {
+	return nil, 0, errors.New("连接注册中心超时")
+}
+
+func TestLock_readError(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+
+	a := NewLockByRegistry("readerr", r)
+	b := NewLockByRegistry("readerr", &flakyRegistry{IRegistry: r})
+	_, err = a.TryAcquire()
+	assert.Equal(t, nil, err, "1. 获取锁")
+	node := a.b.(*registryBackend).node
+
+	//读取持有者节点失败时不删除节点
+	_, err = b.TryAcquire()
+	assert.Equal(t, true, err != nil, "2. 读取持有者节点失败时返回错误")
+	ok, _ := r.Exists(node)
+	assert.Equal(t, true, ok, "2. 读取失败时保留持有者节点")
+	_, err = NewLockByRegistry("readerr", r).TryAcquire()
+	assert.Equal(t, true, err != nil, "2. 持有者仍持有锁")
+	assert.Equal(t, nil, a.Release(), "3. 释放锁")
+}
+
+type counterRegistry struct {
+	registry.IRegistry
+	counter int64
+}
+
+func (c *counterRegistry) Incr(path string) (int64, error) {
+	c.counter++
+	return c.counter, nil
+}
+
+func TestLock_counter(t *testing.T) {
+	lm, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+	r := &counterRegistry{IRegistry: lm, counter: 100}
+
+	a := NewLockByRegistry("counter", r)
+	b := NewLockByRegistry("counter", r)
+	t1, err := a.TryAcquire()
+	assert.Equal(t, nil, err, "1. 获取锁")
+	assert.Equal(t, int64(101), t1, "1. fencing token由计数器生成")
+	_, token, err := b.Holder()
+	assert.Equal(t, nil, err, "1. 获取锁持有者")
+	assert.Equal(t, t1, token, "1. 持有者的fencing token")
+
+	t2, err := a.TryAcquire()
+	assert.Equal(t, nil, err, "2. 重入获取锁")
+	assert.Equal(t, t1, t2, "2. 重入不生成新的fencing token")
+	assert.Equal(t, nil, a.Release(), "2. 释放重入的锁")
+	assert.Equal(t, nil, a.Release(), "2. 释放锁")
+
+	t3, err := b.TryAcquire()
+	assert.Equal(t, nil, err, "3. 释放后其它竞争者获取锁")
+	assert.Equal(t, int64(102), t3, "3. fencing token单调递增")
+	assert.Equal(t, nil, b.Release(), "3. 释放锁")
+}
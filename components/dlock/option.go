@@ -0,0 +1,47 @@
+package dlock
+
+import "time"
+
+//defTTL 默认租约时长
+const defTTL = time.Second * 30
+
+//defRetry 默认重试间隔
+const defRetry = time.Millisecond * 500
+
+type options struct {
+	ttl   time.Duration
+	retry time.Duration
+	data  string
+}
+
+//Option 锁配置选项
+type Option func(*options)
+
+//WithTTL 设置租约时长,持有锁期间每隔ttl/3自动续期,节点异常退出后锁在ttl后自动释放
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+//WithRetry 设置获取锁失败后的重试间隔
+func WithRetry(retry time.Duration) Option {
+	return func(o *options) {
+		o.retry = retry
+	}
+}
+
+//WithData 设置持有锁时附带的数据,可通过Holder查询当前持有者的数据
+func WithData(data string) Option {
+	return func(o *options) {
+		o.data = data
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{ttl: defTTL, retry: defRetry}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,92 @@
+package dlock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	xredis "github.com/micro-plat/hydra/components/pkgs/redis"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/types"
+)
+
+//获取锁:SET NX PX成功后递增fencing计数器,锁的值为"持有者:token",附带数据保存在独立的键中
+var acquireScript = redis.NewScript(`
+if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	local token = redis.call("incr", KEYS[2])
+	redis.call("set", KEYS[1], ARGV[1] .. ":" .. token, "PX", ARGV[2])
+	redis.call("set", KEYS[3], ARGV[3], "PX", ARGV[2])
+	return token
+end
+return 0`)
+
+//续期:锁的值与当前持有者一致时延长过期时间
+var renewScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	redis.call("pexpire", KEYS[2], ARGV[2])
+	return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+
+//释放:锁的值与当前持有者一致时删除
+var releaseScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+	return redis.call("del", KEYS[1], KEYS[2])
+end
+return 0`)
+
+//NewRedisLock 构建基于redis的分布式锁,使用SET NX PX获取锁,lua脚本续期与释放,
+//fencing token由同一slot中的计数器生成
+func NewRedisLock(lockName string, client *xredis.Client, opts ...Option) *Lock {
+	o := newOptions(opts...)
+	key := fmt.Sprintf("dlock:%s:{%s}", global.Current().GetPlatName(), lockName)
+	return newLock(lockName, &redisBackend{client: client, key: key, fence: key + ":fence", dataKey: key + ":data", data: o.data}, o)
+}
+
+type redisBackend struct {
+	client  *xredis.Client
+	key     string
+	fence   string
+	dataKey string
+	data    string
+}
+
+func (b *redisBackend) acquire(owner string, ttl time.Duration) (token int64, ok bool, err error) {
+	v, err := acquireScript.Run(b.client, []string{b.key, b.fence, b.dataKey}, owner, ttl.Milliseconds(), b.data).Result()
+	if err != nil {
+		return 0, false, err
+	}
+	token = types.GetInt64(v)
+	return token, token > 0, nil
+}
+
+func (b *redisBackend) renew(owner string, token int64, ttl time.Duration) (bool, error) {
+	v, err := renewScript.Run(b.client, []string{b.key, b.dataKey}, value(owner, token), ttl.Milliseconds()).Result()
+	if err != nil {
+		return false, err
+	}
+	return types.GetInt64(v) == 1, nil
+}
+
+func (b *redisBackend) release(owner string, token int64) error {
+	return releaseScript.Run(b.client, []string{b.key, b.dataKey}, value(owner, token)).Err()
+}
+
+func (b *redisBackend) cancel() {}
+
+func (b *redisBackend) holder() (data string, token int64, err error) {
+	values, err := b.client.MGet(b.key, b.dataKey).Result()
+	if err != nil {
+		return "", 0, err
+	}
+	v := types.GetString(values[0])
+	if v == "" {
+		return "", 0, nil
+	}
+	return types.GetString(values[1]), types.GetInt64(v[strings.LastIndex(v, ":")+1:]), nil
+}
+
+func value(owner string, token int64) string {
+	return fmt.Sprintf("%s:%d", owner, token)
+}
@@ -0,0 +1,222 @@
+package dlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//NewLock 构建基于注册中心的分布式锁
+func NewLock(lockName string, registryAddr string, l logger.ILogging, opts ...Option) (lk *Lock, err error) {
+	r, err := registry.GetRegistry(registryAddr, l)
+	if err != nil {
+		return nil, err
+	}
+	return NewLockByRegistry(lockName, r, opts...), nil
+}
+
+//NewLockByRegistry 根据当前注册中心创建分布式锁,在锁节点下创建序列节点,序号最小的节点持有锁,
+//注册中心支持计数器时fencing token由计数器生成,否则使用序号,租约过期或无法解析的节点由其它竞争者删除
+func NewLockByRegistry(lockName string, r registry.IRegistry, opts ...Option) (lk *Lock) {
+	o := newOptions(opts...)
+	root := registry.Join("dlock", global.Current().GetPlatName(), lockName)
+	return newLock(lockName, &registryBackend{r: r, root: root, data: o.data}, o)
+}
+
+//incrementer 支持计数器的注册中心,序列节点序号可能回绕,fencing token优先使用计数器生成
+type incrementer interface {
+	Incr(path string) (int64, error)
+}
+
+//errCorrupt 锁节点数据无法解析
+var errCorrupt = errors.New("锁节点数据格式有误")
+
+type registryBackend struct {
+	r     registry.IRegistry
+	root  string
+	node  string
+	token int64
+	data  string
+	mu    sync.Mutex
+}
+
+type nodeValue struct {
+	Owner  string `json:"owner"`
+	Expire int64  `json:"expire"`
+	Token  int64  `json:"token,omitempty"`
+	Data   string `json:"data,omitempty"`
+}
+
+func (b *registryBackend) acquire(owner string, ttl time.Duration) (token int64, ok bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	//1. 创建或刷新当前竞争者的节点,节点已被删除时重新创建
+	if b.node != "" {
+		if ok, err := b.refresh(owner, ttl); err != nil || !ok {
+			b.node, b.token = "", 0
+		}
+	}
+	if b.node == "" {
+		if b.node, err = b.r.CreateSeqNode(registry.Join(b.root, "dlock_"), b.newValue(owner, ttl)); err != nil {
+			b.node = ""
+			return 0, false, err
+		}
+	}
+
+	//2. 检查是否有序号更小且未过期的节点,已过期或无法解析的节点视为失效并删除,
+	//读取失败且节点仍存在时保留节点并返回错误
+	seq := getSeq(b.node)
+	children, _, err := b.r.GetChildren(b.root)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, name := range children {
+		if s := getSeq(name); s < 0 || s >= seq {
+			continue
+		}
+		path := registry.Join(b.root, name)
+		v, err := b.getValue(path)
+		if err == nil && !v.expired() {
+			return 0, false, nil
+		}
+		if err == nil || errors.Is(err, errCorrupt) {
+			b.r.Delete(path)
+			continue
+		}
+		if ok, eerr := b.r.Exists(path); eerr == nil && !ok {
+			continue
+		}
+		return 0, false, err
+	}
+
+	//3. 获得锁后生成fencing token并写入节点
+	if b.token == 0 {
+		if b.token, err = b.nextToken(seq); err != nil {
+			return 0, false, err
+		}
+		if err = b.r.Update(b.node, b.newValue(owner, ttl)); err != nil {
+			b.token = 0
+			return 0, false, err
+		}
+	}
+	return b.token, true, nil
+}
+
+//nextToken 生成fencing token,注册中心支持计数器时使用不回绕的计数器,否则使用节点序号
+func (b *registryBackend) nextToken(seq int64) (int64, error) {
+	if c, ok := b.r.(incrementer); ok {
+		return c.Incr(registry.Join(b.root, "fence"))
+	}
+	return seq, nil
+}
+
+func (b *registryBackend) holder() (data string, token int64, err error) {
+	children, _, err := b.r.GetChildren(b.root)
+	if err != nil {
+		return "", 0, err
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return getSeq(children[i]) < getSeq(children[j])
+	})
+	for _, name := range children {
+		seq := getSeq(name)
+		if seq < 0 {
+			continue
+		}
+		v, err := b.getValue(registry.Join(b.root, name))
+		if err != nil || v.expired() {
+			continue
+		}
+		if v.Token > 0 {
+			return v.Data, v.Token, nil
+		}
+		return v.Data, seq, nil
+	}
+	return "", 0, nil
+}
+
+func (b *registryBackend) getValue(path string) (*nodeValue, error) {
+	buff, _, err := b.r.GetValue(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(buff) == 0 {
+		return nil, fmt.Errorf("锁节点%s无数据", path)
+	}
+	v := &nodeValue{}
+	if err := json.Unmarshal(buff, v); err != nil {
+		return nil, fmt.Errorf("%w:%s(%v)", errCorrupt, path, err)
+	}
+	return v, nil
+}
+
+func (b *registryBackend) renew(owner string, token int64, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.refresh(owner, ttl)
+}
+
+func (b *registryBackend) refresh(owner string, ttl time.Duration) (bool, error) {
+	if b.node == "" {
+		return false, nil
+	}
+	ok, err := b.r.Exists(b.node)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, b.r.Update(b.node, b.newValue(owner, ttl))
+}
+
+func (b *registryBackend) release(owner string, token int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.delete()
+}
+
+func (b *registryBackend) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.delete()
+}
+
+func (b *registryBackend) delete() error {
+	if b.node == "" {
+		return nil
+	}
+	node := b.node
+	b.node, b.token = "", 0
+	return b.r.Delete(node)
+}
+
+func (b *registryBackend) newValue(owner string, ttl time.Duration) string {
+	buff, _ := json.Marshal(&nodeValue{Owner: owner, Expire: time.Now().Add(ttl).UnixNano() / int64(time.Millisecond), Token: b.token, Data: b.data})
+	return string(buff)
+}
+
+//expired 租约是否已过期
+func (v *nodeValue) expired() bool {
+	return v.Expire > 0 && v.Expire < time.Now().UnixNano()/int64(time.Millisecond)
+}
+
+//getSeq 获取序列节点的序号
+func getSeq(path string) int64 {
+	index := strings.LastIndex(path, "_")
+	if index < 0 {
+		return -1
+	}
+	seq, err := strconv.ParseInt(path[index+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return seq
+}
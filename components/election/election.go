@@ -0,0 +1,202 @@
+package election
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/components/dlock"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//IElection 主节点选举
+type IElection interface {
+	//Campaign 开始竞选,在后台持续竞选直到Resign或系统关闭
+	Campaign()
+
+	//Resign 放弃竞选,当前为主节点时释放主节点身份
+	Resign()
+
+	//IsLeader 当前节点是否是主节点
+	IsLeader() bool
+
+	//Leader 获取当前主节点信息,无主节点时返回nil
+	Leader() (*Node, error)
+
+	//Watch 监控当前节点主节点身份的变化
+	Watch() <-chan bool
+
+	//OnChange 当前节点主节点身份变化时回调
+	OnChange(f func(isLeader bool))
+}
+
+//Node 候选节点信息
+type Node struct {
+	ID          string `json:"id"`
+	IP          string `json:"ip"`
+	HostName    string `json:"host_name"`
+	PID         int    `json:"pid"`
+	PlatName    string `json:"plat_name"`
+	SysName     string `json:"sys_name"`
+	ClusterName string `json:"cluster_name"`
+	Token       int64  `json:"token,omitempty"`
+}
+
+//Election 基于注册中心分布式锁的主节点选举,主节点持有/dlock/平台名/election_名称下的锁,
+//锁的fencing token即为任期编号
+type Election struct {
+	name     string
+	lock     *dlock.Lock
+	retry    time.Duration
+	mu       sync.Mutex
+	leader   bool
+	watchers []chan bool
+	handlers []func(bool)
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+var _ IElection = &Election{}
+
+//New 构建主节点选举
+func New(name string, r registry.IRegistry, opts ...Option) *Election {
+	o := &options{ttl: defTTL, retry: defRetry}
+	for _, opt := range opts {
+		opt(o)
+	}
+	hostName, _ := os.Hostname()
+	node := &Node{
+		ID:          utility.GetGUID(),
+		IP:          global.LocalIP(),
+		HostName:    hostName,
+		PID:         os.Getpid(),
+		PlatName:    global.Current().GetPlatName(),
+		SysName:     global.Current().GetSysName(),
+		ClusterName: global.Current().GetClusterName(),
+	}
+	buff, _ := json.Marshal(node)
+	return &Election{
+		name:  name,
+		retry: o.retry,
+		lock:  dlock.NewLockByRegistry("election_"+name, r, dlock.WithTTL(o.ttl), dlock.WithRetry(o.retry), dlock.WithData(string(buff))),
+	}
+}
+
+//Campaign 开始竞选,在后台持续竞选直到Resign或系统关闭
+func (e *Election) Campaign() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.campaign(ctx, e.done)
+	go func() {
+		select {
+		case <-global.Current().ClosingNotify():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+//Resign 放弃竞选,当前为主节点时释放主节点身份
+func (e *Election) Resign() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel = nil
+	e.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+//IsLeader 当前节点是否是主节点
+func (e *Election) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+//Leader 获取当前主节点信息,无主节点时返回nil
+func (e *Election) Leader() (*Node, error) {
+	data, token, err := e.lock.Holder()
+	if err != nil || token == 0 {
+		return nil, err
+	}
+	node := &Node{}
+	if err := json.Unmarshal([]byte(data), node); err != nil {
+		return nil, err
+	}
+	node.Token = token
+	return node, nil
+}
+
+//Watch 监控当前节点主节点身份的变化,只保留最近一次的变化
+func (e *Election) Watch() <-chan bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ch := make(chan bool, 1)
+	e.watchers = append(e.watchers, ch)
+	return ch
+}
+
+//OnChange 当前节点主节点身份变化时回调
+func (e *Election) OnChange(f func(isLeader bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, f)
+}
+
+func (e *Election) campaign(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		_, err := e.lock.Acquire(ctx)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.retry):
+				continue
+			}
+		}
+		e.setLeader(true)
+		select {
+		case <-e.lock.Lost():
+			e.setLeader(false)
+		case <-ctx.Done():
+			e.lock.Release()
+			e.setLeader(false)
+			return
+		}
+	}
+}
+
+func (e *Election) setLeader(leader bool) {
+	e.mu.Lock()
+	if e.leader == leader {
+		e.mu.Unlock()
+		return
+	}
+	e.leader = leader
+	handlers := append([]func(bool){}, e.handlers...)
+	for _, ch := range e.watchers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- leader
+	}
+	e.mu.Unlock()
+	for _, h := range handlers {
+		h(leader)
+	}
+}
@@ -0,0 +1,54 @@
+package election
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func waitChange(t *testing.T, ch <-chan bool, want bool, name string) {
+	select {
+	case v := <-ch:
+		assert.Equal(t, want, v, name)
+	case <-time.After(time.Second * 3):
+		t.Errorf("%s:超时未收到主节点变化通知", name)
+	}
+}
+
+func TestElection(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+
+	a := New("cron", r, WithTTL(time.Millisecond*300), WithRetry(time.Millisecond*50))
+	b := New("cron", r, WithTTL(time.Millisecond*300), WithRetry(time.Millisecond*50))
+	aw, bw := a.Watch(), b.Watch()
+	changes := make(chan bool, 4)
+	b.OnChange(func(isLeader bool) { changes <- isLeader })
+
+	a.Campaign()
+	waitChange(t, aw, true, "1. 首个竞选节点成为主节点")
+	b.Campaign()
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, false, b.IsLeader(), "1. 其它节点不是主节点")
+	leader, err := a.Leader()
+	assert.Equal(t, nil, err, "1. 查询主节点")
+	assert.Equal(t, true, leader != nil && leader.Token > 0, "1. 查询主节点")
+
+	a.Resign()
+	waitChange(t, aw, false, "2. 主节点放弃竞选")
+	waitChange(t, bw, true, "2. 其它节点成为主节点")
+	waitChange(t, changes, true, "2. 回调通知成为主节点")
+	next, err := b.Leader()
+	assert.Equal(t, nil, err, "2. 查询主节点")
+	assert.Equal(t, true, next.Token > leader.Token, "2. 任期编号递增")
+
+	b.Resign()
+	waitChange(t, changes, false, "3. 回调通知失去主节点")
+	leader, err = b.Leader()
+	assert.Equal(t, nil, err, "3. 无主节点")
+	assert.Equal(t, true, leader == nil, "3. 无主节点")
+}
@@ -0,0 +1,31 @@
+package election
+
+import "time"
+
+//defTTL 默认主节点租约时长
+const defTTL = time.Second * 15
+
+//defRetry 默认竞选重试间隔
+const defRetry = time.Second
+
+type options struct {
+	ttl   time.Duration
+	retry time.Duration
+}
+
+//Option 选举配置选项
+type Option func(*options)
+
+//WithTTL 设置主节点租约时长,主节点异常退出后其它节点在ttl后可当选
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+//WithRetry 设置竞选重试间隔
+func WithRetry(retry time.Duration) Option {
+	return func(o *options) {
+		o.retry = retry
+	}
+}
@@ -0,0 +1,248 @@
+package flags
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/micro-plat/hydra/conf/vars/feature"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/watcher"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//IFlags 功能开关
+type IFlags interface {
+	//Get 获取功能开关配置
+	Get(name string) (*feature.Flag, bool)
+
+	//Names 获取所有功能开关名称
+	Names() []string
+
+	//IsEnabled 功能开关对目标是否开启,开关不存在时返回false
+	IsEnabled(name string, t *feature.Target) bool
+}
+
+var _ IFlags = &Flags{}
+
+//Flags 保存在注册中心/平台名/var/feature下的功能开关,通过watcher监控开关的增删及修改
+type Flags struct {
+	r        registry.IRegistry
+	path     string
+	log      logger.ILogging
+	mu       sync.RWMutex
+	flags    map[string]*feature.Flag
+	watchers map[string]watcher.IValueWatcher
+	children watcher.IChildWatcher
+	done     chan struct{}
+	once     sync.Once
+}
+
+var cache = cmap.New(2)
+
+//Get 获取当前注册中心及平台的功能开关,首次获取时加载并监控所有开关
+func Get() (*Flags, error) {
+	key := global.Def.RegistryAddr + "|" + global.Def.PlatName
+	_, obj, err := cache.SetIfAbsentCb(key, func(i ...interface{}) (interface{}, error) {
+		r, err := registry.GetRegistry(global.Def.RegistryAddr, global.Def.Log())
+		if err != nil {
+			return nil, err
+		}
+		return New(r, global.Def.PlatName, global.Def.Log())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*Flags), nil
+}
+
+//New 加载平台的功能开关并监控变化
+func New(r registry.IRegistry, platName string, log logger.ILogging) (*Flags, error) {
+	f := &Flags{
+		r:        r,
+		path:     registry.Join(platName, "var", feature.TypeNodeName),
+		log:      log,
+		flags:    make(map[string]*feature.Flag),
+		watchers: make(map[string]watcher.IValueWatcher),
+		done:     make(chan struct{}),
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	children, err := watcher.NewChildWatcherByRegistry(r, []string{f.path}, log)
+	if err != nil {
+		return nil, err
+	}
+	notify, err := children.Start()
+	if err != nil {
+		return nil, err
+	}
+	f.children = children
+	go f.loop(notify)
+	return f, nil
+}
+
+//Get 获取功能开关配置
+func (f *Flags) Get(name string) (*feature.Flag, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	flag, ok := f.flags[name]
+	return flag, ok
+}
+
+//Names 获取所有功能开关名称
+func (f *Flags) Names() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	names := make([]string, 0, len(f.flags))
+	for name := range f.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//IsEnabled 功能开关对目标是否开启,开关不存在时返回false
+func (f *Flags) IsEnabled(name string, t *feature.Target) bool {
+	flag, ok := f.Get(name)
+	if !ok {
+		return false
+	}
+	return flag.IsEnabled(t)
+}
+
+//Close 关闭所有监控
+func (f *Flags) Close() {
+	f.once.Do(func() {
+		close(f.done)
+		if f.children != nil {
+			f.children.Close()
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, w := range f.watchers {
+			w.Close()
+		}
+	})
+}
+
+//load 加载所有功能开关,配置有误的开关不加载
+func (f *Flags) load() error {
+	ok, err := f.r.Exists(f.path)
+	if err != nil || !ok {
+		return err
+	}
+	names, _, err := f.r.GetChildren(f.path)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, _, err := f.r.GetValue(registry.Join(f.path, name))
+		if err != nil {
+			return err
+		}
+		f.set(name, data)
+		f.watch(name)
+	}
+	return nil
+}
+
+//reload 重新获取开关配置,用于删除后重新创建的开关
+func (f *Flags) reload(name string) {
+	data, _, err := f.r.GetValue(registry.Join(f.path, name))
+	if err != nil {
+		f.log.Errorf("获取功能开关%s失败:%v", name, err)
+		return
+	}
+	f.set(name, data)
+}
+
+//loop 处理开关节点的增删,新增的开关单独监控值变化。
+//各注册中心通知的子节点格式不同,收到通知后重新获取子节点
+func (f *Flags) loop(notify chan *watcher.ChildChangeArgs) {
+	for {
+		select {
+		case <-f.done:
+			return
+		case args := <-notify:
+			if args.Parent != f.path {
+				continue
+			}
+			var names []string
+			if args.OP != watcher.DEL {
+				children, _, err := f.r.GetChildren(f.path)
+				if err != nil {
+					f.log.Errorf("获取功能开关列表失败:%v", err)
+					continue
+				}
+				names = children
+			}
+			exists := make(map[string]bool, len(names))
+			for _, name := range names {
+				exists[name] = true
+				if _, ok := f.Get(name); !ok {
+					f.reload(name)
+				}
+				f.watch(name)
+			}
+			f.mu.Lock()
+			for name := range f.flags {
+				if !exists[name] {
+					delete(f.flags, name)
+				}
+			}
+			f.mu.Unlock()
+		}
+	}
+}
+
+//watch 监控开关值变化,节点删除后监控器继续等待节点重新创建,已监控的开关不重复监控
+func (f *Flags) watch(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.watchers[name]; ok {
+		return
+	}
+	w, err := watcher.NewValueWatcherByRegistry(f.r, []string{registry.Join(f.path, name)}, f.log)
+	if err != nil {
+		f.log.Errorf("监控功能开关%s失败:%v", name, err)
+		return
+	}
+	notify, err := w.Start()
+	if err != nil {
+		f.log.Errorf("监控功能开关%s失败:%v", name, err)
+		return
+	}
+	f.watchers[name] = w
+	go func() {
+		for {
+			select {
+			case <-f.done:
+				return
+			case args := <-notify:
+
+				//部分注册中心删除节点时通知原值,需检查节点是否存在
+				if ok, err := f.r.Exists(args.Path); args.OP == watcher.DEL || (err == nil && !ok) {
+					f.mu.Lock()
+					delete(f.flags, name)
+					f.mu.Unlock()
+					continue
+				}
+				f.set(name, args.Content)
+			}
+		}
+	}()
+}
+
+//set 更新开关配置,配置有误时保留原配置
+func (f *Flags) set(name string, data []byte) {
+	flag, err := feature.NewByRaw(name, data)
+	if err != nil {
+		f.log.Error(err)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags[name] = flag
+}
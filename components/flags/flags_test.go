@@ -0,0 +1,105 @@
+package flags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/vars/feature"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	_ "github.com/micro-plat/hydra/registry/watcher/wchild"
+	_ "github.com/micro-plat/hydra/registry/watcher/wvalue"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+//waitFor 等待开关变化
+func waitFor(t *testing.T, f func() bool, name string) {
+	for i := 0; i < 50; i++ {
+		if f() {
+			return
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	t.Errorf("%s:超时未收到开关变化", name)
+}
+
+func TestFlags_IsEnabled(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	root := registry.Join("flags_test", "var", feature.TypeNodeName)
+	r.CreatePersistentNode(registry.Join(root, "all"), `{"enable":true}`)
+	r.CreatePersistentNode(registry.Join(root, "off"), `{"enable":false}`)
+	r.CreatePersistentNode(registry.Join(root, "white"), `{"enable":true,"percent":0,"users":["u1"]}`)
+	r.CreatePersistentNode(registry.Join(root, "api"), `{"enable":true,"clusters":["prod"],"server_types":["api"]}`)
+	r.CreatePersistentNode(registry.Join(root, "half"), `{"enable":true,"percent":50}`)
+	r.CreatePersistentNode(registry.Join(root, "bad"), `{"enable":true,"percent":200}`)
+
+	f, err := New(r, "flags_test", global.Def.Log())
+	assert.Equal(t, nil, err, "加载功能开关")
+	defer f.Close()
+
+	api := &feature.Target{UserID: "u1", ClusterName: "prod", ServerType: "api"}
+	tests := []struct {
+		name   string
+		flag   string
+		target *feature.Target
+		want   bool
+	}{
+		{name: "1. 默认全量开启", flag: "all", target: &feature.Target{}, want: true},
+		{name: "2. 开关已关闭", flag: "off", target: api, want: false},
+		{name: "3. 开关不存在", flag: "none", target: api, want: false},
+		{name: "4. 配置有误的开关不加载", flag: "bad", target: api, want: false},
+		{name: "5. 白名单用户开启", flag: "white", target: api, want: true},
+		{name: "6. 非白名单用户关闭", flag: "white", target: &feature.Target{UserID: "u2"}, want: false},
+		{name: "7. 集群及服务器类型匹配", flag: "api", target: api, want: true},
+		{name: "8. 集群不匹配", flag: "api", target: &feature.Target{ClusterName: "gray", ServerType: "api"}, want: false},
+		{name: "9. 服务器类型不匹配", flag: "api", target: &feature.Target{ClusterName: "prod", ServerType: "cron"}, want: false},
+		{name: "10. 按比例放量时未指定用户", flag: "half", target: &feature.Target{}, want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, f.IsEnabled(tt.flag, tt.target), tt.name)
+	}
+
+	hits := 0
+	for i := 0; i < 200; i++ {
+		target := &feature.Target{UserID: string(rune('a'+i%26)) + string(rune('a'+i/26))}
+		enabled := f.IsEnabled("half", target)
+		assert.Equal(t, enabled, f.IsEnabled("half", target), "11. 同一用户结果稳定")
+		if enabled {
+			hits++
+		}
+	}
+	assert.Equal(t, true, hits > 50 && hits < 150, "11. 按用户编号放量")
+}
+
+func TestFlags_Watch(t *testing.T) {
+	r := localmemory.NewLocalMemory()
+	root := registry.Join("flags_watch", "var", feature.TypeNodeName)
+
+	f, err := New(r, "flags_watch", global.Def.Log())
+	assert.Equal(t, nil, err, "加载功能开关")
+	defer f.Close()
+	target := &feature.Target{UserID: "u1"}
+	assert.Equal(t, 0, len(f.Names()), "1. 未配置开关")
+
+	r.CreatePersistentNode(registry.Join(root, "order"), `{"enable":true}`)
+	waitFor(t, func() bool { return f.IsEnabled("order", target) }, "2. 新增开关")
+
+	r.Update(registry.Join(root, "order"), `{"enable":false}`)
+	waitFor(t, func() bool { return !f.IsEnabled("order", target) }, "3. 修改开关")
+
+	r.Update(registry.Join(root, "order"), `{"enable":true,"percent":-1}`)
+	time.Sleep(time.Millisecond * 300)
+	_, ok := f.Get("order")
+	assert.Equal(t, true, ok, "4. 配置有误时保留原配置")
+
+	r.CreatePersistentNode(registry.Join(root, "pay"), `{"enable":true}`)
+	waitFor(t, func() bool { return f.IsEnabled("pay", target) }, "5. 新增其它开关")
+	assert.Equal(t, []string{"order", "pay"}, f.Names(), "5. 新增其它开关")
+
+	r.Delete(registry.Join(root, "pay"))
+	waitFor(t, func() bool { _, ok := f.Get("pay"); return !ok }, "6. 删除开关")
+
+	r.CreatePersistentNode(registry.Join(root, "pay"), `{"enable":true}`)
+	waitFor(t, func() bool { return f.IsEnabled("pay", target) }, "7. 重新创建开关")
+}
@@ -0,0 +1,123 @@
+package pkgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//EnvelopeVersion 当前消息信封版本
+const EnvelopeVersion = 2
+
+//消息头名称
+const (
+	HeaderMessageID   = "X-Message-Id"
+	HeaderTimestamp   = "X-Message-Timestamp"
+	HeaderProducer    = "X-Message-Producer"
+	HeaderVersion     = "X-Message-Version"
+	HeaderContentType = "Content-Type"
+)
+
+//Envelope 消息信封,包含消息编号、时间戳、内容类型、生产者、用户头信息及跟踪信息
+type Envelope struct {
+	Version     int               `json:"__version__,omitempty"`
+	ID          string            `json:"__id__,omitempty"`
+	Timestamp   int64             `json:"__timestamp__,omitempty"`
+	ContentType string            `json:"__content_type__,omitempty"`
+	Producer    string            `json:"__producer__,omitempty"`
+	Trace       map[string]string `json:"__trace__,omitempty"`
+	Header      map[string]string `json:"__header__"`
+	Data        []byte            `json:"__data__"`
+}
+
+//NewEnvelope 构建消息信封,未指定内容类型时按json处理
+func NewEnvelope(content interface{}, contentType string) *Envelope {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return &Envelope{
+		Version:     EnvelopeVersion,
+		ID:          utility.GetGUID(),
+		Timestamp:   time.Now().UnixNano() / int64(time.Millisecond),
+		ContentType: contentType,
+		Producer:    fmt.Sprintf("%s/%s", global.Def.GetPlatName(), global.Def.GetSysName()),
+		Trace:       make(map[string]string),
+		Header:      make(map[string]string),
+		Data:        GetBytes(content, contentType),
+	}
+}
+
+//ParseEnvelope 解析消息信封,兼容未包含版本号的早期格式
+func ParseEnvelope(message string) (*Envelope, error) {
+	e := &Envelope{}
+	if err := json.Unmarshal([]byte(message), e); err != nil {
+		return nil, fmt.Errorf("消息格式有误:%w", err)
+	}
+	if e.Header == nil {
+		e.Header = make(map[string]string)
+	}
+	if e.Version == 0 && e.ContentType == "" {
+		e.ContentType = e.Header[HeaderContentType]
+	}
+	return e, nil
+}
+
+//SetTrace 设置跟踪信息。早期版本的消费者只读取__header__,过渡期间同时写入消息头
+func (e *Envelope) SetTrace(name string, value string) {
+	e.Trace[name] = value
+	e.Header[name] = value
+}
+
+//Marshal 转换为json串
+func (e *Envelope) Marshal() string {
+	buff, err := json.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	return string(buff)
+}
+
+//Headers 获取全部头信息,信封字段以标准头名称返回
+func (e *Envelope) Headers() map[string]string {
+	hd := make(map[string]string, len(e.Header)+len(e.Trace)+5)
+	for k, v := range e.Header {
+		hd[k] = v
+	}
+	for k, v := range e.Trace {
+		hd[k] = v
+	}
+	if e.ID != "" {
+		hd[HeaderMessageID] = e.ID
+	}
+	if e.Timestamp > 0 {
+		hd[HeaderTimestamp] = fmt.Sprint(e.Timestamp)
+	}
+	if e.Producer != "" {
+		hd[HeaderProducer] = e.Producer
+	}
+	if e.Version > 0 {
+		hd[HeaderVersion] = fmt.Sprint(e.Version)
+	}
+	if e.ContentType != "" {
+		hd[HeaderContentType] = e.ContentType
+	}
+	return hd
+}
+
+//GetBytes 根据内容类型将消息内容转换为字节数组,json类型时要求内容为json串、map或struct
+func GetBytes(content interface{}, contentType string) []byte {
+	if contentType == "" || strings.Contains(contentType, "json") {
+		return []byte(GetString(content))
+	}
+	switch v := content.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	panic(fmt.Sprintf("%s类型仅支持string或[]byte,实际是:%T", contentType, content))
+}
@@ -0,0 +1,67 @@
+package pkgs
+
+import (
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     interface{}
+		contentType string
+		header      map[string]string
+		want        string
+		wantType    string
+	}{
+		{name: "1. json串", content: `{"id":1}`, want: `{"id":1}`, wantType: "application/json"},
+		{name: "2. map对象", content: map[string]interface{}{"id": 1}, want: `{"id":1}`, wantType: "application/json"},
+		{name: "3. 文本内容", content: "hello", contentType: "text/plain", want: "hello", wantType: "text/plain"},
+		{name: "4. 二进制内容", content: []byte{1, 2, 3}, contentType: "application/octet-stream", want: string([]byte{1, 2, 3}), wantType: "application/octet-stream"},
+		{name: "5. 用户头信息", content: `{}`, header: map[string]string{"X-Tenant": "t1"}, want: `{}`, wantType: "application/json"},
+	}
+	for _, tt := range tests {
+		e := NewEnvelope(tt.content, tt.contentType)
+		for k, v := range tt.header {
+			e.Header[k] = v
+		}
+		e.SetTrace("X-Request-Id", "abc")
+
+		got, err := ParseEnvelope(e.Marshal())
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.want, string(got.Data), tt.name)
+		hd := got.Headers()
+		assert.Equal(t, e.ID, hd[HeaderMessageID], tt.name)
+		assert.Equal(t, tt.wantType, hd[HeaderContentType], tt.name)
+		assert.Equal(t, "2", hd[HeaderVersion], tt.name)
+		assert.Equal(t, "abc", hd["X-Request-Id"], tt.name)
+		assert.Equal(t, "abc", got.Header["X-Request-Id"], tt.name, "早期消费者从__header__读取")
+		for k, v := range tt.header {
+			assert.Equal(t, v, hd[k], tt.name)
+		}
+	}
+}
+
+func TestParseEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		data    string
+		header  map[string]string
+		wantErr bool
+	}{
+		{name: "1. 早期格式", message: `{"__data__":"eyJpZCI6MX0=","__header__":{"X-Request-Id":"abc"}}`, data: `{"id":1}`, header: map[string]string{"X-Request-Id": "abc"}},
+		{name: "2. 非信封格式", message: `{"id":1}`, header: map[string]string{}},
+		{name: "3. 非json格式", message: `abc`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEnvelope(tt.message)
+		assert.Equal(t, tt.wantErr, err != nil, tt.name)
+		if tt.wantErr {
+			continue
+		}
+		assert.Equal(t, tt.data, string(got.Data), tt.name)
+		assert.Equal(t, tt.header, got.Headers(), tt.name)
+	}
+}
@@ -9,7 +9,6 @@ import (
 
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/lib4go/envs"
-	"github.com/micro-plat/lib4go/types"
 )
 
 var onceLock sync.Once
@@ -37,18 +36,12 @@ func GetStringByHeader(name string, content interface{}, hd ...string) string {
 		return GetString(content)
 	}
 
-	header := make(map[string]string, 0)
-	if len(hd)%2 == 0 {
-		for i := 0; i < len(hd)/2; i++ {
-			header[fmt.Sprint(hd[i])] = hd[i+1]
-		}
-	}
-
 	//新版本hydra
-	out := types.NewXMap()
-	out.SetValue("__data__", types.StringToBytes(GetString(content)))
-	out.SetValue("__header__", header)
-	return string(out.Marshal())
+	e := NewEnvelope(content, "")
+	for i := 0; i+1 < len(hd); i += 2 {
+		e.Header[hd[i]] = hd[i+1]
+	}
+	return e.Marshal()
 }
 
 //IsOriginalQueue 是否是老版本队列
@@ -33,20 +33,63 @@ func NewByConfig(config *varredis.Redis) (r *Client, err error) {
 	r.opt.ReadTimeout = types.DecodeInt(r.opt.ReadTimeout, 0, 3, r.opt.ReadTimeout)
 	r.opt.WriteTimeout = types.DecodeInt(r.opt.WriteTimeout, 0, 3, r.opt.WriteTimeout)
 	r.opt.PoolSize = types.DecodeInt(r.opt.PoolSize, 0, 10, r.opt.PoolSize)
-	ropts := &redis.UniversalOptions{
-		Addrs:        r.opt.Addrs,
-		Password:     r.opt.Password,
-		DB:           r.opt.DbIndex,
-		DialTimeout:  time.Duration(r.opt.DialTimeout) * time.Second,
-		ReadTimeout:  time.Duration(r.opt.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(r.opt.WriteTimeout) * time.Second,
-		PoolSize:     r.opt.PoolSize,
-	}
-	r.UniversalClient = redis.NewUniversalClient(ropts)
+	r.UniversalClient = newClient(r.opt)
 	_, err = r.UniversalClient.Ping().Result()
 	return
 }
 
+//newClient 根据部署模式创建单机、哨兵或集群客户端
+func newClient(opt *varredis.Redis) redis.UniversalClient {
+	dialTimeout := time.Duration(opt.DialTimeout) * time.Second
+	readTimeout := time.Duration(opt.ReadTimeout) * time.Second
+	writeTimeout := time.Duration(opt.WriteTimeout) * time.Second
+	switch opt.GetMode() {
+	case varredis.ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        opt.Addrs,
+			Password:     opt.Password,
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: writeTimeout,
+			PoolSize:     opt.PoolSize,
+		})
+	case varredis.ModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    opt.MasterName,
+			SentinelAddrs: opt.Addrs,
+			Password:      opt.Password,
+			DB:            opt.DbIndex,
+			DialTimeout:   dialTimeout,
+			ReadTimeout:   readTimeout,
+			WriteTimeout:  writeTimeout,
+			PoolSize:      opt.PoolSize,
+		})
+	}
+	addr := ""
+	if len(opt.Addrs) > 0 {
+		addr = opt.Addrs[0]
+	}
+	return redis.NewClient(&redis.Options{
+		Addr:         addr,
+		Password:     opt.Password,
+		DB:           opt.DbIndex,
+		DialTimeout:  dialTimeout,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		PoolSize:     opt.PoolSize,
+	})
+}
+
+//ForEachNode 对每个节点执行操作,集群模式时遍历所有主节点
+func (c *Client) ForEachNode(fn func(client redis.Cmdable) error) error {
+	if cluster, ok := c.UniversalClient.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(func(client *redis.Client) error {
+			return fn(client)
+		})
+	}
+	return fn(c.UniversalClient)
+}
+
 //GetAddrs GetAddrs
 func (c *Client) GetAddrs() []string {
 	return c.opt.Addrs
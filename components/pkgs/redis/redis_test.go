@@ -3,6 +3,9 @@ package redis
 import (
 	"testing"
 
+	"github.com/go-redis/redis"
+	"github.com/micro-plat/lib4go/assert"
+
 	varredis "github.com/micro-plat/hydra/conf/vars/redis"
 )
 
@@ -34,3 +37,26 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClient_Mode(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []varredis.Option
+		mode    string
+		cluster bool
+	}{
+		{name: "1. 单个地址为单机模式", opts: []varredis.Option{varredis.WithAddrs("192.168.0.111:6379")}, mode: varredis.ModeSingle},
+		{name: "2. 多个地址为集群模式", opts: []varredis.Option{varredis.WithAddrs("192.168.0.111:6379", "192.168.0.112:6379")}, mode: varredis.ModeCluster, cluster: true},
+		{name: "3. 指定主节点名称为哨兵模式", opts: []varredis.Option{varredis.WithAddrs("192.168.0.111:26379", "192.168.0.112:26379"), varredis.WithSentinel("mymaster")}, mode: varredis.ModeSentinel},
+		{name: "4. 单个地址指定集群模式", opts: []varredis.Option{varredis.WithAddrs("192.168.0.111:6379"), varredis.WithCluster()}, mode: varredis.ModeCluster, cluster: true},
+		{name: "5. 多个地址指定单机模式", opts: []varredis.Option{varredis.WithAddrs("192.168.0.111:6379", "192.168.0.112:6379"), varredis.WithRaw(`{"mode":"single"}`)}, mode: varredis.ModeSingle},
+	}
+	for _, tt := range tests {
+		opt := varredis.New("", tt.opts...)
+		assert.Equal(t, tt.mode, opt.GetMode(), tt.name)
+		client := newClient(opt)
+		_, ok := client.(*redis.ClusterClient)
+		assert.Equal(t, tt.cluster, ok, tt.name)
+		client.Close()
+	}
+}
@@ -10,6 +10,7 @@ import (
 //IQueue 消息队列
 type IQueue interface {
 	Send(key string, value interface{}, requestID ...string) error
+	SendWith(key string, value interface{}, opts ...SendOption) error
 }
 
 //IComponentQueue Component Queue
@@ -31,15 +32,41 @@ func newQueue(proto string, confRaw string) (q *queue, err error) {
 
 //Send 发送消息
 func (q *queue) Send(key string, value interface{}, requestID ...string) error {
-	hd := make([]string, 0, 2)
 	if len(requestID) > 0 {
-		hd = append(hd, context.XRequestID, requestID[0])
-	} else {
-		if ctx, ok := context.GetContext(); ok {
-			hd = append(hd, context.XRequestID, ctx.User().GetTraceID())
-		}
+		return q.SendWith(key, value, WithRequestID(requestID[0]))
 	}
-	return q.q.Push(global.MQConf.GetQueueName(key), pkgs.GetStringByHeader(key, value, hd...))
+	return q.SendWith(key, value)
+}
+
+//SendWith 按选项发送消息,可设置分区键、消息编号、消息头等
+func (q *queue) SendWith(key string, value interface{}, opts ...SendOption) error {
+	o := &sendOption{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	name := global.MQConf.GetQueueName(key)
+
+	//兼容老版本
+	if pkgs.IsOriginalQueue(key) {
+		return q.q.Push(name, pkgs.GetString(value))
+	}
+
+	e := pkgs.NewEnvelope(value, o.contentType)
+	if o.messageID != "" {
+		e.ID = o.messageID
+	}
+	for k, v := range o.headers {
+		e.Header[k] = v
+	}
+	if o.partitionKey != "" {
+		e.Header[context.XPartitionKey] = o.partitionKey
+	}
+	if o.requestID != "" {
+		e.SetTrace(context.XRequestID, o.requestID)
+	} else if ctx, ok := context.GetContext(); ok {
+		e.SetTrace(context.XRequestID, ctx.User().GetTraceID())
+	}
+	return q.q.Push(name, e.Marshal())
 }
 
 func (q *queue) Close() error {
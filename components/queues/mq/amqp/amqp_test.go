@@ -0,0 +1,103 @@
+package amqp
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestProducer(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []queueamqp.Option
+	}{
+		{name: "1. 默认交换机"},
+		{name: "2. 指定direct交换机", opts: []queueamqp.Option{queueamqp.WithExchange("hydra", "direct")}},
+		{name: "3. 非持久化队列", opts: []queueamqp.Option{queueamqp.WithExchange("hydra", "direct"), queueamqp.WithDurable(false)}},
+	}
+	for _, tt := range tests {
+		b := newBroker()
+		reset := b.use()
+		p, err := NewProducerByConfig(queueamqp.New("amqp://127.0.0.1:5672", tt.opts...))
+		assert.Equal(t, nil, err, tt.name)
+
+		assert.Equal(t, nil, p.Push("order", `{"id":1}`), tt.name)
+		assert.Equal(t, nil, p.Push("order", `{"id":2}`), tt.name)
+		n, err := p.Count("order")
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, int64(2), n, tt.name)
+
+		v, err := p.Pop("order")
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, `{"id":1}`, v, tt.name)
+		assert.Equal(t, 0, b.pending(), tt.name+",pop后已确认")
+
+		p.Pop("order")
+		_, err = p.Pop("order")
+		assert.Equal(t, mq.Nil, err, tt.name)
+		p.Close()
+		reset()
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	tests := []struct {
+		name     string
+		requeue  bool
+		ack      bool
+		count    int
+		received int
+	}{
+		{name: "1. 确认消息", ack: true, count: 5, received: 5},
+		{name: "2. 取消消息不重新入队", ack: false, count: 5, received: 5},
+		{name: "3. 取消消息重新入队", ack: false, requeue: true, count: 1, received: 3},
+	}
+	for _, tt := range tests {
+		tt := tt
+		b := newBroker()
+		reset := b.use()
+		opts := []queueamqp.Option{queueamqp.WithExchange("hydra", "direct")}
+		if tt.requeue {
+			opts = append(opts, queueamqp.WithRequeue())
+		}
+		cnf := queueamqp.New("amqp://127.0.0.1:5672", opts...)
+		c, _ := NewConsumerByConfig(cnf)
+		assert.Equal(t, nil, c.Connect(), tt.name)
+
+		var lock sync.Mutex
+		received := 0
+		done := make(chan struct{})
+		err := c.Consume("order", 2, func(m mq.IMQCMessage) {
+			lock.Lock()
+			defer lock.Unlock()
+			received++
+			if received == tt.received {
+				close(done)
+			}
+			if tt.ack {
+				m.Ack()
+				return
+			}
+			m.Nack()
+		})
+		assert.Equal(t, nil, err, tt.name)
+
+		p, _ := NewProducerByConfig(cnf)
+		for i := 0; i < tt.count; i++ {
+			p.Push("order", `{"id":1}`)
+		}
+		select {
+		case <-done:
+		case <-time.After(time.Second * 3):
+			t.Error(tt.name, "未收到全部消息")
+		}
+		assert.Equal(t, 2, b.prefetch, tt.name+",预取数与并发数相同")
+		c.UnConsume("order")
+		c.Close()
+		reset()
+	}
+}
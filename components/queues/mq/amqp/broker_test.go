@@ -0,0 +1,238 @@
+package amqp
+
+import (
+	"sync"
+	"time"
+
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+	"github.com/streadway/amqp"
+)
+
+//broker 进程内的amqp服务,用于测试
+type broker struct {
+	lock      sync.Mutex
+	exchanges map[string]string
+	bindings  map[string]map[string][]string
+	queues    map[string]*brokerQueue
+	unacked   map[uint64]*unacked
+	tag       uint64
+	prefetch  int
+}
+
+type brokerQueue struct {
+	messages [][]byte
+	notify   chan struct{}
+}
+
+type unacked struct {
+	queue string
+	body  []byte
+}
+
+func newBroker() *broker {
+	return &broker{
+		exchanges: map[string]string{},
+		bindings:  map[string]map[string][]string{},
+		queues:    map[string]*brokerQueue{},
+		unacked:   map[uint64]*unacked{},
+	}
+}
+
+//use 将dial替换为连接到当前服务
+func (b *broker) use() func() {
+	old := dial
+	dial = func(cfg *queueamqp.AMQP) (connection, error) {
+		return &brokerConn{b: b}, nil
+	}
+	return func() { dial = old }
+}
+
+func (b *broker) queue(name string) *brokerQueue {
+	q, ok := b.queues[name]
+	if !ok {
+		q = &brokerQueue{notify: make(chan struct{}, 1)}
+		b.queues[name] = q
+	}
+	return q
+}
+
+func (b *broker) enqueue(name string, body []byte) {
+	q := b.queue(name)
+	q.messages = append(q.messages, body)
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (b *broker) pending() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.unacked)
+}
+
+func (b *broker) Ack(tag uint64, multiple bool) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.unacked, tag)
+	return nil
+}
+
+func (b *broker) Nack(tag uint64, multiple bool, requeue bool) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if m, ok := b.unacked[tag]; ok && requeue {
+		b.enqueue(m.queue, m.body)
+	}
+	delete(b.unacked, tag)
+	return nil
+}
+
+func (b *broker) Reject(tag uint64, requeue bool) error {
+	return b.Nack(tag, false, requeue)
+}
+
+//next 取出队列中的下一条消息并记录为未确认
+func (b *broker) next(name string) (amqp.Delivery, bool) {
+	q := b.queue(name)
+	if len(q.messages) == 0 {
+		return amqp.Delivery{}, false
+	}
+	body := q.messages[0]
+	q.messages = q.messages[1:]
+	b.tag++
+	b.unacked[b.tag] = &unacked{queue: name, body: body}
+	return amqp.Delivery{Acknowledger: b, DeliveryTag: b.tag, Body: body}, true
+}
+
+type brokerConn struct {
+	b      *broker
+	closed bool
+}
+
+func (c *brokerConn) Channel() (channel, error) {
+	return &brokerChannel{b: c.b, stop: map[string]chan struct{}{}}, nil
+}
+
+func (c *brokerConn) IsClosed() bool {
+	return c.closed
+}
+
+func (c *brokerConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+type brokerChannel struct {
+	b        *broker
+	prefetch int
+	stop     map[string]chan struct{}
+}
+
+func (c *brokerChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	c.prefetch = prefetchCount
+	c.b.lock.Lock()
+	c.b.prefetch = prefetchCount
+	c.b.lock.Unlock()
+	return nil
+}
+
+func (c *brokerChannel) ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error {
+	c.b.lock.Lock()
+	defer c.b.lock.Unlock()
+	c.b.exchanges[name] = kind
+	return nil
+}
+
+func (c *brokerChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	c.b.lock.Lock()
+	defer c.b.lock.Unlock()
+	q := c.b.queue(name)
+	return amqp.Queue{Name: name, Messages: len(q.messages)}, nil
+}
+
+func (c *brokerChannel) QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error {
+	c.b.lock.Lock()
+	defer c.b.lock.Unlock()
+	if _, ok := c.b.bindings[exchange]; !ok {
+		c.b.bindings[exchange] = map[string][]string{}
+	}
+	c.b.bindings[exchange][key] = append(c.b.bindings[exchange][key], name)
+	return nil
+}
+
+func (c *brokerChannel) QueueInspect(name string) (amqp.Queue, error) {
+	return c.QueueDeclare(name, false, false, false, false, nil)
+}
+
+func (c *brokerChannel) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	c.b.lock.Lock()
+	defer c.b.lock.Unlock()
+	if exchange == "" {
+		c.b.enqueue(key, msg.Body)
+		return nil
+	}
+	for _, q := range c.b.bindings[exchange][key] {
+		c.b.enqueue(q, msg.Body)
+	}
+	return nil
+}
+
+func (c *brokerChannel) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	c.b.lock.Lock()
+	defer c.b.lock.Unlock()
+	d, ok := c.b.next(queue)
+	return d, ok, nil
+}
+
+func (c *brokerChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	ch := make(chan amqp.Delivery)
+	stop := make(chan struct{})
+	c.b.lock.Lock()
+	q := c.b.queue(queue)
+	c.stop[consumer] = stop
+	c.b.lock.Unlock()
+	go func() {
+		defer close(ch)
+		for {
+			c.b.lock.Lock()
+			full := c.prefetch > 0 && len(c.b.unacked) >= c.prefetch
+			var d amqp.Delivery
+			ok := false
+			if !full {
+				d, ok = c.b.next(queue)
+			}
+			c.b.lock.Unlock()
+			if !ok {
+				select {
+				case <-stop:
+					return
+				case <-q.notify:
+				case <-time.After(time.Millisecond * 10):
+				}
+				continue
+			}
+			select {
+			case ch <- d:
+			case <-stop:
+				c.b.Nack(d.DeliveryTag, false, true)
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (c *brokerChannel) Cancel(consumer string, noWait bool) error {
+	c.b.lock.Lock()
+	defer c.b.lock.Unlock()
+	if stop, ok := c.stop[consumer]; ok {
+		close(stop)
+		delete(c.stop, consumer)
+	}
+	return nil
+}
+
+func (c *brokerChannel) Close() error {
+	return nil
+}
@@ -0,0 +1,74 @@
+package amqp
+
+import (
+	"time"
+
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+	"github.com/streadway/amqp"
+)
+
+//channel amqp通道
+type channel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	QueueInspect(name string) (amqp.Queue, error)
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Get(queue string, autoAck bool) (amqp.Delivery, bool, error)
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	Close() error
+}
+
+//connection amqp连接
+type connection interface {
+	Channel() (channel, error)
+	IsClosed() bool
+	Close() error
+}
+
+type amqpConn struct {
+	*amqp.Connection
+}
+
+//Channel 创建通道
+func (c *amqpConn) Channel() (channel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+//dial 连接到amqp服务器,测试时可替换为进程内的服务
+var dial = func(cfg *queueamqp.AMQP) (connection, error) {
+	timeout := time.Duration(cfg.DialTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	conn, err := amqp.DialConfig(cfg.Address, amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Dial:      amqp.DefaultDial(timeout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &amqpConn{Connection: conn}, nil
+}
+
+//declare 声明交换机、队列并绑定路由键
+func declare(ch channel, cfg *queueamqp.AMQP, queue string) error {
+	if cfg.Exchange != "" {
+		if err := ch.ExchangeDeclare(cfg.Exchange, cfg.ExchangeType, cfg.Durable, false, false, false, nil); err != nil {
+			return err
+		}
+	}
+	if _, err := ch.QueueDeclare(queue, cfg.Durable, false, false, false, nil); err != nil {
+		return err
+	}
+	if cfg.Exchange != "" {
+		return ch.QueueBind(queue, cfg.GetRoutingKey(queue), cfg.Exchange, false, nil)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package amqp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//Consumer amqp消息消费者
+type Consumer struct {
+	conn     connection
+	queues   cmap.ConcurrentMap
+	closeCh  chan struct{}
+	done     bool
+	lk       sync.Mutex
+	once     sync.Once
+	log      logger.ILogger
+	confOpts *queueamqp.AMQP
+}
+
+//NewConsumerByRaw 创建新的Consumer
+func NewConsumerByRaw(raw string) (*Consumer, error) {
+	return NewConsumerByConfig(queueamqp.NewByRaw(raw))
+}
+
+//NewConsumerByConfig 创建新的Consumer
+func NewConsumerByConfig(confOpts *queueamqp.AMQP) (consumer *Consumer, err error) {
+	consumer = &Consumer{
+		confOpts: confOpts,
+		queues:   cmap.New(4),
+		closeCh:  make(chan struct{}),
+		log:      logger.GetSession("mq.amqp", logger.CreateSession()),
+	}
+	return consumer, nil
+}
+
+//Connect  连接服务器
+func (consumer *Consumer) Connect() (err error) {
+	_, err = consumer.getConn()
+	return err
+}
+
+//Consume 注册消费信息,预取消息数未配置时与并发数相同
+func (consumer *Consumer) Consume(queue string, concurrency int, callback func(mq.IMQCMessage)) (err error) {
+	if strings.EqualFold(queue, "") {
+		return errors.New("队列名字不能为空")
+	}
+	if callback == nil {
+		return errors.New("回调函数不能为nil")
+	}
+	_, _, err = consumer.queues.SetIfAbsentCb(queue, func(input ...interface{}) (c interface{}, err error) {
+		queue := input[0].(string)
+		unconsumeCh := make(chan struct{})
+		nconcurrency := concurrency
+		if concurrency <= 0 {
+			nconcurrency = 10
+		}
+		go func() {
+			for {
+				err := consumer.subscribe(queue, nconcurrency, callback, unconsumeCh)
+				if err == nil {
+					return
+				}
+				consumer.log.Errorf("amqp订阅队列%s失败:%v", queue, err)
+				select {
+				case <-consumer.closeCh:
+					return
+				case <-unconsumeCh:
+					return
+				case <-time.After(time.Second * 3): //延迟重连
+				}
+			}
+		}()
+		return unconsumeCh, nil
+	}, queue)
+	return
+}
+
+//subscribe 订阅队列并阻塞至取消订阅或连接断开,连接断开时返回错误
+func (consumer *Consumer) subscribe(queue string, concurrency int, callback func(mq.IMQCMessage), unconsumeCh chan struct{}) error {
+	conn, err := consumer.getConn()
+	if err != nil {
+		return err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	prefetch := consumer.confOpts.Prefetch
+	if prefetch <= 0 {
+		prefetch = concurrency
+	}
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		return err
+	}
+	if err := declare(ch, consumer.confOpts, queue); err != nil {
+		return err
+	}
+	tag := fmt.Sprintf("%s.%s", queue, utility.GetGUID()[0:6])
+	deliveries, err := ch.Consume(queue, tag, false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range deliveries {
+				callback(newMessage(d, consumer.confOpts.Requeue))
+			}
+		}()
+	}
+	closed := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		return fmt.Errorf("队列%s的消息通道已关闭", queue)
+	case <-consumer.closeCh:
+	case <-unconsumeCh:
+	}
+	ch.Cancel(tag, false)
+	<-closed
+	return nil
+}
+
+//UnConsume 取消注册消费
+func (consumer *Consumer) UnConsume(queue string) {
+	if c, ok := consumer.queues.Get(queue); ok {
+		close(c.(chan struct{}))
+	}
+	consumer.queues.Remove(queue)
+}
+
+//Close 关闭当前连接
+func (consumer *Consumer) Close() {
+	consumer.once.Do(func() {
+		consumer.done = true
+		close(consumer.closeCh)
+	})
+	consumer.queues.Clear()
+
+	consumer.lk.Lock()
+	defer consumer.lk.Unlock()
+	if consumer.conn != nil {
+		consumer.conn.Close()
+	}
+}
+
+//getConn 获取连接,连接已断开时重新连接
+func (consumer *Consumer) getConn() (connection, error) {
+	consumer.lk.Lock()
+	defer consumer.lk.Unlock()
+	if consumer.done {
+		return nil, errors.New("amqp消费者已关闭")
+	}
+	if consumer.conn != nil && !consumer.conn.IsClosed() {
+		return consumer.conn, nil
+	}
+	conn, err := dial(consumer.confOpts)
+	if err != nil {
+		return nil, err
+	}
+	consumer.conn = conn
+	return conn, nil
+}
+
+type cresolver struct {
+}
+
+func (s *cresolver) Resolve(confRaw string) (mq.IMQC, error) {
+	return NewConsumerByRaw(confRaw)
+}
+func init() {
+	mq.RegisterConsumer(Proto, &cresolver{})
+}
@@ -0,0 +1,36 @@
+package amqp
+
+import (
+	"github.com/streadway/amqp"
+)
+
+//Message amqp消息
+type Message struct {
+	delivery amqp.Delivery
+	requeue  bool
+}
+
+//Ack 确定消息
+func (m *Message) Ack() error {
+	return m.delivery.Ack(false)
+}
+
+//Nack 取消消息,根据配置决定是否重新入队
+func (m *Message) Nack() error {
+	return m.delivery.Nack(false, m.requeue)
+}
+
+//GetMessage 获取消息
+func (m *Message) GetMessage() string {
+	return string(m.delivery.Body)
+}
+
+//Has 是否有数据
+func (m *Message) Has() bool {
+	return len(m.delivery.Body) > 0
+}
+
+//newMessage 创建消息
+func newMessage(d amqp.Delivery, requeue bool) *Message {
+	return &Message{delivery: d, requeue: requeue}
+}
@@ -0,0 +1,150 @@
+package amqp
+
+import (
+	"sync"
+
+	"github.com/micro-plat/hydra/components/queues/mq"
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/streadway/amqp"
+)
+
+//Producer amqp消息生产者
+type Producer struct {
+	conn     connection
+	ch       channel
+	declared cmap.ConcurrentMap
+	lk       sync.Mutex
+	confOpts *queueamqp.AMQP
+}
+
+//NewProducerByRaw 根据配置创建生产者
+func NewProducerByRaw(raw string) (*Producer, error) {
+	return NewProducerByConfig(queueamqp.NewByRaw(raw))
+}
+
+//NewProducerByConfig 根据配置创建生产者
+func NewProducerByConfig(confOpts *queueamqp.AMQP) (p *Producer, err error) {
+	p = &Producer{confOpts: confOpts, declared: cmap.New(4)}
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	return p, p.connect()
+}
+
+//Push 向交换机发送消息,消息按路由键投递到队列
+func (p *Producer) Push(key string, value string) error {
+	msg := amqp.Publishing{
+		ContentType: "application/json",
+		Body:        []byte(value),
+	}
+	if p.confOpts.Durable {
+		msg.DeliveryMode = amqp.Persistent
+	}
+	return p.do(func(ch channel) error {
+		if err := p.declare(ch, key); err != nil {
+			return err
+		}
+		return ch.Publish(p.confOpts.Exchange, p.confOpts.GetRoutingKey(key), false, false, msg)
+	})
+}
+
+//Pop 移除并且返回队列中的第一条消息
+func (p *Producer) Pop(key string) (value string, err error) {
+	err = p.do(func(ch channel) error {
+		if err := p.declare(ch, key); err != nil {
+			return err
+		}
+		d, ok, err := ch.Get(key, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return mq.Nil
+		}
+		value = string(d.Body)
+		return d.Ack(false)
+	})
+	return value, err
+}
+
+//Count 获取队列中的消息数
+func (p *Producer) Count(key string) (n int64, err error) {
+	err = p.do(func(ch channel) error {
+		q, err := ch.QueueInspect(key)
+		if err != nil {
+			return err
+		}
+		n = int64(q.Messages)
+		return nil
+	})
+	return n, err
+}
+
+//Close 释放资源
+func (p *Producer) Close() error {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	if p.ch != nil {
+		p.ch.Close()
+	}
+	if p.conn != nil {
+		return p.conn.Close()
+	}
+	return nil
+}
+
+//do 使用当前通道执行操作,连接断开时重连后重试一次
+func (p *Producer) do(f func(ch channel) error) error {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	if p.conn == nil || p.conn.IsClosed() {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	err := f(p.ch)
+	if err != amqp.ErrClosed {
+		return err
+	}
+	if err := p.connect(); err != nil {
+		return err
+	}
+	return f(p.ch)
+}
+
+func (p *Producer) connect() (err error) {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.declared.Clear()
+	p.conn, err = dial(p.confOpts)
+	if err != nil {
+		return err
+	}
+	p.ch, err = p.conn.Channel()
+	return err
+}
+
+func (p *Producer) declare(ch channel, queue string) error {
+	if p.declared.Has(queue) {
+		return nil
+	}
+	if err := declare(ch, p.confOpts, queue); err != nil {
+		return err
+	}
+	p.declared.Set(queue, true)
+	return nil
+}
+
+type producerResolver struct {
+}
+
+func (s *producerResolver) Resolve(confRaw string) (mq.IMQP, error) {
+	return NewProducerByRaw(confRaw)
+}
+func init() {
+	mq.RegisterProducer(Proto, &producerResolver{})
+}
+
+//Proto amqp
+const Proto = queueamqp.Proto
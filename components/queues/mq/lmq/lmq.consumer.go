@@ -7,7 +7,6 @@ import (
 
 	"github.com/micro-plat/hydra/components/queues/mq"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
-	"github.com/micro-plat/lib4go/types"
 )
 
 //Consumer 基于本地channel的Consumer
@@ -42,7 +41,10 @@ func (consumer *Consumer) Consume(queue string, concurrency int, callback func(m
 	_, _, err = consumer.queues.SetIfAbsentCb(queue, func(input ...interface{}) (c interface{}, err error) {
 		queue := input[0].(string)
 		unconsumeCh := make(chan struct{}, 1)
-		nconcurrency := types.GetMax(concurrency, 10)
+		nconcurrency := concurrency
+		if concurrency <= 0 {
+			nconcurrency = 10
+		}
 		msgChan := make(chan *Message, nconcurrency)
 		for i := 0; i < nconcurrency; i++ {
 			go func() {
@@ -0,0 +1,50 @@
+package queues
+
+//SendOption 消息发送选项
+type SendOption func(*sendOption)
+
+type sendOption struct {
+	requestID    string
+	partitionKey string
+	messageID    string
+	contentType  string
+	headers      map[string]string
+}
+
+//WithRequestID 设置请求编号
+func WithRequestID(requestID string) SendOption {
+	return func(o *sendOption) {
+		o.requestID = requestID
+	}
+}
+
+//WithPartitionKey 设置分区键,相同分区键的消息由mqc服务器按顺序处理
+func WithPartitionKey(key string) SendOption {
+	return func(o *sendOption) {
+		o.partitionKey = key
+	}
+}
+
+//WithMessageID 设置消息编号,未设置时自动生成,mqc服务器开启去重后相同编号的消息只处理一次
+func WithMessageID(id string) SendOption {
+	return func(o *sendOption) {
+		o.messageID = id
+	}
+}
+
+//WithContentType 设置消息内容类型,默认为application/json
+func WithContentType(contentType string) SendOption {
+	return func(o *sendOption) {
+		o.contentType = contentType
+	}
+}
+
+//WithHeader 设置消息头,可在mqc服务中通过Request().Headers()获取
+func WithHeader(name string, value string) SendOption {
+	return func(o *sendOption) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		o.headers[name] = value
+	}
+}
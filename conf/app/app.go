@@ -6,6 +6,7 @@ import (
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/server"
 	"github.com/micro-plat/hydra/conf/server/acl/blacklist"
+	"github.com/micro-plat/hydra/conf/server/acl/canary"
 	"github.com/micro-plat/hydra/conf/server/acl/limiter"
 	"github.com/micro-plat/hydra/conf/server/acl/proxy"
 	"github.com/micro-plat/hydra/conf/server/acl/whitelist"
@@ -50,6 +51,7 @@ type IAPPConf interface {
 	GetBlackListConf() (*blacklist.BlackList, error)
 	GetLimiterConf() (*limiter.Limiter, error)
 	GetProxyConf() (*proxy.Proxy, error)
+	GetCanaryConf() (*canary.Canary, error)
 	GetAPMConf() (*apm.APM, error)
 	GetProcessorConf() (*processor.Processor, error)
 
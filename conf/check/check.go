@@ -0,0 +1,212 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/server"
+	"github.com/micro-plat/hydra/conf/server/acl/blacklist"
+	"github.com/micro-plat/hydra/conf/server/acl/canary"
+	"github.com/micro-plat/hydra/conf/server/acl/limiter"
+	"github.com/micro-plat/hydra/conf/server/acl/proxy"
+	"github.com/micro-plat/hydra/conf/server/acl/whitelist"
+	"github.com/micro-plat/hydra/conf/server/api"
+	"github.com/micro-plat/hydra/conf/server/apm"
+	"github.com/micro-plat/hydra/conf/server/auth/apikey"
+	"github.com/micro-plat/hydra/conf/server/auth/basic"
+	"github.com/micro-plat/hydra/conf/server/auth/jwt"
+	"github.com/micro-plat/hydra/conf/server/auth/ras"
+	"github.com/micro-plat/hydra/conf/server/cron"
+	"github.com/micro-plat/hydra/conf/server/header"
+	"github.com/micro-plat/hydra/conf/server/metric"
+	"github.com/micro-plat/hydra/conf/server/mqc"
+	"github.com/micro-plat/hydra/conf/server/processor"
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/hydra/conf/server/render"
+	"github.com/micro-plat/hydra/conf/server/rpc"
+	"github.com/micro-plat/hydra/conf/server/static"
+	"github.com/micro-plat/hydra/conf/server/task"
+	"github.com/micro-plat/hydra/conf/server/ws"
+	"github.com/micro-plat/hydra/conf/vars"
+	"github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/hydra/conf/vars/rlog"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	robfig "github.com/robfig/cron/v3"
+)
+
+//Error 配置检查错误
+type Error struct {
+	Path string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%v", e.Path, e.Err)
+}
+
+//Errors 配置检查的所有错误
+type Errors []*Error
+
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("配置检查未通过,共%d个错误:", len(e)))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+//Err 存在错误时返回错误,否则返回nil
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+//checker 子配置的检查函数
+type checker struct {
+	name  string
+	check func(cnf conf.IServerConf) error
+}
+
+//httpCheckers http类服务器(api,web,ws)的子配置检查
+var httpCheckers = []checker{
+	{header.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = header.GetConf(cnf); return }},
+	{registry.Join(jwt.ParNodeName, jwt.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = jwt.GetConf(cnf); return }},
+	{metric.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = metric.GetConf(cnf); return }},
+	{static.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = static.GetConf(cnf); return }},
+	{registry.Join(apikey.ParNodeName, apikey.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = apikey.GetConf(cnf); return }},
+	{registry.Join(ras.ParNodeName, ras.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = ras.GetConf(cnf); return }},
+	{registry.Join(basic.ParNodeName, basic.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = basic.GetConf(cnf); return }},
+	{render.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = render.GetConf(cnf); return }},
+	{registry.Join(whitelist.ParNodeName, whitelist.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = whitelist.GetConf(cnf); return }},
+	{registry.Join(blacklist.ParNodeName, blacklist.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = blacklist.GetConf(cnf); return }},
+	{registry.Join(limiter.ParNodeName, limiter.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = limiter.GetConf(cnf); return }},
+	{registry.Join(proxy.ParNodeName, proxy.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = proxy.GetConf(cnf); return }},
+	{registry.Join(canary.ParNodeName, canary.SubNodeName), func(cnf conf.IServerConf) (err error) { _, err = canary.GetConf(cnf); return }},
+	{apm.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = apm.GetConf(cnf); return }},
+	{processor.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = processor.GetConf(cnf); return }},
+}
+
+//localCheckers 依赖本地文件的子配置检查(如静态文件目录),发布前的离线检查不执行
+var localCheckers = map[string]bool{
+	static.TypeNodeName: true,
+}
+
+//checkers 各类服务器的主配置及子配置检查,主配置的名称为空
+var checkers = map[string][]checker{
+	global.API: append([]checker{{"", func(cnf conf.IServerConf) (err error) { _, err = api.GetConf(cnf); return }}}, httpCheckers...),
+	global.Web: append([]checker{{"", func(cnf conf.IServerConf) (err error) { _, err = api.GetConf(cnf); return }}}, httpCheckers...),
+	global.WS:  append([]checker{{"", func(cnf conf.IServerConf) (err error) { _, err = ws.GetConf(cnf); return }}}, httpCheckers...),
+	global.RPC: {
+		{"", func(cnf conf.IServerConf) (err error) { _, err = rpc.GetConf(cnf); return }},
+		{metric.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = metric.GetConf(cnf); return }},
+	},
+	global.CRON: {
+		{"", func(cnf conf.IServerConf) (err error) { _, err = cron.GetConf(cnf); return }},
+		{task.TypeNodeName, checkTasks},
+	},
+	global.MQC: {
+		{"", func(cnf conf.IServerConf) (err error) { _, err = mqc.GetConf(cnf); return }},
+		{queue.TypeNodeName, func(cnf conf.IServerConf) (err error) { _, err = queue.GetConf(cnf); return }},
+	},
+}
+
+//Registry 检查注册中心中平台、系统、集群的服务器配置及变量配置,返回所有错误
+func Registry(r registry.IRegistry, plat string, system string, types []string, cluster string) Errors {
+	return checkRegistry(r, plat, system, types, cluster, false)
+}
+
+func checkRegistry(r registry.IRegistry, plat string, system string, types []string, cluster string, offline bool) Errors {
+	errs := make(Errors, 0, 1)
+	for _, tp := range types {
+		pub := server.NewServerPub(plat, system, tp, cluster)
+		if ok, err := r.Exists(pub.GetServerPath()); err != nil || !ok {
+			if err == nil {
+				err = fmt.Errorf("未配置%s服务器主配置", tp)
+			}
+			errs = append(errs, &Error{Path: pub.GetServerPath(), Err: err})
+			continue
+		}
+		cnf, err := server.NewServerConf(plat, system, tp, cluster, r)
+		if err != nil {
+			errs = append(errs, &Error{Path: pub.GetServerPath(), Err: err})
+			continue
+		}
+		for _, c := range checkers[tp] {
+			if offline && localCheckers[c.name] {
+				continue
+			}
+			if err := c.check(cnf); err != nil {
+				path := pub.GetServerPath()
+				if c.name != "" {
+					path = pub.GetSubConfPath(c.name)
+				}
+				errs = append(errs, &Error{Path: path, Err: err})
+			}
+		}
+		cnf.Close()
+	}
+	return append(errs, checkVars(r, plat)...)
+}
+
+//Nodes 将待发布的配置节点(路径:值)写入独立的内存注册中心后进行检查,返回所有错误,
+//检查时不依赖本地文件,依赖本地文件的子配置由服务器启动时检查
+func Nodes(nodes map[string]string, plat string, system string, types []string, cluster string) Errors {
+	r := localmemory.NewLocalMemory()
+	defer r.Close()
+	paths := make([]string, 0, len(nodes))
+	for path := range nodes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		r.CreatePersistentNode(path, nodes[path])
+	}
+	return checkRegistry(r, plat, system, types, cluster, true)
+}
+
+//checkVars 检查变量配置
+func checkVars(r registry.IRegistry, plat string) Errors {
+	errs := make(Errors, 0, 1)
+	pub := vars.NewVarPub(plat)
+	cnf, err := vars.NewVarConf(plat, r)
+	if err != nil {
+		return append(errs, &Error{Path: pub.GetVarPath(), Err: err})
+	}
+	if _, err := rlog.GetConf(cnf); err != nil {
+		errs = append(errs, &Error{Path: pub.GetVarPath(rlog.TypeNodeName, rlog.LogName), Err: err})
+	}
+	cnf.Iter(func(path string, c *conf.RawConf) bool {
+		names := strings.SplitN(path, "/", 2)
+		if len(names) == 2 && names[0] == redis.TypeNodeName {
+			if _, err := redis.GetConf(cnf, names[1]); err != nil {
+				errs = append(errs, &Error{Path: pub.GetVarPath(names...), Err: err})
+			}
+		}
+		return true
+	})
+	return errs
+}
+
+//checkTasks 检查cron任务配置及cron表达式
+func checkTasks(cnf conf.IServerConf) error {
+	tasks, err := task.GetConf(cnf)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks.Tasks {
+		if t.IsImmediately() {
+			continue
+		}
+		if _, err := robfig.ParseStandard(t.Cron); err != nil {
+			return fmt.Errorf("%s的cron表达式(%s)配置有误 %w", t.Service, t.Cron, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestNodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []string
+		nodes map[string]string
+		paths []string
+	}{
+		{name: "1. 配置正确", types: []string{"api"}, nodes: map[string]string{
+			"/p/s/api/t/conf":           `{"address":"8080"}`,
+			"/p/s/api/t/conf/acl/limit": `{"rules":[{"path":"/a","maxAllow":10,"resp":{"status":302,"content":"busy"}}]}`,
+			"/p/var/redis/r":            `{"addrs":["127.0.0.1:6379"]}`,
+		}},
+		{name: "2. 未配置主配置", types: []string{"api"}, nodes: map[string]string{},
+			paths: []string{"/p/s/api/t/conf"}},
+		{name: "3. 多个子配置有误", types: []string{"api"}, nodes: map[string]string{
+			"/p/s/api/t/conf":           `{"address":"8080"}`,
+			"/p/s/api/t/conf/acl/limit": `{"rules":[{"path":"/a","maxAllow":10,"resp":{"status":10}}]}`,
+			"/p/s/api/t/conf/render":    `a := `,
+		}, paths: []string{"/p/s/api/t/conf/render", "/p/s/api/t/conf/acl/limit"}},
+		{name: "4. cron表达式有误", types: []string{"cron"}, nodes: map[string]string{
+			"/p/s/cron/t/conf":      `{}`,
+			"/p/s/cron/t/conf/task": `{"tasks":[{"cron":"@every 10s","service":"/a"},{"cron":"x y","service":"/b"}]}`,
+		}, paths: []string{"/p/s/cron/t/conf/task"}},
+		{name: "5. 变量配置有误", types: []string{}, nodes: map[string]string{
+			"/p/var/app/rlog": `{"layout":{}}`,
+		}, paths: []string{"/p/var/app/rlog"}},
+		{name: "6. 离线检查不检查本地静态文件目录", types: []string{"api"}, nodes: map[string]string{
+			"/p/s/api/t/conf":        `{"address":"8080"}`,
+			"/p/s/api/t/conf/static": `{"dir":"./notexists"}`,
+		}},
+		{name: "7. 包含${...}内容的已有配置正常加载", types: []string{"api"}, nodes: map[string]string{
+			"/p/s/api/t/conf":        `{"address":"8080"}`,
+			"/p/s/api/t/conf/header": `{"X-Template":"${name}","X-Escape":"$${id}"}`,
+			"/p/var/app/tpl":         `hello ${user}`,
+		}},
+	}
+	for _, tt := range tests {
+		errs := Nodes(tt.nodes, "p", "s", tt.types, "t")
+		paths := make([]string, 0, len(errs))
+		for _, e := range errs {
+			paths = append(paths, e.Path)
+		}
+		assert.Equal(t, len(tt.paths), len(paths), tt.name, errs)
+		for _, p := range tt.paths {
+			assert.Equal(t, true, strings.Contains(strings.Join(paths, ","), p), tt.name, p)
+		}
+		assert.Equal(t, len(tt.paths) > 0, errs.Err() != nil, tt.name)
+	}
+}
@@ -0,0 +1,63 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+//Diff 按行比较两个配置值,json内容先格式化后再比较,返回以" "、"-"、"+"开头的行
+func Diff(from string, to string) []string {
+	a := strings.Split(format(from), "\n")
+	b := strings.Split(format(to), "\n")
+
+	//最长公共子序列
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+"+b[j])
+	}
+	return lines
+}
+
+//format 格式化json内容,非json内容原样返回
+func format(v string) string {
+	var buff bytes.Buffer
+	if err := json.Indent(&buff, []byte(v), "", "  "); err != nil {
+		return v
+	}
+	return buff.String()
+}
@@ -0,0 +1,150 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+)
+
+//Root 历史版本根路径
+const Root = "/_history"
+
+//versionsNode 保存历史版本的节点名
+const versionsNode = "_versions"
+
+//MaxVersions 每个配置节点保留的最大历史版本数
+var MaxVersions = 50
+
+//Version 配置节点的历史版本
+type Version struct {
+	Version     int64  `json:"version"`
+	Path        string `json:"path"`
+	Author      string `json:"author"`
+	Time        string `json:"time"`
+	NodeVersion int32  `json:"node_version"`
+	Data        string `json:"data"`
+}
+
+//Archive 将配置节点的当前值归档为新的历史版本,节点不存在或与最近的历史版本相同时不归档
+func Archive(r registry.IRegistry, path string) error {
+	if ok, err := r.Exists(path); err != nil || !ok {
+		return err
+	}
+	data, version, err := r.GetValue(path)
+	if err != nil {
+		return err
+	}
+	versions, err := List(r, path)
+	if err != nil {
+		return err
+	}
+	next := int64(1)
+	if len(versions) > 0 {
+		last := versions[len(versions)-1]
+		if last.Data == string(data) {
+			return nil
+		}
+		next = last.Version + 1
+	}
+	v := &Version{
+		Version:     next,
+		Path:        path,
+		Author:      getAuthor(),
+		Time:        time.Now().Format("2006-01-02 15:04:05"),
+		NodeVersion: version,
+		Data:        string(data),
+	}
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := r.CreatePersistentNode(getVersionPath(path, next), string(buff)); err != nil {
+		return fmt.Errorf("归档配置%s失败:%w", path, err)
+	}
+
+	//清理超出保留数量的历史版本
+	for i := 0; i < len(versions)+1-MaxVersions; i++ {
+		r.Delete(getVersionPath(path, versions[i].Version))
+	}
+	return nil
+}
+
+//List 获取配置节点的所有历史版本,按版本号从小到大排列
+func List(r registry.IRegistry, path string) ([]*Version, error) {
+	root := registry.Join(Root, path, versionsNode)
+	if ok, err := r.Exists(root); err != nil || !ok {
+		return nil, err
+	}
+	children, _, err := r.GetChildren(root)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]*Version, 0, len(children))
+	for _, name := range children {
+		n, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		v, err := Get(r, path, n)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+//Get 获取配置节点的指定历史版本
+func Get(r registry.IRegistry, path string, version int64) (*Version, error) {
+	buff, _, err := r.GetValue(getVersionPath(path, version))
+	if err != nil {
+		return nil, fmt.Errorf("获取配置%s的历史版本%d失败:%w", path, version, err)
+	}
+	v := &Version{}
+	if err := json.Unmarshal(buff, v); err != nil {
+		return nil, fmt.Errorf("配置%s的历史版本%d格式有误:%w", path, version, err)
+	}
+	return v, nil
+}
+
+//Rollback 将配置节点恢复为指定的历史版本,恢复前归档当前值
+func Rollback(r registry.IRegistry, path string, version int64) error {
+	v, err := Get(r, path, version)
+	if err != nil {
+		return err
+	}
+	if err := Archive(r, path); err != nil {
+		return err
+	}
+	ok, err := r.Exists(path)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return r.Update(path, v.Data)
+	}
+	return r.CreatePersistentNode(path, v.Data)
+}
+
+func getVersionPath(path string, version int64) string {
+	return registry.Join(Root, path, versionsNode, strconv.FormatInt(version, 10))
+}
+
+//getAuthor 获取当前操作人,格式为用户名@主机名
+func getAuthor() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s", name, host)
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestHistory(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+	path := "/hydra_history/apiserver/api/t/conf/router"
+
+	assert.Equal(t, nil, Archive(r, path), "1. 节点不存在时不归档")
+	versions, err := List(r, path)
+	assert.Equal(t, nil, err, "1. 节点不存在时不归档")
+	assert.Equal(t, 0, len(versions), "1. 节点不存在时不归档")
+
+	r.CreatePersistentNode(path, `{"a":1}`)
+	assert.Equal(t, nil, Archive(r, path), "2. 归档当前值")
+	assert.Equal(t, nil, Archive(r, path), "2. 与最近版本相同时不归档")
+	r.Update(path, `{"a":2}`)
+	assert.Equal(t, nil, Archive(r, path), "2. 归档当前值")
+	versions, err = List(r, path)
+	assert.Equal(t, nil, err, "2. 归档当前值")
+	assert.Equal(t, 2, len(versions), "2. 归档当前值")
+	assert.Equal(t, int64(1), versions[0].Version, "2. 版本号递增")
+	assert.Equal(t, `{"a":2}`, versions[1].Data, "2. 版本内容")
+	assert.Equal(t, true, versions[1].Author != "" && versions[1].Time != "", "2. 记录操作人及时间")
+
+	r.Update(path, `{"a":3}`)
+	assert.Equal(t, nil, Rollback(r, path, 1), "3. 恢复历史版本")
+	data, _, _ := r.GetValue(path)
+	assert.Equal(t, `{"a":1}`, string(data), "3. 恢复历史版本")
+	versions, _ = List(r, path)
+	assert.Equal(t, 3, len(versions), "3. 恢复前归档当前值")
+	assert.Equal(t, `{"a":3}`, versions[2].Data, "3. 恢复前归档当前值")
+	assert.NotEqual(t, nil, Rollback(r, path, 10), "3. 版本不存在")
+
+	MaxVersions = 2
+	defer func() { MaxVersions = 50 }()
+	r.Update(path, `{"a":4}`)
+	Archive(r, path)
+	versions, _ = List(r, path)
+	assert.Equal(t, 2, len(versions), "4. 清理超出保留数量的版本")
+	assert.Equal(t, int64(3), versions[0].Version, "4. 清理超出保留数量的版本")
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []string
+	}{
+		{name: "1. 内容相同", from: "a", to: "a", want: []string{" a"}},
+		{name: "2. 文本内容", from: "a\nb\nc", to: "a\nc\nd", want: []string{" a", "-b", " c", "+d"}},
+		{name: "3. json内容", from: `{"a":1,"b":2}`, to: `{"a":1,"b":3}`, want: []string{" {", `   "a": 1,`, `-  "b": 2`, `+  "b": 3`, " }"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Diff(tt.from, tt.to), tt.name)
+	}
+}
@@ -0,0 +1,170 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/micro-plat/hydra/conf/secret"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//VarPrefix 变量配置引用的前缀,如${var:db/db/connString}
+const VarPrefix = "var:"
+
+//EnvPrefix 环境变量引用的前缀,如${env:DB_HOST}、${env:DB_HOST:-127.0.0.1}
+const EnvPrefix = "env:"
+
+//refPattern 引用表达式,只处理env:与var:前缀的引用,其它${...}内容(如脚本、模板)保持不变,$${env:...}为转义
+var refPattern = regexp.MustCompile(`\$?\$\{\s*(?:env|var):[^{}]*\}`)
+
+//VarLookup 获取变量配置的原始内容,变量不存在时返回nil
+type VarLookup func(tp string, name string) ([]byte, error)
+
+//RegistryVarLookup 从注册中心获取平台的变量配置
+func RegistryVarLookup(r registry.IRegistry, platName string) VarLookup {
+	return func(tp string, name string) ([]byte, error) {
+		path := registry.Join(platName, "var", tp, name)
+		if ok, err := r.Exists(path); err != nil || !ok {
+			return nil, err
+		}
+		data, _, err := r.GetValue(path)
+		if err != nil {
+			return nil, err
+		}
+		return Decrypt(data)
+	}
+}
+
+//Interpolate 替换配置中的${env:环境变量}、${env:环境变量:-默认值}及${var:类型/名称/字段}引用,
+//json内容只替换字符串值,替换结果均为字符串。lookup为nil时不支持变量配置引用,存在无法解析的引用时返回错误
+func Interpolate(data []byte, lookup VarLookup) ([]byte, error) {
+	if !refPattern.Match(data) {
+		return data, nil
+	}
+	s := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(s, []byte("{")) && !bytes.HasPrefix(s, []byte("[")) {
+		v, err := expand(string(data), lookup)
+		return []byte(v), err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	var err error
+	v = walk(v, func(s string) string {
+		if err != nil {
+			return s
+		}
+		var r string
+		r, err = expand(s, lookup)
+		return r
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+//expand 替换字符串中的引用
+func expand(s string, lookup VarLookup) (string, error) {
+	var err error
+	r := refPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		var v string
+		v, err = resolve(m[2:len(m)-1], lookup)
+		return v
+	})
+	return r, err
+}
+
+//resolve 解析引用表达式
+func resolve(ref string, lookup VarLookup) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, EnvPrefix) {
+		env := strings.TrimPrefix(ref, EnvPrefix)
+		name, def, hasDef := env, "", false
+		if i := strings.Index(env, ":-"); i >= 0 {
+			name, def, hasDef = env[:i], env[i+2:], true
+		}
+		if name == "" {
+			return "", fmt.Errorf("引用${%s}未指定环境变量名称", ref)
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v, nil
+		}
+		if hasDef {
+			return def, nil
+		}
+		return "", fmt.Errorf("引用${%s}无法解析:环境变量%s未设置", ref, name)
+	}
+
+	names := strings.SplitN(strings.TrimPrefix(ref, VarPrefix), "/", 3)
+	if len(names) != 3 || names[0] == "" || names[1] == "" || names[2] == "" {
+		return "", fmt.Errorf("引用${%s}格式有误,应为${var:类型/名称/字段}", ref)
+	}
+	if lookup == nil {
+		return "", fmt.Errorf("引用${%s}无法解析:当前配置不支持引用变量配置", ref)
+	}
+	data, err := lookup(names[0], names[1])
+	if err != nil {
+		return "", fmt.Errorf("引用${%s}无法解析:%w", ref, err)
+	}
+	if data == nil {
+		return "", fmt.Errorf("引用${%s}无法解析:变量配置%s/%s不存在", ref, names[0], names[1])
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	values := make(map[string]interface{})
+	if err := decoder.Decode(&values); err != nil {
+		return "", fmt.Errorf("引用${%s}无法解析:变量配置%s/%s不是json对象", ref, names[0], names[1])
+	}
+	value, ok := values[names[2]]
+	if !ok || value == nil {
+		return "", fmt.Errorf("引用${%s}无法解析:变量配置%s/%s未配置字段%s", ref, names[0], names[1], names[2])
+	}
+	var v string
+	switch c := value.(type) {
+	case string:
+		v = c
+	case json.Number, bool:
+		v = fmt.Sprint(c)
+	default:
+		return "", fmt.Errorf("引用${%s}无法解析:字段%s不是字符串、数值或布尔值", ref, names[2])
+	}
+	if secret.IsEncrypted(v) {
+		if v, err = secret.Decrypt(v); err != nil {
+			return "", fmt.Errorf("引用${%s}解密失败:%w", ref, err)
+		}
+	}
+
+	//变量配置中的值只解析环境变量引用
+	return expand(v, nil)
+}
+
+//walk 遍历所有字符串值
+func walk(v interface{}, f func(s string) string) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		for k, i := range c {
+			c[k] = walk(i, f)
+		}
+	case []interface{}:
+		for n, i := range c {
+			c[n] = walk(i, f)
+		}
+	case string:
+		return f(c)
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	os.Setenv("HYDRA_TEST_HOST", "192.168.0.1")
+	os.Unsetenv("HYDRA_TEST_NONE")
+	defer os.Unsetenv("HYDRA_TEST_HOST")
+
+	r := localmemory.NewLocalMemory()
+	defer r.Close()
+	r.CreatePersistentNode("/plat/var/db/db", `{"provider":"mysql","connString":"root:${env:HYDRA_TEST_HOST}","maxOpen":10}`)
+	r.CreatePersistentNode("/plat/var/db/nested", `{"connString":"${var:db/db/provider}"}`)
+	r.CreatePersistentNode("/plat/var/db/text", `abc`)
+	lookup := RegistryVarLookup(r, "plat")
+
+	tests := []struct {
+		name    string
+		data    string
+		lookup  VarLookup
+		want    string
+		wantErr string
+	}{
+		{name: "1. 不包含引用", data: `{"b":1,"a":"x"}`, want: `{"b":1,"a":"x"}`},
+		{name: "2. 替换环境变量", data: `{"host":"${env:HYDRA_TEST_HOST}:8080","port":8080}`, want: `{"host":"192.168.0.1:8080","port":8080}`},
+		{name: "3. 环境变量未设置时使用默认值", data: `{"host":"${env:HYDRA_TEST_NONE:-127.0.0.1}"}`, want: `{"host":"127.0.0.1"}`},
+		{name: "4. 环境变量未设置", data: `{"host":"${env:HYDRA_TEST_NONE}"}`, wantErr: "引用${env:HYDRA_TEST_NONE}无法解析:环境变量HYDRA_TEST_NONE未设置"},
+		{name: "5. 转义引用", data: `{"host":"$${env:HYDRA_TEST_HOST}"}`, want: `{"host":"${env:HYDRA_TEST_HOST}"}`},
+		{name: "6. 嵌套对象及数组", data: `{"a":{"b":["${env:HYDRA_TEST_HOST}"]}}`, want: `{"a":{"b":["192.168.0.1"]}}`},
+		{name: "7. 非json内容", data: `host=${env:HYDRA_TEST_HOST}`, want: `host=192.168.0.1`},
+		{name: "8. 引用变量配置", data: `{"db":"${var:db/db/connString}","max":"${var:db/db/maxOpen}"}`, lookup: lookup, want: `{"db":"root:192.168.0.1","max":"10"}`},
+		{name: "9. 变量配置不存在", data: `{"db":"${var:db/none/connString}"}`, lookup: lookup, wantErr: "引用${var:db/none/connString}无法解析:变量配置db/none不存在"},
+		{name: "10. 变量字段不存在", data: `{"db":"${var:db/db/none}"}`, lookup: lookup, wantErr: "引用${var:db/db/none}无法解析:变量配置db/db未配置字段none"},
+		{name: "11. 变量配置不是json对象", data: `{"db":"${var:db/text/a}"}`, lookup: lookup, wantErr: "引用${var:db/text/a}无法解析:变量配置db/text不是json对象"},
+		{name: "12. 变量引用格式错误", data: `{"db":"${var:db/db}"}`, lookup: lookup, wantErr: "引用${var:db/db}格式有误,应为${var:类型/名称/字段}"},
+		{name: "13. 不支持引用变量配置", data: `{"db":"${var:db/db/provider}"}`, wantErr: "引用${var:db/db/provider}无法解析:当前配置不支持引用变量配置"},
+		{name: "14. 变量配置中不支持引用变量", data: `{"db":"${var:db/nested/connString}"}`, lookup: lookup, wantErr: "引用${var:db/db/provider}无法解析:当前配置不支持引用变量配置"},
+		{name: "15. 未指定前缀的${...}内容保持不变", data: `{"script":"request := ${HYDRA_TEST_NONE}", "b":1}`, want: `{"script":"request := ${HYDRA_TEST_NONE}", "b":1}`},
+		{name: "16. 脚本内容保持不变", data: "a := `${name}`\nb := \"$${x}\"", want: "a := `${name}`\nb := \"$${x}\""},
+		{name: "17. 同时包含脚本内容与引用", data: `{"tpl":"${name}","host":"${env:HYDRA_TEST_HOST}"}`, want: `{"host":"192.168.0.1","tpl":"${name}"}`},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate([]byte(tt.data), tt.lookup)
+		if tt.wantErr != "" {
+			assert.Equal(t, tt.wantErr, fmt.Sprint(err), tt.name)
+			continue
+		}
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.want, string(got), tt.name)
+	}
+}
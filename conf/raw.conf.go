@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 
+	"github.com/micro-plat/hydra/conf/secret"
 	"github.com/micro-plat/lib4go/security/md5"
 	"github.com/micro-plat/lib4go/types"
 )
@@ -23,9 +24,10 @@ var EmptyRawConf = &RawConf{
 	XMap:      types.NewXMap(),
 }
 
-//RawConf json配置文件
+//RawConf json配置文件,以enc:开头的加密配置值在构建时解密
 type RawConf struct {
 	raw       []byte
+	stored    []byte //包含加密配置值时,注册中心中存储的原串
 	version   int32
 	signature string
 	types.XMap
@@ -37,6 +39,9 @@ func NewByMap(data map[string]interface{}, version int32) (c *RawConf, err error
 	if err != nil {
 		return nil, err
 	}
+	if secret.HasEncrypted(buf) {
+		return NewByText(buf, version)
+	}
 	c = &RawConf{
 		XMap:      types.NewXMapByMap(data),
 		version:   version,
@@ -57,7 +62,13 @@ func NewByText(message []byte, version int32) (c *RawConf, err error) {
 	case bytes.HasPrefix(message, []byte("<?xml")):
 		c.XMap, err = types.NewXMapByXML(string(message))
 	case bytes.HasPrefix(message, []byte("{")) || bytes.HasPrefix(message, []byte("[")):
-		c.XMap, err = types.NewXMapByJSON(string(message))
+		if secret.HasEncrypted(message) {
+			if c.raw, err = secret.DecryptJSON(message); err != nil {
+				return nil, err
+			}
+			c.stored = message
+		}
+		c.XMap, err = types.NewXMapByJSON(string(c.raw))
 	}
 	return c, err
 }
@@ -67,6 +78,14 @@ func (j *RawConf) GetRaw() []byte {
 	return j.raw
 }
 
+//GetStoredRaw 获取注册中心中存储的原串,加密的配置值未解密
+func (j *RawConf) GetStoredRaw() []byte {
+	if j.stored == nil {
+		return j.raw
+	}
+	return j.stored
+}
+
 //GetVersion 获取当前配置的版本号
 func (j *RawConf) GetVersion() int32 {
 	return j.version
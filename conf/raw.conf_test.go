@@ -2,9 +2,12 @@ package conf
 
 import (
 	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/micro-plat/hydra/conf/secret"
 	"github.com/micro-plat/lib4go/assert"
 	"github.com/micro-plat/lib4go/security/md5"
 )
@@ -125,3 +128,26 @@ func TestRawConf_GetRaw(t *testing.T) {
 		assert.Equal(t, tt.want, got, tt.name)
 	}
 }
+
+func TestNewByText_Encrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.key")
+	assert.Equal(t, nil, secret.GenerateKeyFile(path), "生成密钥文件")
+	p, err := secret.NewKeyFile(path)
+	assert.Equal(t, nil, err, "加载密钥文件")
+	secret.SetProvider(p)
+
+	enc, err := secret.Encrypt("123456")
+	assert.Equal(t, nil, err, "加密配置值")
+	stored := []byte(fmt.Sprintf(`{"addrs":["192.168.0.1:6379"],"password":"%s"}`, enc))
+
+	c, err := NewByText(stored, 1)
+	assert.Equal(t, nil, err, "1. rawconf-NewByText-解密配置值")
+	assert.Equal(t, "123456", c.GetString("password"), "1. rawconf-NewByText-解密配置值")
+	assert.Equal(t, `{"addrs":["192.168.0.1:6379"],"password":"123456"}`, string(c.GetRaw()), "1. rawconf-NewByText-解密原串")
+	assert.Equal(t, stored, c.GetStoredRaw(), "1. rawconf-NewByText-保留存储的原串")
+	assert.Equal(t, md5.EncryptBytes(stored), c.GetSignature(), "1. rawconf-NewByText-签名")
+
+	secret.SetProvider(nil)
+	_, err = NewByText([]byte(`{"password":"enc:v1:x:y:z"}`), 1)
+	assert.NotEqual(t, nil, err, "2. rawconf-NewByText-解密失败")
+}
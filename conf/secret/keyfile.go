@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//KeyFile 本地密钥文件,每行一个base64编码的32字节主密钥,首行为加密使用的当前密钥,
+//其余行用于解密轮换前加密的配置值
+type KeyFile struct {
+	current string
+	keys    map[string][]byte
+}
+
+var _ IKeyProvider = &KeyFile{}
+
+//NewKeyFile 加载本地密钥文件
+func NewKeyFile(path string) (*KeyFile, error) {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件%s失败:%w", path, err)
+	}
+	k := &KeyFile{keys: make(map[string][]byte)}
+	scanner := bufio.NewScanner(bytes.NewReader(buff))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("密钥文件%s格式有误,每行应为base64编码的32字节密钥", path)
+		}
+		id := keyID(key)
+		if k.current == "" {
+			k.current = id
+		}
+		k.keys[id] = key
+	}
+	if k.current == "" {
+		return nil, fmt.Errorf("密钥文件%s中未包含密钥", path)
+	}
+	return k, nil
+}
+
+//GenerateKeyFile 生成新的密钥文件,文件已存在时将新密钥作为当前密钥插入首行
+func GenerateKeyFile(path string) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	old, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	content := append([]byte(base64.StdEncoding.EncodeToString(key)+"\n"), old...)
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+//WrapKey 使用当前主密钥加密数据密钥
+func (k *KeyFile) WrapKey(dek []byte) (string, []byte, error) {
+	wrapped, err := seal(k.keys[k.current], dek)
+	return k.current, wrapped, err
+}
+
+//UnwrapKey 使用指定编号的主密钥解密数据密钥
+func (k *KeyFile) UnwrapKey(id string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("密钥文件中不存在编号为%s的密钥", id)
+	}
+	return open(key, wrapped)
+}
+
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
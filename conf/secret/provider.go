@@ -0,0 +1,48 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+//KeyFileEnv 指定本地密钥文件路径的环境变量
+const KeyFileEnv = "HYDRA_SECRET_KEYFILE"
+
+//IKeyProvider 主密钥提供者,用于加密、解密每个配置值的数据密钥
+type IKeyProvider interface {
+	//WrapKey 使用当前主密钥加密数据密钥,返回主密钥编号
+	WrapKey(dek []byte) (keyID string, wrapped []byte, err error)
+
+	//UnwrapKey 使用指定编号的主密钥解密数据密钥
+	UnwrapKey(keyID string, wrapped []byte) (dek []byte, err error)
+}
+
+var provider IKeyProvider
+var providerLock sync.Mutex
+
+//SetProvider 设置主密钥提供者
+func SetProvider(p IKeyProvider) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	provider = p
+}
+
+//GetProvider 获取主密钥提供者,未设置时从环境变量HYDRA_SECRET_KEYFILE指定的密钥文件加载
+func GetProvider() (IKeyProvider, error) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	if provider != nil {
+		return provider, nil
+	}
+	path := os.Getenv(KeyFileEnv)
+	if path == "" {
+		return nil, fmt.Errorf("未设置密钥提供者,请通过环境变量%s指定密钥文件", KeyFileEnv)
+	}
+	p, err := NewKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	provider = p
+	return provider, nil
+}
@@ -0,0 +1,204 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//Prefix 加密值的前缀
+const Prefix = "enc:"
+
+//version 加密格式版本
+const version = "v1"
+
+//maskValue 脱敏后的显示值
+const maskValue = "******"
+
+//sensitives 配置名包含以下内容时视为敏感配置
+var sensitives = []string{"password", "pwd", "secret", "token", "connstring", "privatekey"}
+
+//IsEncrypted 是否是加密的配置值
+func IsEncrypted(v string) bool {
+	return strings.HasPrefix(v, Prefix)
+}
+
+//Encrypt 使用信封加密方式加密配置值:每个值生成随机数据密钥,以AES-GCM加密值,
+//数据密钥由主密钥加密后与密文一同保存,格式为enc:v1:主密钥编号:加密的数据密钥:密文
+func Encrypt(plain string) (string, error) {
+	p, err := GetProvider()
+	if err != nil {
+		return "", err
+	}
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	keyID, wrapped, err := p.WrapKey(dek)
+	if err != nil {
+		return "", err
+	}
+	data, err := seal(dek, []byte(plain))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{Prefix + version, keyID,
+		base64.StdEncoding.EncodeToString(wrapped),
+		base64.StdEncoding.EncodeToString(data)}, ":"), nil
+}
+
+//Decrypt 解密配置值,未加密的值原样返回
+func Decrypt(v string) (string, error) {
+	if !IsEncrypted(v) {
+		return v, nil
+	}
+	parts := strings.Split(v[len(Prefix):], ":")
+	if len(parts) != 4 || parts[0] != version {
+		return "", fmt.Errorf("加密的配置值格式有误")
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("加密的配置值格式有误:%w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", fmt.Errorf("加密的配置值格式有误:%w", err)
+	}
+	p, err := GetProvider()
+	if err != nil {
+		return "", err
+	}
+	dek, err := p.UnwrapKey(parts[1], wrapped)
+	if err != nil {
+		return "", fmt.Errorf("解密数据密钥失败:%w", err)
+	}
+	plain, err := open(dek, data)
+	if err != nil {
+		return "", fmt.Errorf("解密配置值失败:%w", err)
+	}
+	return string(plain), nil
+}
+
+//HasEncrypted json内容中是否包含加密的配置值
+func HasEncrypted(data []byte) bool {
+	return bytes.Contains(data, []byte(`"`+Prefix))
+}
+
+//DecryptJSON 解密json内容中所有加密的配置值
+func DecryptJSON(data []byte) ([]byte, error) {
+	if !HasEncrypted(data) {
+		return data, nil
+	}
+	v, err := unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	v = walk(v, "", func(key string, s string) string {
+		if err != nil {
+			return s
+		}
+		var plain string
+		if plain, err = Decrypt(s); err != nil {
+			err = fmt.Errorf("解密配置%s失败:%w", key, err)
+		}
+		return plain
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+//Mask 对json内容中加密的配置值及敏感配置进行脱敏,用于显示配置
+func Mask(data []byte) []byte {
+	v, err := unmarshal(data)
+	if err != nil {
+		return data
+	}
+	v = walk(v, "", func(key string, s string) string {
+		if IsEncrypted(s) {
+			return Prefix + maskValue
+		}
+		if isSensitive(key) && s != "" {
+			return maskValue
+		}
+		return s
+	})
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return data
+	}
+	return buff
+}
+
+//unmarshal 解析json,数值保持原样
+func unmarshal(data []byte) (v interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&v)
+	return v, err
+}
+
+//walk 遍历所有字符串值
+func walk(v interface{}, key string, f func(key string, s string) string) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		for k, i := range c {
+			c[k] = walk(i, k, f)
+		}
+	case []interface{}:
+		for n, i := range c {
+			c[n] = walk(i, key, f)
+		}
+	case string:
+		return f(key, c)
+	}
+	return v
+}
+
+func isSensitive(key string) bool {
+	name := strings.ToLower(key)
+	for _, s := range sensitives {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+//seal 使用AES-GCM加密,随机nonce置于密文前
+func seal(key []byte, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func open(key []byte, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("密文长度有误")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newProvider(t *testing.T, path string) {
+	assert.Equal(t, nil, GenerateKeyFile(path), "生成密钥文件")
+	p, err := NewKeyFile(path)
+	assert.Equal(t, nil, err, "加载密钥文件")
+	SetProvider(p)
+}
+
+func TestEncrypt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret.key")
+	newProvider(t, path)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "1. 加密空值", input: ""},
+		{name: "2. 加密连接串", input: "hydra/123456@orcl136"},
+	}
+	for _, tt := range tests {
+		enc, err := Encrypt(tt.input)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, true, IsEncrypted(enc), tt.name)
+		plain, err := Decrypt(enc)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.input, plain, tt.name)
+	}
+
+	//轮换密钥后仍可解密旧值
+	enc, _ := Encrypt("123456")
+	newProvider(t, path)
+	plain, err := Decrypt(enc)
+	assert.Equal(t, nil, err, "3. 轮换密钥后解密旧值")
+	assert.Equal(t, "123456", plain, "3. 轮换密钥后解密旧值")
+
+	//密钥不匹配时解密失败
+	newProvider(t, filepath.Join(t.TempDir(), "other.key"))
+	_, err = Decrypt(enc)
+	assert.NotEqual(t, nil, err, "4. 密钥不匹配")
+	plain, err = Decrypt("123456")
+	assert.Equal(t, nil, err, "5. 未加密的值原样返回")
+	assert.Equal(t, "123456", plain, "5. 未加密的值原样返回")
+}
+
+func TestDecryptJSON(t *testing.T) {
+	newProvider(t, filepath.Join(t.TempDir(), "secret.key"))
+	password, _ := Encrypt("123456")
+	input, _ := json.Marshal(map[string]interface{}{
+		"addrs":    []string{"192.168.0.1:6379"},
+		"password": password,
+		"db":       1,
+	})
+
+	buff, err := DecryptJSON(input)
+	assert.Equal(t, nil, err, "1. 解密json中的加密值")
+	assert.Equal(t, `{"addrs":["192.168.0.1:6379"],"db":1,"password":"123456"}`, string(buff), "1. 解密json中的加密值")
+
+	assert.Equal(t, `{"addrs":["192.168.0.1:6379"],"db":1,"password":"enc:******"}`, string(Mask(input)), "2. 加密值脱敏")
+	assert.Equal(t, `{"addrs":["192.168.0.1:6379"],"db":1,"password":"******"}`, string(Mask(buff)), "3. 敏感配置脱敏")
+}
@@ -0,0 +1,192 @@
+/*
+按声明的规则将部分请求转发到灰度集群。先按固定规则(请求头、cookie、用户)匹配，未匹配时按权重分配，
+启用粘性路由后同一用户始终分配到同一集群。未分配到灰度集群的请求由当前集群处理，修改或删除配置即可回滚。
+*/
+
+package canary
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/registry"
+)
+
+const (
+	//ParNodeName canary配置父节点名
+	ParNodeName = "acl"
+
+	//SubNodeName canary配置子节点名
+	SubNodeName = "canary"
+)
+
+//固定规则的取值来源
+const (
+	ByHeader = "header"
+	ByCookie = "cookie"
+	ByUser   = "user"
+)
+
+//Target 灰度集群及分配的流量百分比
+type Target struct {
+	Cluster string `json:"cluster" valid:"required" toml:"cluster" label:"灰度集群"`
+	Weight  int    `json:"weight" toml:"weight" label:"流量百分比"`
+}
+
+//Pin 固定规则,请求头、cookie或用户的值在列表中时转到指定集群
+type Pin struct {
+	By      string   `json:"by" valid:"in(header|cookie|user),required" toml:"by" label:"取值来源"`
+	Name    string   `json:"name,omitempty" toml:"name,omitempty" label:"名称"`
+	Values  []string `json:"values" valid:"required" toml:"values" label:"匹配值"`
+	Cluster string   `json:"cluster" valid:"required" toml:"cluster" label:"目标集群"`
+}
+
+//Canary 灰度路由配置
+type Canary struct {
+	Paths   []string  `json:"paths,omitempty" toml:"paths,omitempty" label:"灰度路径"`
+	Targets []*Target `json:"targets,omitempty" toml:"targets,omitempty" label:"灰度集群"`
+	Pins    []*Pin    `json:"pins,omitempty" toml:"pins,omitempty" label:"固定规则"`
+	User    string    `json:"user,omitempty" valid:"matches(^(header|cookie):.+$)" toml:"user,omitempty" label:"用户标识"`
+	Sticky  bool      `json:"sticky,omitempty" toml:"sticky,omitempty"`
+	Disable bool      `json:"disable,omitempty" toml:"disable,omitempty"`
+	p       *conf.PathMatch
+}
+
+//New 构建灰度路由配置
+func New(opts ...Option) *Canary {
+	c := &Canary{}
+	for _, f := range opts {
+		f(c)
+	}
+	c.p = conf.NewPathMatch(c.Paths...)
+	return c
+}
+
+//Match 请求路径是否需要灰度
+func (c *Canary) Match(path string) bool {
+	if c.Disable {
+		return false
+	}
+	if len(c.Paths) == 0 {
+		return true
+	}
+	ok, _ := c.p.Match(path)
+	return ok
+}
+
+//Route 获取处理请求的集群,返回空表示由当前集群处理
+func (c *Canary) Route(req *http.Request) string {
+	//固定规则优先
+	for _, pin := range c.Pins {
+		v := c.getValue(req, pin.By, pin.Name)
+		if v != "" && contains(pin.Values, v) {
+			return pin.Cluster
+		}
+	}
+
+	//按权重分配,粘性路由时根据用户标识计算分配位置
+	bucket := -1
+	if c.Sticky {
+		if user := c.GetUser(req); user != "" {
+			h := fnv.New32a()
+			h.Write([]byte(user))
+			bucket = int(h.Sum32() % 100)
+		}
+	}
+	if bucket < 0 {
+		bucket = rand.Intn(100)
+	}
+	total := 0
+	for _, t := range c.Targets {
+		total += t.Weight
+		if bucket < total {
+			return t.Cluster
+		}
+	}
+	return ""
+}
+
+//GetUser 获取请求的用户标识
+func (c *Canary) GetUser(req *http.Request) string {
+	parties := strings.SplitN(c.User, ":", 2)
+	if len(parties) != 2 {
+		return ""
+	}
+	return c.getValue(req, parties[0], parties[1])
+}
+
+func (c *Canary) getValue(req *http.Request, by string, name string) string {
+	switch by {
+	case ByHeader:
+		return req.Header.Get(name)
+	case ByCookie:
+		if cookie, err := req.Cookie(name); err == nil {
+			return cookie.Value
+		}
+	case ByUser:
+		return c.GetUser(req)
+	}
+	return ""
+}
+
+//GetConf 获取灰度路由配置
+func GetConf(cnf conf.IServerConf) (*Canary, error) {
+	canary := &Canary{}
+	_, err := cnf.GetSubObject(registry.Join(ParNodeName, SubNodeName), canary)
+	if errors.Is(err, conf.ErrNoSetting) {
+		return &Canary{Disable: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("绑定canary配置有误:%v", err)
+	}
+	if b, err := govalidator.ValidateStruct(canary); !b {
+		return nil, fmt.Errorf("canary配置数据有误:%v %+v", err, canary)
+	}
+	if !canary.Disable && !cnf.AllowGray() {
+		return nil, fmt.Errorf("canary配置有误:%s服务器不支持灰度", cnf.GetServerType())
+	}
+	total := 0
+	for _, t := range canary.Targets {
+		if b, err := govalidator.ValidateStruct(t); !b {
+			return nil, fmt.Errorf("canary配置数据有误:%v %+v", err, t)
+		}
+		if t.Weight < 0 || t.Weight > 100 {
+			return nil, fmt.Errorf("canary配置数据有误:集群%s的流量百分比%d不在0-100之间", t.Cluster, t.Weight)
+		}
+		total += t.Weight
+	}
+	if total > 100 {
+		return nil, fmt.Errorf("canary配置数据有误:灰度集群的流量百分比之和%d超过100", total)
+	}
+	for _, pin := range canary.Pins {
+		if b, err := govalidator.ValidateStruct(pin); !b {
+			return nil, fmt.Errorf("canary配置数据有误:%v %+v", err, pin)
+		}
+		if pin.By == ByUser && canary.User == "" {
+			return nil, fmt.Errorf("canary配置数据有误:按用户固定路由时需配置用户标识user")
+		}
+		if pin.By != ByUser && pin.Name == "" {
+			return nil, fmt.Errorf("canary配置数据有误:按%s固定路由时需配置名称name", pin.By)
+		}
+	}
+	if canary.Sticky && canary.User == "" {
+		return nil, fmt.Errorf("canary配置数据有误:粘性路由需配置用户标识user")
+	}
+	canary.p = conf.NewPathMatch(canary.Paths...)
+	return canary, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
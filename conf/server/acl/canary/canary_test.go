@@ -0,0 +1,73 @@
+package canary
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newRequest(headers map[string]string, cookies map[string]string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/order/query", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range cookies {
+		req.AddCookie(&http.Cookie{Name: k, Value: v})
+	}
+	return req
+}
+
+func TestCanary_Match(t *testing.T) {
+	tests := []struct {
+		name   string
+		canary *Canary
+		path   string
+		want   bool
+	}{
+		{name: "1. 未设置路径", canary: New(WithTarget("gray", 10)), path: "/order/query", want: true},
+		{name: "2. 路径匹配", canary: New(WithPaths("/order/*"), WithTarget("gray", 10)), path: "/order/query", want: true},
+		{name: "3. 路径不匹配", canary: New(WithPaths("/user/*"), WithTarget("gray", 10)), path: "/order/query", want: false},
+		{name: "4. 已禁用", canary: New(WithTarget("gray", 10), WithDisable()), path: "/order/query", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.canary.Match(tt.path), tt.name)
+	}
+}
+
+func TestCanary_Route(t *testing.T) {
+	tests := []struct {
+		name   string
+		canary *Canary
+		req    *http.Request
+		want   string
+	}{
+		{name: "1. 全部流量转到灰度集群", canary: New(WithTarget("gray", 100)), req: newRequest(nil, nil), want: "gray"},
+		{name: "2. 未分配流量", canary: New(WithTarget("gray", 0)), req: newRequest(nil, nil), want: ""},
+		{name: "3. 请求头固定规则", canary: New(WithTarget("gray", 0), WithHeaderPin("X-Canary", "beta", "1")), req: newRequest(map[string]string{"X-Canary": "1"}, nil), want: "beta"},
+		{name: "4. 请求头值不匹配", canary: New(WithTarget("gray", 0), WithHeaderPin("X-Canary", "beta", "1")), req: newRequest(map[string]string{"X-Canary": "2"}, nil), want: ""},
+		{name: "5. cookie固定规则", canary: New(WithCookiePin("canary", "beta", "on")), req: newRequest(nil, map[string]string{"canary": "on"}), want: "beta"},
+		{name: "6. 用户固定规则", canary: New(WithUser("header:X-User-Id"), WithUserPin("beta", "u1", "u2")), req: newRequest(map[string]string{"X-User-Id": "u2"}, nil), want: "beta"},
+		{name: "7. 固定规则优先于权重", canary: New(WithTarget("gray", 100), WithHeaderPin("X-Canary", "beta", "1")), req: newRequest(map[string]string{"X-Canary": "1"}, nil), want: "beta"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.canary.Route(tt.req), tt.name)
+	}
+}
+
+func TestCanary_Sticky(t *testing.T) {
+	canary := New(WithUser("cookie:uid"), WithSticky(), WithTarget("gray", 50))
+	hits := 0
+	for i := 0; i < 100; i++ {
+		uid := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		req := newRequest(nil, map[string]string{"uid": uid})
+		cluster := canary.Route(req)
+		for n := 0; n < 5; n++ {
+			assert.Equal(t, cluster, canary.Route(req), "同一用户应转到同一集群")
+		}
+		if cluster == "gray" {
+			hits++
+		}
+	}
+	assert.Equal(t, true, hits > 20 && hits < 80, "流量应按权重分配")
+}
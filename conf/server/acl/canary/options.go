@@ -0,0 +1,67 @@
+package canary
+
+//Option 配置选项
+type Option func(*Canary)
+
+//WithPaths 设置需灰度的请求路径,未设置时所有请求均参与灰度
+func WithPaths(paths ...string) Option {
+	return func(a *Canary) {
+		a.Paths = append(a.Paths, paths...)
+	}
+}
+
+//WithTarget 将指定百分比的流量转到灰度集群
+func WithTarget(cluster string, weight int) Option {
+	return func(a *Canary) {
+		a.Targets = append(a.Targets, &Target{Cluster: cluster, Weight: weight})
+	}
+}
+
+//WithHeaderPin 请求头的值在列表中时转到指定集群
+func WithHeaderPin(name string, cluster string, values ...string) Option {
+	return func(a *Canary) {
+		a.Pins = append(a.Pins, &Pin{By: ByHeader, Name: name, Values: values, Cluster: cluster})
+	}
+}
+
+//WithCookiePin cookie的值在列表中时转到指定集群
+func WithCookiePin(name string, cluster string, values ...string) Option {
+	return func(a *Canary) {
+		a.Pins = append(a.Pins, &Pin{By: ByCookie, Name: name, Values: values, Cluster: cluster})
+	}
+}
+
+//WithUserPin 用户在列表中时转到指定集群
+func WithUserPin(cluster string, users ...string) Option {
+	return func(a *Canary) {
+		a.Pins = append(a.Pins, &Pin{By: ByUser, Values: users, Cluster: cluster})
+	}
+}
+
+//WithUser 设置用户标识的来源,如header:X-User-Id、cookie:uid
+func WithUser(user string) Option {
+	return func(a *Canary) {
+		a.User = user
+	}
+}
+
+//WithSticky 启用粘性路由,同一用户始终转到同一集群
+func WithSticky() Option {
+	return func(a *Canary) {
+		a.Sticky = true
+	}
+}
+
+//WithDisable 关闭
+func WithDisable() Option {
+	return func(a *Canary) {
+		a.Disable = true
+	}
+}
+
+//WithEnable 开启
+func WithEnable() Option {
+	return func(a *Canary) {
+		a.Disable = false
+	}
+}
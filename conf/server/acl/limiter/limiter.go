@@ -76,6 +76,14 @@ func GetConf(cnf conf.IServerConf) (*Limiter, error) {
 	if b, err := govalidator.ValidateStruct(limiter); !b {
 		return nil, fmt.Errorf("limit配置数据有误:%v %+v", err, limiter)
 	}
+	for _, rule := range limiter.Rules {
+		if b, err := govalidator.ValidateStruct(rule); !b {
+			return nil, fmt.Errorf("limit配置数据有误:%v %+v", err, rule)
+		}
+		if b, err := govalidator.ValidateStruct(rule.Resp); !b {
+			return nil, fmt.Errorf("limit配置的resp数据有误:%v %+v", err, rule.Resp)
+		}
+	}
 
 	newLimit := New(WithRuleList(limiter.Rules...))
 	newLimit.Disable = limiter.Disable
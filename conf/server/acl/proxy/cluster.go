@@ -10,11 +10,27 @@ import (
 
 var clusters = cmap.New(2)
 
+//UpCluster 上游集群
 type UpCluster struct {
 	c    conf.ICluster
 	name string
 }
 
+//GetUpCluster 保存到缓存，或从缓存获取上游集群信息
+func GetUpCluster(cnf conf.IServerConf, name string) (*UpCluster, error) {
+	_, cluster, err := clusters.SetIfAbsentCb(name, func(value ...interface{}) (interface{}, error) {
+		up, err := cnf.GetCluster(name)
+		if err != nil {
+			return nil, err
+		}
+		return &UpCluster{c: up, name: name}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cluster.(*UpCluster), nil
+}
+
 //Next 获取下一个可用的上游地址
 func (c *UpCluster) Next() (u *url.URL, err error) {
 
@@ -45,18 +45,11 @@ func (g *Proxy) Check() (*UpCluster, bool, error) {
 		return nil, false, nil
 	}
 
-	//保存到缓存，或从缓存获取上游集群信息
-	_, cluster, err := clusters.SetIfAbsentCb(upstream, func(value ...interface{}) (interface{}, error) {
-		up, err := g.c.GetCluster(upstream)
-		if err != nil {
-			return nil, err
-		}
-		return &UpCluster{c: up, name: upstream}, nil
-	})
+	cluster, err := GetUpCluster(g.c, upstream)
 	if err != nil {
 		return nil, false, err
 	}
-	return cluster.(*UpCluster), true, nil
+	return cluster, true, nil
 
 }
 
@@ -28,6 +28,18 @@ type Server struct {
 	Sharding int    `json:"sharding,omitempty" toml:"sharding,omitempty"`
 	Addr     string `json:"addr,omitempty" valid:"required"  toml:"addr,omitempty" label:"mqc服务地址"`
 	Trace    bool   `json:"trace,omitempty" toml:"trace,omitempty"`
+
+	Dedup       string `json:"dedup,omitempty" toml:"dedup,omitempty" label:"消息去重使用的缓存配置名"`
+	DedupExpire int    `json:"dedupExpire,omitempty" toml:"dedupExpire,omitempty" label:"消息编号保留时长(秒)"`
+
+	Lag *Lag `json:"lag,omitempty" toml:"lag,omitempty"`
+}
+
+//Lag 消息积压监控配置
+type Lag struct {
+	Interval  int   `json:"interval,omitempty" toml:"interval,omitempty" label:"采样间隔(秒)"`
+	Threshold int64 `json:"threshold,omitempty" toml:"threshold,omitempty" label:"积压告警阈值"`
+	Duration  int   `json:"duration,omitempty" toml:"duration,omitempty" label:"持续超过阈值的时长(秒)"`
 }
 
 //New 构建mqc server配置，默认为对等模式
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/types"
 )
 
 //Option 配置选项
@@ -16,6 +17,21 @@ func WithTrace() Option {
 	}
 }
 
+//WithDedup 根据消息编号去重,编号保存于指定的缓存中,expire为保留时长(秒),默认3600秒
+func WithDedup(cacheName string, expire ...int) Option {
+	return func(a *Server) {
+		a.Dedup = cacheName
+		a.DedupExpire = types.GetIntByIndex(expire, 0, 3600)
+	}
+}
+
+//WithLag 开启消息积压监控,每interval秒采样一次,积压数持续duration秒超过threshold时触发告警
+func WithLag(interval int, threshold int64, duration int) Option {
+	return func(a *Server) {
+		a.Lag = &Lag{Interval: interval, Threshold: threshold, Duration: duration}
+	}
+}
+
 //WithMasterSlave 设置为主备模式
 func WithMasterSlave() Option {
 	return func(a *Server) {
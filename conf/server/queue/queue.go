@@ -5,6 +5,9 @@ type Queue struct {
 	Queue       string `json:"queue,omitempty" valid:"ascii,required" toml:"queue,omitempty" label:"队列名"`
 	Service     string `json:"service,omitempty" valid:"ascii,spath,required" toml:"service,omitempty" label:"队列服务"`
 	Concurrency int    `json:"concurrency,omitempty" toml:"concurrency,omitempty"`
+	Partitions  int    `json:"partitions,omitempty" toml:"partitions,omitempty" label:"分区数"`
+	Min         int    `json:"min,omitempty" toml:"min,omitempty" label:"最小并发数"`
+	Max         int    `json:"max,omitempty" toml:"max,omitempty" label:"最大并发数"`
 	Disable     bool   `json:"disable,omitempty" toml:"disable,omitempty"`
 }
 
@@ -20,6 +23,11 @@ func NewQueue(queue string, service string, opts ...Option) *Queue {
 	return q
 }
 
+//IsAdaptive 是否为自适应并发数
+func (q *Queue) IsAdaptive() bool {
+	return q.Max > 0 && q.Max > q.Min
+}
+
 //Option Option
 type Option func(q *Queue)
 
@@ -30,6 +38,21 @@ func WithConcurrency(concurrency int) Option {
 	}
 }
 
+//WithPartitions 分区数,相同分区键的消息顺序处理,不同分区并行处理
+func WithPartitions(partitions int) Option {
+	return func(q *Queue) {
+		q.Partitions = partitions
+	}
+}
+
+//WithAdaptive 自适应并发数,根据队列积压数与处理时长在min与max之间调整处理协程数
+func WithAdaptive(min int, max int) Option {
+	return func(q *Queue) {
+		q.Min = min
+		q.Max = max
+	}
+}
+
 //WithDisable 禁用
 func WithDisable() Option {
 	return func(q *Queue) {
@@ -38,10 +38,14 @@ func (q *Queues) Append(queues ...*Queue) (*Queues, []*Queue) {
 	notifyQueues := []*Queue{}
 	for _, v := range queues {
 		if queue, ok := keyMap[v.Queue]; ok {
-			if queue.Disable != v.Disable || queue.Concurrency != v.Concurrency {
+			if queue.Disable != v.Disable || queue.Concurrency != v.Concurrency || queue.Partitions != v.Partitions ||
+				queue.Min != v.Min || queue.Max != v.Max {
 				notifyQueues = append(notifyQueues, v)
 				queue.Disable = v.Disable
 				queue.Concurrency = v.Concurrency
+				queue.Partitions = v.Partitions
+				queue.Min = v.Min
+				queue.Max = v.Max
 			}
 			continue
 		}
@@ -133,7 +133,7 @@ func (c *ServerConf) load() (err error) {
 
 	//获取主配置
 	mainPath := c.GetServerPath()
-	conf, err := getValue(c.registry, mainPath)
+	conf, err := getValue(c.registry, mainPath, c.GetPlatName())
 	if err != nil {
 		return err
 	}
@@ -163,7 +163,7 @@ func (c *ServerConf) getSubConf(path string) (map[string]conf.RawConf, error) {
 	values := make(map[string]conf.RawConf)
 	for _, p := range confs {
 		currentPath := registry.Join(path, p)
-		value, err := getValue(c.registry, currentPath)
+		value, err := getValue(c.registry, currentPath, c.GetPlatName())
 		if err != nil {
 			return nil, err
 		}
@@ -182,7 +182,7 @@ func (c *ServerConf) getSubConf(path string) (map[string]conf.RawConf, error) {
 
 	return values, nil
 }
-func getValue(registry registry.IRegistry, path string) (*conf.RawConf, error) {
+func getValue(registry registry.IRegistry, path string, platName string) (*conf.RawConf, error) {
 
 	data, version, err := registry.GetValue(path)
 	if err != nil {
@@ -193,6 +193,10 @@ func getValue(registry registry.IRegistry, path string) (*conf.RawConf, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%s[%s]解密子配置失败:%w", path, data, err)
 	}
+	rdata, err = conf.Interpolate(rdata, conf.RegistryVarLookup(registry, platName))
+	if err != nil {
+		return nil, fmt.Errorf("%s配置有误:%w", path, err)
+	}
 	if len(rdata) == 0 {
 		rdata = []byte("{}")
 	}
@@ -3,6 +3,7 @@ package server
 import (
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/server/acl/blacklist"
+	"github.com/micro-plat/hydra/conf/server/acl/canary"
 	"github.com/micro-plat/hydra/conf/server/acl/limiter"
 	"github.com/micro-plat/hydra/conf/server/acl/proxy"
 	"github.com/micro-plat/hydra/conf/server/acl/whitelist"
@@ -32,6 +33,7 @@ type HttpSub struct {
 	blackList *Loader
 	limit     *Loader
 	proxy     *Loader
+	canary    *Loader
 	apm       *Loader
 	processor *Loader
 }
@@ -50,6 +52,7 @@ func NewHttpSub(cnf conf.IServerConf) *HttpSub {
 	s.blackList = GetLoader(cnf, s.getBlacklistFunc())
 	s.limit = GetLoader(cnf, s.getLimiterFunc())
 	s.proxy = GetLoader(cnf, s.getProxyFunc())
+	s.canary = GetLoader(cnf, s.getCanaryFunc())
 	s.apm = GetLoader(cnf, s.getAPMFunc())
 	s.processor = GetLoader(cnf, s.getProcessorFunc())
 	return s
@@ -139,6 +142,13 @@ func (s HttpSub) getProxyFunc() func(cnf conf.IServerConf) (interface{}, error)
 	}
 }
 
+//getCanaryFunc 获取canary配置信息
+func (s HttpSub) getCanaryFunc() func(cnf conf.IServerConf) (interface{}, error) {
+	return func(cnf conf.IServerConf) (interface{}, error) {
+		return canary.GetConf(cnf)
+	}
+}
+
 //getGrayFunc 获取gray配置信息
 func (s HttpSub) getAPMFunc() func(cnf conf.IServerConf) (interface{}, error) {
 	return func(cnf conf.IServerConf) (interface{}, error) {
@@ -266,6 +276,15 @@ func (s *HttpSub) GetProxyConf() (*proxy.Proxy, error) {
 	return proxyObj.(*proxy.Proxy), nil
 }
 
+//GetCanaryConf 获取灰度路由配置
+func (s *HttpSub) GetCanaryConf() (*canary.Canary, error) {
+	canaryObj, err := s.canary.GetConf()
+	if err != nil {
+		return nil, err
+	}
+	return canaryObj.(*canary.Canary), nil
+}
+
 //GetAPMConf 获取APM配置
 func (s *HttpSub) GetAPMConf() (*apm.APM, error) {
 	apmc, err := s.apm.GetConf()
@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/micro-plat/hydra/conf/history"
+	varpub "github.com/micro-plat/hydra/conf/vars"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//变更类型
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+//Change 节点变更
+type Change struct {
+	Action string
+	Path   string
+	From   string
+	To     string
+}
+
+//Plan 比较配置文档与注册中心的配置,获取需执行的变更,prune为true时删除文档中不存在的节点,
+//平台变量为多个系统共用,只删除文档中包含的变量类型下的节点
+func Plan(r registry.IRegistry, d *Document, plat string, system string, cluster string, prune bool) ([]*Change, error) {
+	nodes, err := d.Nodes(plat, system, cluster)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*Change, 0, len(nodes))
+	for _, path := range sortedKeys(nodes) {
+		to := nodes[path]
+		ok, err := r.Exists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			changes = append(changes, &Change{Action: Create, Path: path, To: to})
+			continue
+		}
+		data, _, err := r.GetValue(path)
+		if err != nil {
+			return nil, err
+		}
+		if normalize(string(data)) != normalize(to) {
+			changes = append(changes, &Change{Action: Update, Path: path, From: string(data), To: to})
+		}
+	}
+	if !prune {
+		return changes, nil
+	}
+
+	//查找文档中不存在的节点
+	types := make([]string, 0, len(d.Servers))
+	for tp := range d.Servers {
+		types = append(types, tp)
+	}
+	current, err := Export(r, plat, system, types, cluster)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := current.Nodes(plat, system, cluster)
+	if err != nil {
+		return nil, err
+	}
+	varRoot := varpub.NewVarPub(plat).GetVarPath()
+	for _, path := range sortedKeys(exists) {
+		if _, ok := nodes[path]; ok {
+			continue
+		}
+		if tp, ok := varType(varRoot, path); ok {
+			if _, ok := d.Vars[tp]; !ok {
+				continue
+			}
+		}
+		changes = append(changes, &Change{Action: Delete, Path: path, From: exists[path]})
+	}
+	return changes, nil
+}
+
+//varType 获取变量节点的变量类型,不是变量节点时返回false
+func varType(root string, path string) (string, bool) {
+	if !strings.HasPrefix(path, root+"/") {
+		return "", false
+	}
+	return strings.SplitN(strings.TrimPrefix(path, root+"/"), "/", 2)[0], true
+}
+
+//Apply 执行变更,修改或删除前归档节点的当前值
+func Apply(r registry.IRegistry, changes []*Change) error {
+	for _, c := range changes {
+		if c.Action != Create {
+			if err := history.Archive(r, c.Path); err != nil {
+				return err
+			}
+		}
+		var err error
+		switch c.Action {
+		case Create:
+			err = r.CreatePersistentNode(c.Path, c.To)
+		case Update:
+			err = r.Update(c.Path, c.To)
+		case Delete:
+			err = r.Delete(c.Path)
+		default:
+			err = fmt.Errorf("不支持的变更类型:%s", c.Action)
+		}
+		if err != nil {
+			return fmt.Errorf("%s配置%s失败:%w", c.Action, c.Path, err)
+		}
+	}
+	return nil
+}
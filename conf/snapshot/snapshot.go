@@ -0,0 +1,302 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/micro-plat/hydra/conf/server"
+	varpub "github.com/micro-plat/hydra/conf/vars"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//MainNode 服务器主配置的节点名
+const MainNode = "main"
+
+//支持的文档格式
+const (
+	TOML = "toml"
+	JSON = "json"
+)
+
+//Document 平台、系统、集群的配置文档,包含服务器主配置、子配置及变量配置
+type Document struct {
+	Plat    string                            `json:"plat,omitempty" toml:"plat,omitempty"`
+	System  string                            `json:"system,omitempty" toml:"system,omitempty"`
+	Cluster string                            `json:"cluster,omitempty" toml:"cluster,omitempty"`
+	Servers map[string]map[string]interface{} `json:"servers,omitempty" toml:"servers,omitempty"`
+	Vars    map[string]map[string]interface{} `json:"vars,omitempty" toml:"vars,omitempty"`
+}
+
+//Export 从注册中心导出配置文档
+func Export(r registry.IRegistry, plat string, system string, types []string, cluster string) (*Document, error) {
+	doc := &Document{
+		Plat:    plat,
+		System:  system,
+		Cluster: cluster,
+		Servers: make(map[string]map[string]interface{}),
+		Vars:    make(map[string]map[string]interface{}),
+	}
+	for _, tp := range types {
+		root := server.NewServerPub(plat, system, tp, cluster).GetServerPath()
+		nodes := make(map[string]interface{})
+		if err := readNodes(r, root, MainNode, nodes); err != nil {
+			return nil, err
+		}
+		if len(nodes) > 0 {
+			doc.Servers[tp] = nodes
+		}
+	}
+	root := varpub.NewVarPub(plat).GetVarPath()
+	if ok, err := r.Exists(root); err != nil || !ok {
+		return doc, err
+	}
+	tps, _, err := r.GetChildren(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, tp := range tps {
+		nodes := make(map[string]interface{})
+		if err := readNodes(r, registry.Join(root, tp), "", nodes); err != nil {
+			return nil, err
+		}
+		if len(nodes) > 0 {
+			doc.Vars[tp] = nodes
+		}
+	}
+	return doc, nil
+}
+
+//readNodes 读取节点及所有子节点的值,节点名为相对于根节点的路径
+func readNodes(r registry.IRegistry, path string, name string, nodes map[string]interface{}) error {
+	if ok, err := r.Exists(path); err != nil || !ok {
+		return err
+	}
+	data, _, err := r.GetValue(path)
+	if err != nil {
+		return err
+	}
+	children, _, err := r.GetChildren(path)
+	if err != nil {
+		return err
+	}
+	v := strings.TrimSpace(string(data))
+	if name != "" && v != "" && !(v == "{}" && len(children) > 0) {
+		if nodes[name], err = decodeValue(data); err != nil {
+			return fmt.Errorf("配置%s格式有误:%w", path, err)
+		}
+	}
+	for _, c := range children {
+		cname := c
+		if name != "" && name != MainNode {
+			cname = name + "/" + c
+		}
+		if err := readNodes(r, registry.Join(path, c), cname, nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Encode 将配置文档序列化为指定格式
+func (d *Document) Encode(format string) ([]byte, error) {
+	switch format {
+	case JSON:
+		return json.MarshalIndent(d, "", "  ")
+	case TOML:
+		var buff bytes.Buffer
+		if err := toml.NewEncoder(&buff).Encode(d); err != nil {
+			return nil, fmt.Errorf("配置无法转换为toml格式,请使用json格式:%w", err)
+		}
+		return buff.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("不支持的文档格式:%s", format)
+	}
+}
+
+//Decode 解析并检查指定格式的配置文档
+func Decode(data []byte, format string) (*Document, error) {
+	d, err := Parse(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return d, d.Validate()
+}
+
+//Parse 解析指定格式的配置文档,不检查文档内容,用于解析只包含部分配置的覆盖文档
+func Parse(data []byte, format string) (*Document, error) {
+	d := &Document{}
+	switch format {
+	case JSON:
+		v, err := decodeValue(data)
+		if err != nil {
+			return nil, fmt.Errorf("配置文档格式有误:%w", err)
+		}
+		buff, _ := json.Marshal(v)
+		if err := json.Unmarshal(buff, d); err != nil {
+			return nil, fmt.Errorf("配置文档格式有误:%w", err)
+		}
+	case TOML:
+		if _, err := toml.Decode(string(data), d); err != nil {
+			return nil, fmt.Errorf("配置文档格式有误:%w", err)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的文档格式:%s", format)
+	}
+	return d, nil
+}
+
+//Validate 检查配置文档
+func (d *Document) Validate() error {
+	for tp, nodes := range d.Servers {
+		if len(global.ServerTypes) > 0 && !contains(global.ServerTypes, tp) {
+			return fmt.Errorf("不支持的服务器类型:%s", tp)
+		}
+		if _, ok := nodes[MainNode]; !ok {
+			return fmt.Errorf("服务器%s未配置主配置%s", tp, MainNode)
+		}
+		for name, v := range nodes {
+			if err := checkNode(name, v); err != nil {
+				return fmt.Errorf("服务器%s的配置有误:%w", tp, err)
+			}
+		}
+	}
+	for tp, nodes := range d.Vars {
+		if tp == "" || strings.Contains(tp, "/") {
+			return fmt.Errorf("变量类型%s有误", tp)
+		}
+		for name, v := range nodes {
+			if err := checkNode(name, v); err != nil {
+				return fmt.Errorf("变量%s的配置有误:%w", tp, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkNode(name string, v interface{}) error {
+	if name == "" || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.Contains(name, "//") {
+		return fmt.Errorf("节点名称%s有误", name)
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}, string:
+		return nil
+	default:
+		return fmt.Errorf("节点%s的值必须为对象、数组或字符串", name)
+	}
+}
+
+//Nodes 获取文档中所有节点的路径及值
+func (d *Document) Nodes(plat string, system string, cluster string) (map[string]string, error) {
+	list := make(map[string]string)
+	for tp, nodes := range d.Servers {
+		pub := server.NewServerPub(plat, system, tp, cluster)
+		for name, v := range nodes {
+			path := pub.GetSubConfPath(name)
+			if name == MainNode {
+				path = pub.GetServerPath()
+			}
+			value, err := encodeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			list[path] = value
+		}
+	}
+	pub := varpub.NewVarPub(plat)
+	for tp, nodes := range d.Vars {
+		for name, v := range nodes {
+			value, err := encodeValue(v)
+			if err != nil {
+				return nil, err
+			}
+			list[pub.GetVarPath(tp, name)] = value
+		}
+	}
+	return list, nil
+}
+
+//decodeValue 解析节点值,json数值为整数时转换为int64,非json内容作为字符串处理
+func decodeValue(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		if json.Valid(data) {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return convert(v), nil
+}
+
+func convert(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		for k, i := range c {
+			if i == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = convert(i)
+		}
+	case []interface{}:
+		for n, i := range c {
+			c[n] = convert(i)
+		}
+	case json.Number:
+		if i, err := c.Int64(); err == nil {
+			return i
+		}
+		f, _ := c.Float64()
+		return f
+	}
+	return v
+}
+
+//encodeValue 将节点值序列化为注册中心保存的内容
+func encodeValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(buff), nil
+}
+
+//normalize 将节点值转换为统一格式,用于比较
+func normalize(data string) string {
+	v, err := decodeValue([]byte(data))
+	if err != nil {
+		return data
+	}
+	s, err := encodeValue(v)
+	if err != nil {
+		return data
+	}
+	return s
+}
+
+func contains(list []string, v string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/history"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestExportImport(t *testing.T) {
+	r, err := registry.GetRegistry("lm://.", global.Def.Log())
+	assert.Equal(t, nil, err, "创建注册中心")
+	r.CreatePersistentNode("/snapplat/sys/api/t/conf", `{"address":":8080","rTimeout":30,"certs":null}`)
+	r.CreatePersistentNode("/snapplat/sys/api/t/conf/acl/white.list", `{"disable":true}`)
+	r.CreatePersistentNode("/snapplat/var/db/db", `{"provider":"mysql","connString":"enc:v1:xx"}`)
+
+	doc, err := Export(r, "snapplat", "sys", []string{"api"}, "t")
+	assert.Equal(t, nil, err, "1. 导出配置")
+	assert.Equal(t, map[string]interface{}{"address": ":8080", "rTimeout": int64(30)}, doc.Servers["api"][MainNode], "1. 导出主配置")
+	assert.Equal(t, map[string]interface{}{"disable": true}, doc.Servers["api"]["acl/white.list"], "1. 导出子配置")
+	assert.Equal(t, "enc:v1:xx", doc.Vars["db"]["db"].(map[string]interface{})["connString"], "1. 加密的配置值原样导出")
+
+	for _, format := range []string{TOML, JSON} {
+		buff, err := doc.Encode(format)
+		assert.Equal(t, nil, err, "2. 序列化配置文档"+format)
+		ndoc, err := Decode(buff, format)
+		assert.Equal(t, nil, err, "2. 解析配置文档"+format)
+		changes, err := Plan(r, ndoc, "snapplat", "sys", "t", true)
+		assert.Equal(t, nil, err, "2. 无变更"+format)
+		assert.Equal(t, 0, len(changes), "2. 无变更"+format)
+	}
+
+	doc.Servers["api"][MainNode].(map[string]interface{})["address"] = ":9090"
+	delete(doc.Servers["api"], "acl/white.list")
+	changes, err := Plan(r, doc, "snapplat", "sys", "prod", false)
+	assert.Equal(t, nil, err, "3. 导入到其它集群")
+	assert.Equal(t, 1, len(changes), "3. 导入到其它集群")
+	assert.Equal(t, Create, changes[0].Action, "3. 导入到其它集群")
+
+	changes, err = Plan(r, doc, "snapplat", "sys", "t", true)
+	assert.Equal(t, nil, err, "4. 修改及删除节点")
+	assert.Equal(t, 2, len(changes), "4. 修改及删除节点")
+	assert.Equal(t, Update, changes[0].Action, "4. 修改节点")
+	assert.Equal(t, Delete, changes[1].Action, "4. 删除节点")
+	assert.Equal(t, "/snapplat/sys/api/t/conf/acl/white.list", changes[1].Path, "4. 删除节点")
+	assert.Equal(t, nil, Apply(r, changes), "4. 执行变更")
+	data, _, _ := r.GetValue("/snapplat/sys/api/t/conf")
+	assert.Equal(t, `{"address":":9090","rTimeout":30}`, string(data), "4. 执行变更")
+	ok, _ := r.Exists("/snapplat/sys/api/t/conf/acl/white.list")
+	assert.Equal(t, false, ok, "4. 执行变更")
+	versions, _ := history.List(r, "/snapplat/sys/api/t/conf")
+	assert.Equal(t, 1, len(versions), "4. 修改前归档")
+
+	//其它系统共用的变量类型不删除
+	r.CreatePersistentNode("/snapplat/var/redis/cache", `{"addrs":["127.0.0.1:6379"]}`)
+	r.CreatePersistentNode("/snapplat/var/db/other", `{"provider":"mysql"}`)
+	changes, err = Plan(r, doc, "snapplat", "sys", "t", true)
+	assert.Equal(t, nil, err, "5. 删除变量节点")
+	deletes := make(map[string]bool)
+	for _, c := range changes {
+		deletes[c.Path] = c.Action == Delete
+	}
+	assert.Equal(t, false, deletes["/snapplat/var/redis/cache"], "5. 文档中不包含的变量类型不删除")
+	assert.Equal(t, true, deletes["/snapplat/var/db/other"], "5. 删除文档中包含的变量类型下的节点")
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		wantErr bool
+	}{
+		{name: "1. toml文档", data: "[servers.api.main]\naddress = \":8080\"\n[vars.db.db]\nprovider = \"mysql\"", format: TOML},
+		{name: "2. json文档", data: `{"servers":{"api":{"main":{"address":":8080"},"router":[{"path":"/a"}]}}}`, format: JSON},
+		{name: "3. 缺少主配置", data: `{"servers":{"api":{"router":{}}}}`, format: JSON, wantErr: true},
+		{name: "4. 节点值类型有误", data: `{"vars":{"db":{"db":1}}}`, format: JSON, wantErr: true},
+		{name: "5. 节点名称有误", data: `{"vars":{"db":{"/db":{}}}}`, format: JSON, wantErr: true},
+		{name: "6. 文档格式有误", data: `{"vars"`, format: JSON, wantErr: true},
+		{name: "7. 不支持的格式", data: `{}`, format: "yaml", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := Decode([]byte(tt.data), tt.format)
+		assert.Equal(t, tt.wantErr, err != nil, tt.name, err)
+	}
+}
@@ -3,6 +3,8 @@ package cacheredis
 import (
 	"encoding/json"
 	"fmt"
+
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
 )
 
 //Option 配置选项
@@ -45,6 +47,21 @@ func WithPoolSize(i int) Option {
 	}
 }
 
+//WithSentinel 使用哨兵模式,Addrs为哨兵地址
+func WithSentinel(masterName string) Option {
+	return func(a *Redis) {
+		a.MasterName = masterName
+		a.Mode = varredis.ModeSentinel
+	}
+}
+
+//WithCluster 使用集群模式
+func WithCluster() Option {
+	return func(a *Redis) {
+		a.Mode = varredis.ModeCluster
+	}
+}
+
 //WithRaw 通过json原串初始化
 func WithRaw(raw string) Option {
 	return func(o *Redis) {
@@ -24,6 +24,8 @@ type Redis struct {
 	ReadTimeout  int      `json:"read_timeout,omitempty" toml:"read_timeout,omitempty"`
 	WriteTimeout int      `json:"write_timeout,omitempty" toml:"write_timeout,omitempty"`
 	PoolSize     int      `json:"pool_size,omitempty" toml:"pool_size,omitempty"`
+	MasterName   string   `json:"master_name,omitempty" toml:"master_name,omitempty" label:"哨兵主节点名称"`
+	Mode         string   `json:"mode,omitempty" toml:"mode,omitempty" valid:"in(single|sentinel|cluster)" label:"部署模式"`
 
 	ConfigName string `json:"config_name,omitempty" toml:"config_name,omitempty" valid:"ascii"`
 }
@@ -0,0 +1,39 @@
+package tiered
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//Option 配置选项
+type Option func(*Tiered)
+
+//WithRedis 设置发送失效通知的redis(/var/redis下的名称),二级缓存为redis时可不设置
+func WithRedis(name string) Option {
+	return func(o *Tiered) {
+		o.Redis = name
+	}
+}
+
+//WithChannel 设置失效通知的发布订阅通道
+func WithChannel(channel string) Option {
+	return func(o *Tiered) {
+		o.Channel = channel
+	}
+}
+
+//WithExpiration 设置本地缓存过期时间(秒)
+func WithExpiration(expiration int) Option {
+	return func(o *Tiered) {
+		o.Expiration = expiration
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *Tiered) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(fmt.Errorf("tiered.WithRaw:%w", err))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package tiered
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/micro-plat/hydra/conf"
+	"github.com/micro-plat/hydra/conf/vars/cache"
+)
+
+//Proto 二级缓存类型
+const Proto = "tiered"
+
+//Tiered 二级缓存配置,本地内存作为一级缓存,L2指定的缓存作为二级缓存
+type Tiered struct {
+	*cache.Cache
+	L2         string `json:"l2" toml:"l2" valid:"ascii,required" label:"二级缓存名称"`
+	Redis      string `json:"redis,omitempty" toml:"redis,omitempty" valid:"ascii" label:"失效通知redis名称"`
+	Channel    string `json:"channel,omitempty" toml:"channel,omitempty" label:"失效通知通道"`
+	Expiration int    `json:"expiration,omitempty" toml:"expiration,omitempty" label:"本地缓存过期时间(秒)"`
+}
+
+//New 构建二级缓存配置,l2为/var/cache下的缓存名称
+func New(l2 string, opts ...Option) *Tiered {
+	t := &Tiered{
+		Cache:      &cache.Cache{Proto: Proto},
+		L2:         l2,
+		Channel:    "hydra:cache:invalidate",
+		Expiration: 60,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if b, err := govalidator.ValidateStruct(t); !b {
+		panic(fmt.Errorf("tiered配置数据有误:%v %+v", err, t))
+	}
+	return t
+}
+
+//NewByRaw 通过json原串初始化
+func NewByRaw(raw string) *Tiered {
+	return New("", WithRaw(raw))
+}
+
+//GetL2Conf 获取二级缓存配置
+func (t *Tiered) GetL2Conf(varConf conf.IVarConf) (*conf.RawConf, error) {
+	js, err := varConf.GetConf(cache.TypeNodeName, t.L2)
+	if errors.Is(err, conf.ErrNoSetting) {
+		return nil, fmt.Errorf("未配置：/var/%s/%s", cache.TypeNodeName, t.L2)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if js.GetString("proto") == Proto {
+		return nil, fmt.Errorf("二级缓存%s不能为%s类型", t.L2, Proto)
+	}
+	return js, nil
+}
+
+//GetRaw 获取配置数据(json)
+func (t *Tiered) GetRaw() string {
+	bytes, _ := json.Marshal(t)
+	return string(bytes)
+}
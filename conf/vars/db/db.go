@@ -5,11 +5,16 @@ const TypeNodeName = "db"
 
 //DB 数据库配置
 type DB struct {
-	Provider   string `json:"provider" valid:"required"`
-	ConnString string `json:"connString" valid:"required" label:"连接字符串"`
-	MaxOpen    int    `json:"maxOpen" valid:"required" label:"最大打开连接数"`
-	MaxIdle    int    `json:"maxIdle" valid:"required" label:"最大空闲连接数"`
-	LifeTime   int    `json:"lifeTime" valid:"required" label:"单个连接时长(秒)"`
+	Provider      string   `json:"provider" valid:"required"`
+	ConnString    string   `json:"connString" valid:"required" label:"连接字符串"`
+	MaxOpen       int      `json:"maxOpen" valid:"required" label:"最大打开连接数"`
+	MaxIdle       int      `json:"maxIdle" valid:"required" label:"最大空闲连接数"`
+	LifeTime      int      `json:"lifeTime" valid:"required" label:"单个连接时长(秒)"`
+	Replicas      []string `json:"replicas,omitempty" label:"只读库连接字符串"`
+	MaxLag        int      `json:"maxLag,omitempty" label:"只读库最大延迟(秒)"`
+	LagSQL        string   `json:"lagSQL,omitempty" label:"只读库延迟查询语句"`
+	SlowThreshold int      `json:"slowThreshold,omitempty" label:"慢查询阈值(毫秒)"`
+	Masks         []string `json:"masks,omitempty" label:"日志中需脱敏的参数名"`
 }
 
 //New 构建DB连接信息
@@ -11,3 +11,32 @@ func WithConnect(maxOpen int, maxIdle int, lifeTime int) Option {
 		a.LifeTime = lifeTime
 	}
 }
+
+//WithReplicas 设置只读库连接字符串,查询语句在可用的只读库间轮询执行,写入及事务使用主库
+func WithReplicas(connStrings ...string) Option {
+	return func(a *DB) {
+		a.Replicas = connStrings
+	}
+}
+
+//WithMaxLag 设置只读库最大延迟(秒)及查询延迟秒数的语句,延迟超过maxLag的只读库暂停使用
+func WithMaxLag(maxLag int, lagSQL string) Option {
+	return func(a *DB) {
+		a.MaxLag = maxLag
+		a.LagSQL = lagSQL
+	}
+}
+
+//WithSlowThreshold 设置慢查询阈值(毫秒),执行时间超过阈值的语句记录为慢查询
+func WithSlowThreshold(ms int) Option {
+	return func(a *DB) {
+		a.SlowThreshold = ms
+	}
+}
+
+//WithMasks 设置日志及链路跟踪中需脱敏的参数名,参数名包含其中任一项时不记录参数值
+func WithMasks(names ...string) Option {
+	return func(a *DB) {
+		a.Masks = names
+	}
+}
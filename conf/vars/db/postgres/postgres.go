@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/micro-plat/hydra/conf/vars/db"
+)
+
+//Provider 数据库类型
+const Provider = "postgres"
+
+//Postgres postgresql数据库连接信息
+type Postgres = db.DB
+
+//New 构建postgresql连接信息
+func New(connString string, opts ...db.Option) *Postgres {
+	return db.New(Provider, connString, opts...)
+}
+
+//NewBy 构建postgresql连接信息,serverIP可包含端口号,如:192.168.0.1:5432
+func NewBy(uName string, pwd string, serverIP string, dbName string, opts ...db.Option) *Postgres {
+	u := &url.URL{
+		Scheme:   Provider,
+		User:     url.UserPassword(uName, pwd),
+		Host:     serverIP,
+		Path:     dbName,
+		RawQuery: "sslmode=disable",
+	}
+	return New(fmt.Sprint(u), opts...)
+}
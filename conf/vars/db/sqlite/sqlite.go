@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/micro-plat/hydra/conf/vars/db"
+)
+
+//Provider 数据库类型
+const Provider = "sqlite3"
+
+//SQLite sqlite数据库连接信息
+type SQLite = db.DB
+
+//New 构建sqlite连接信息
+func New(connString string, opts ...db.Option) *SQLite {
+	return db.New(Provider, connString, opts...)
+}
+
+//NewBy 根据数据库文件构建sqlite连接信息,file为":memory:"时使用内存数据库
+func NewBy(file string, opts ...db.Option) *SQLite {
+	return New(fmt.Sprintf("file:%s?cache=shared&_busy_timeout=5000", file), opts...)
+}
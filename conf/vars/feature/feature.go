@@ -0,0 +1,94 @@
+package feature
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/asaskevich/govalidator"
+)
+
+//TypeNodeName 分类节点名
+const TypeNodeName = "feature"
+
+//Flag 功能开关。开启后依次检查集群、服务器类型,白名单中的用户直接开启,其它用户按用户编号放量。
+//只对白名单开启时设置percent为0
+type Flag struct {
+	Enable      bool     `json:"enable" toml:"enable"`
+	Percent     int      `json:"percent" toml:"percent" valid:"range(0|100)" label:"放量比例"`
+	Users       []string `json:"users,omitempty" toml:"users,omitempty" label:"白名单"`
+	Clusters    []string `json:"clusters,omitempty" toml:"clusters,omitempty" label:"集群"`
+	ServerTypes []string `json:"server_types,omitempty" toml:"server_types,omitempty" label:"服务器类型"`
+	Desc        string   `json:"desc,omitempty" toml:"desc,omitempty" label:"描述"`
+	name        string
+}
+
+//Target 功能开关的判断对象
+type Target struct {
+	UserID      string
+	ClusterName string
+	ServerType  string
+}
+
+//New 构建功能开关,默认对所有用户开启
+func New(opts ...Option) *Flag {
+	f := &Flag{Enable: true, Percent: 100}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+//NewByRaw 通过json原串初始化,未配置percent时对所有用户开启
+func NewByRaw(name string, raw []byte) (*Flag, error) {
+	f := &Flag{Percent: 100}
+	if err := json.Unmarshal(raw, f); err != nil {
+		return nil, fmt.Errorf("功能开关%s配置有误:%w", name, err)
+	}
+	if b, err := govalidator.ValidateStruct(f); !b {
+		return nil, fmt.Errorf("功能开关%s配置数据有误:%v %+v", name, err, f)
+	}
+	f.name = name
+	return f, nil
+}
+
+//GetName 获取开关名称
+func (f *Flag) GetName() string {
+	return f.name
+}
+
+//IsEnabled 功能开关对目标是否开启,未指定用户时只有全量放量才开启
+func (f *Flag) IsEnabled(t *Target) bool {
+	if !f.Enable {
+		return false
+	}
+	if len(f.Clusters) > 0 && !contains(f.Clusters, t.ClusterName) {
+		return false
+	}
+	if len(f.ServerTypes) > 0 && !contains(f.ServerTypes, t.ServerType) {
+		return false
+	}
+	if t.UserID != "" && contains(f.Users, t.UserID) {
+		return true
+	}
+	if f.Percent >= 100 {
+		return true
+	}
+	if f.Percent <= 0 || t.UserID == "" {
+		return false
+	}
+
+	//按开关名称及用户编号计算放量位置,同一用户结果稳定,不同开关的放量用户不同
+	h := fnv.New32a()
+	h.Write([]byte(f.name + ":" + t.UserID))
+	return int(h.Sum32()%100) < f.Percent
+}
+
+func contains(list []string, v string) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
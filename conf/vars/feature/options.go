@@ -0,0 +1,53 @@
+package feature
+
+//Option 配置选项
+type Option func(*Flag)
+
+//WithPercent 设置按用户编号放量的比例(0-100)
+func WithPercent(percent int) Option {
+	return func(a *Flag) {
+		a.Percent = percent
+	}
+}
+
+//WithUsers 设置白名单,白名单中的用户直接开启
+func WithUsers(users ...string) Option {
+	return func(a *Flag) {
+		a.Users = append(a.Users, users...)
+	}
+}
+
+//WithClusters 只对指定集群开启
+func WithClusters(clusters ...string) Option {
+	return func(a *Flag) {
+		a.Clusters = append(a.Clusters, clusters...)
+	}
+}
+
+//WithServerTypes 只对指定服务器类型开启
+func WithServerTypes(types ...string) Option {
+	return func(a *Flag) {
+		a.ServerTypes = append(a.ServerTypes, types...)
+	}
+}
+
+//WithDesc 设置开关描述
+func WithDesc(desc string) Option {
+	return func(a *Flag) {
+		a.Desc = desc
+	}
+}
+
+//WithDisable 关闭
+func WithDisable() Option {
+	return func(a *Flag) {
+		a.Enable = false
+	}
+}
+
+//WithEnable 开启
+func WithEnable() Option {
+	return func(a *Flag) {
+		a.Enable = true
+	}
+}
@@ -0,0 +1,58 @@
+package amqp
+
+import "encoding/json"
+
+//Option 配置选项
+type Option func(*AMQP)
+
+//WithExchange 设置交换机名称及类型(direct,fanout,topic,headers)
+func WithExchange(name string, kind string) Option {
+	return func(a *AMQP) {
+		a.Exchange = name
+		a.ExchangeType = kind
+	}
+}
+
+//WithRoutingKey 设置路由键
+func WithRoutingKey(key string) Option {
+	return func(a *AMQP) {
+		a.RoutingKey = key
+	}
+}
+
+//WithDurable 设置是否持久化队列与交换机
+func WithDurable(durable bool) Option {
+	return func(a *AMQP) {
+		a.Durable = durable
+	}
+}
+
+//WithPrefetch 设置预取消息数
+func WithPrefetch(prefetch int) Option {
+	return func(a *AMQP) {
+		a.Prefetch = prefetch
+	}
+}
+
+//WithRequeue 设置处理失败的消息重新入队
+func WithRequeue() Option {
+	return func(a *AMQP) {
+		a.Requeue = true
+	}
+}
+
+//WithDialTimeout 设置连接超时时长(秒)
+func WithDialTimeout(timeout int) Option {
+	return func(a *AMQP) {
+		a.DialTimeout = timeout
+	}
+}
+
+//WithRaw 通过json原串初始化
+func WithRaw(raw string) Option {
+	return func(o *AMQP) {
+		if err := json.Unmarshal([]byte(raw), o); err != nil {
+			panic(err)
+		}
+	}
+}
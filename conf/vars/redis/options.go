@@ -35,6 +35,21 @@ func WithPoolSize(i int) Option {
 	}
 }
 
+//WithSentinel 使用哨兵模式,Addrs为哨兵地址
+func WithSentinel(masterName string) Option {
+	return func(a *Redis) {
+		a.MasterName = masterName
+		a.Mode = ModeSentinel
+	}
+}
+
+//WithCluster 使用集群模式
+func WithCluster() Option {
+	return func(a *Redis) {
+		a.Mode = ModeCluster
+	}
+}
+
 //WithRaw 通过json原串初始化
 func WithRaw(raw string) Option {
 	return func(o *Redis) {
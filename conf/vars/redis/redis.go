@@ -13,6 +13,18 @@ import (
 //TypeNodeName 分类节点名
 const TypeNodeName = "redis"
 
+//部署模式
+const (
+	//ModeSingle 单机
+	ModeSingle = "single"
+
+	//ModeSentinel 哨兵,Addrs为哨兵地址
+	ModeSentinel = "sentinel"
+
+	//ModeCluster 集群
+	ModeCluster = "cluster"
+)
+
 //Redis redis缓存配置
 type Redis struct {
 	Addrs        []string `json:"addrs,omitempty" toml:"addrs,omitempty" valid:"required" label:"集群地址(|分割)"`
@@ -22,6 +34,8 @@ type Redis struct {
 	ReadTimeout  int      `json:"read_timeout,omitempty" toml:"read_timeout,omitempty"`
 	WriteTimeout int      `json:"write_timeout,omitempty" toml:"write_timeout,omitempty"`
 	PoolSize     int      `json:"pool_size,omitempty" toml:"pool_size,omitempty"`
+	MasterName   string   `json:"master_name,omitempty" toml:"master_name,omitempty" label:"哨兵主节点名称"`
+	Mode         string   `json:"mode,omitempty" toml:"mode,omitempty" valid:"in(single|sentinel|cluster)" label:"部署模式"`
 }
 
 //New 构建redis消息队列配置
@@ -48,10 +62,25 @@ func NewByRaw(raw string) *Redis {
 	if b, err := govalidator.ValidateStruct(org); !b {
 		panic(fmt.Errorf("redis配置数据有误:%v %+v", err, org))
 	}
-
+	if org.GetMode() == ModeSentinel && org.MasterName == "" {
+		panic(fmt.Errorf("redis配置数据有误:哨兵模式必须指定master_name %+v", org))
+	}
 	return org
 }
 
+//GetMode 获取部署模式,未指定时配置了master_name为哨兵模式,多个地址为集群模式,否则为单机模式
+func (r *Redis) GetMode() string {
+	switch {
+	case r.Mode != "":
+		return r.Mode
+	case r.MasterName != "":
+		return ModeSentinel
+	case len(r.Addrs) > 1:
+		return ModeCluster
+	}
+	return ModeSingle
+}
+
 //GetConf GetConf
 func GetConf(varConf conf.IVarConf, name string) (redis *Redis, err error) {
 	js, err := varConf.GetConf("redis", name)
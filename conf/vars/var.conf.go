@@ -25,6 +25,7 @@ type cacheObj struct {
 //VarConf 变量信息
 type VarConf struct {
 	conf.IVarPub
+	platName     string
 	varConfPath  string
 	varVersion   int32
 	varNodeConfs map[string]conf.RawConf
@@ -35,6 +36,7 @@ type VarConf struct {
 func NewVarConf(platName string, rgst registry.IRegistry) (s *VarConf, err error) {
 	s = &VarConf{
 		IVarPub:      NewVarPub(platName),
+		platName:     platName,
 		registry:     rgst,
 		varNodeConfs: make(map[string]conf.RawConf),
 	}
@@ -79,6 +81,10 @@ func (c *VarConf) load() (err error) {
 			if err != nil {
 				return err
 			}
+			rdata, err = conf.Interpolate(rdata, conf.RegistryVarLookup(c.registry, c.platName))
+			if err != nil {
+				return fmt.Errorf("%s配置有误:%w", nodePath, err)
+			}
 			varConf, err := conf.NewByText(rdata, version)
 			if err != nil {
 				err = fmt.Errorf("%s配置有误:%v", nodePath, err)
@@ -19,6 +19,8 @@ const (
 
 	XRequestID = "X-Request-Id"
 
+	XPartitionKey = "X-Partition-Key"
+
 	JSONF  = "application/json; charset=%s"
 	XMLF   = "application/xml; charset=%s"
 	YAMLF  = "text/yaml; charset=%s"
@@ -319,10 +321,19 @@ type IContext interface {
 	//Invoke 调用本地服务
 	Invoke(service string) *pkgs.Rspns
 
+	//Flags 功能开关
+	Flags() IFlags
+
 	//Close 关闭并释放资源
 	Close()
 }
 
+//IFlags 功能开关
+type IFlags interface {
+	//Enabled 功能开关对当前服务器及用户是否开启,未指定用户编号时使用当前用户名
+	Enabled(name string, userID ...string) bool
+}
+
 //ITracer 链路跟踪器
 type ITracer interface {
 	ITraceSpan
@@ -343,6 +354,9 @@ type ITraceSpan interface {
 
 	//NewSpan 新的时间片
 	NewSpan(opertor string) ITraceSpan
+
+	//Tag 设置标签
+	Tag(key string, value string)
 }
 
 //IEnd 关闭
@@ -118,6 +118,11 @@ func (c *Ctx) Invoke(service string) *pkgs.Rspns {
 	return pkgs.NewRspns(fmt.Errorf("不支持服务类型%s(%s)", proto, service))
 }
 
+//Flags 功能开关
+func (c *Ctx) Flags() context.IFlags {
+	return &featureFlags{user: c.user, appConf: c.appConf, log: c.log}
+}
+
 //Close 关闭并释放所有资源
 func (c *Ctx) Close() {
 	context.Del() //从当前请求上下文中删除
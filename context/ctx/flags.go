@@ -0,0 +1,35 @@
+package ctx
+
+import (
+	"github.com/micro-plat/hydra/components/flags"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/vars/feature"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+var _ context.IFlags = &featureFlags{}
+
+type featureFlags struct {
+	user    context.IUser
+	appConf app.IAPPConf
+	log     logger.ILogger
+}
+
+//Enabled 功能开关对当前服务器及用户是否开启,未指定用户编号时使用当前用户名
+func (f *featureFlags) Enabled(name string, userID ...string) bool {
+	store, err := flags.Get()
+	if err != nil {
+		f.log.Errorf("获取功能开关失败:%v", err)
+		return false
+	}
+	target := &feature.Target{
+		UserID:      f.user.GetUserName(),
+		ClusterName: f.appConf.GetServerConf().GetClusterName(),
+		ServerType:  f.appConf.GetServerConf().GetServerType(),
+	}
+	if len(userID) > 0 {
+		target.UserID = userID[0]
+	}
+	return store.IsEnabled(name, target)
+}
@@ -52,6 +52,13 @@ func (s *Span) NewSpan(operator string) context.ITraceSpan {
 	return sub
 }
 
+//Tag 设置标签,需在Start之后调用
+func (s *Span) Tag(key string, value string) {
+	if s.span != nil {
+		s.span.Tag(go2sky.Tag(key), value)
+	}
+}
+
 //Available 是否可用
 func (s *Span) Available() bool {
 	return s.avaliable
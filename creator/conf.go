@@ -63,8 +63,14 @@ type IConf interface {
 	//Pub 发布服务
 	Pub(platName string, systemName string, clusterName string, registryAddr string, cover bool) error
 
+	//Check 检查配置
+	Check(platName string, systemName string, clusterName string) error
+
 	//Load 加载所有配置
 	Load() error
+
+	//Overlay 合并环境的覆盖配置
+	Overlay(env string) error
 }
 
 //ServerMainNodeName 服务主节点名称
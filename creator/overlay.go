@@ -0,0 +1,147 @@
+package creator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/micro-plat/hydra/conf/snapshot"
+)
+
+//OverlayDir 环境覆盖配置文件所在目录,文件名为"环境名.toml"或"环境名.json"
+var OverlayDir = "./overlays"
+
+//Overlay 读取环境的覆盖配置文件并合并到当前配置,env为空或覆盖配置文件不存在时不处理
+func (c *conf) Overlay(env string) error {
+	if env == "" {
+		return nil
+	}
+	for _, format := range []string{snapshot.TOML, snapshot.JSON} {
+		path := filepath.Join(OverlayDir, env+"."+format)
+		buff, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("读取环境%s的覆盖配置%s失败:%w", env, path, err)
+		}
+		doc, err := snapshot.Parse(buff, format)
+		if err != nil {
+			return fmt.Errorf("环境%s的覆盖配置%s有误:%w", env, path, err)
+		}
+		return c.Merge(doc)
+	}
+	return nil
+}
+
+//Merge 将覆盖配置合并到当前配置,对象逐级合并,数组及其它值直接替换,当前配置中不存在的节点直接添加
+func (c *conf) Merge(doc *snapshot.Document) error {
+	for tp, nodes := range doc.Servers {
+		builder, ok := c.data[tp]
+		if !ok {
+			return fmt.Errorf("覆盖配置中的服务器%s未配置", tp)
+		}
+		subs := builder.Map()
+		for name, v := range nodes {
+			value, err := merge(subs[name], v)
+			if err != nil {
+				return fmt.Errorf("合并服务器%s的配置%s失败:%w", tp, name, err)
+			}
+			subs[name] = value
+		}
+	}
+	for tp, nodes := range doc.Vars {
+		if _, ok := c.vars[tp]; !ok {
+			c.vars[tp] = make(map[string]interface{})
+		}
+		for name, v := range nodes {
+			value, err := merge(c.vars[tp][name], v)
+			if err != nil {
+				return fmt.Errorf("合并变量%s/%s失败:%w", tp, name, err)
+			}
+			c.vars[tp][name] = value
+		}
+	}
+	return nil
+}
+
+//merge 合并配置值,结构体配置合并后保持原类型,以便发布时继续处理需安装时输入的配置项
+func merge(base interface{}, overlay interface{}) (interface{}, error) {
+	src, ok := overlay.(map[string]interface{})
+	if !ok || base == nil {
+		return overlay, nil
+	}
+	if _, ok := base.(string); ok {
+		return overlay, nil
+	}
+	t := reflect.TypeOf(base)
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		dst, err := toMap(base)
+		if err != nil {
+			return nil, err
+		}
+		if dst == nil {
+			return overlay, nil
+		}
+		return mergeMap(dst, src), nil
+	}
+
+	//复制结构体后将覆盖内容反序列化到副本,json反序列化对嵌套结构体及map逐级赋值
+	buff, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+	v := reflect.New(st)
+	if t.Kind() == reflect.Ptr {
+		if !reflect.ValueOf(base).IsNil() {
+			v.Elem().Set(reflect.ValueOf(base).Elem())
+		}
+	} else {
+		v.Elem().Set(reflect.ValueOf(base))
+	}
+	if err := json.Unmarshal(buff, v.Interface()); err != nil {
+		return nil, err
+	}
+	if t.Kind() == reflect.Ptr {
+		return v.Interface(), nil
+	}
+	return v.Elem().Interface(), nil
+}
+
+//mergeMap 逐级合并map
+func mergeMap(dst map[string]interface{}, src map[string]interface{}) map[string]interface{} {
+	for k, v := range src {
+		s, sok := v.(map[string]interface{})
+		d, dok := dst[k].(map[string]interface{})
+		if sok && dok {
+			dst[k] = mergeMap(d, s)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+//toMap 将配置值转换为map,值不是json对象时返回nil
+func toMap(v interface{}) (map[string]interface{}, error) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(buff))
+	decoder.UseNumber()
+	var m interface{}
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	r, _ := m.(map[string]interface{})
+	return r, nil
+}
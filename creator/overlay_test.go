@@ -0,0 +1,90 @@
+package creator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro-plat/hydra/conf/server/api"
+	"github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func Test_conf_Overlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "overlay")
+	assert.Equal(t, nil, err, "创建临时目录")
+	old := OverlayDir
+	OverlayDir = dir
+	defer func() { OverlayDir = old }()
+
+	ioutil.WriteFile(filepath.Join(dir, "prod.toml"), []byte(`
+[servers.api.main]
+address = ":9090"
+[servers.api.header]
+"Access-Control-Allow-Origin" = "*"
+[vars.redis.redis]
+addrs = ["192.168.0.2:6379"]
+[vars.db.db]
+provider = "mysql"
+`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "test.json"), []byte(`{"servers":{"api":{"main":{"status":"stop"}}}}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "err.json"), []byte(`{"servers":{"rpc":{"main":{}}}}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "type.json"), []byte(`{"servers":{"api":{"main":{"address":9090}}}}`), 0644)
+
+	newConf := func() *conf {
+		c := New()
+		c.API(":8080", api.WithTimeout(10, 10))
+		c.Vars().Redis("redis", "192.168.0.1:6379", redis.WithDbIndex(1))
+		return c
+	}
+
+	c := newConf()
+	assert.Equal(t, nil, c.Overlay(""), "1. 未指定环境")
+	assert.Equal(t, ":8080", c.GetAPI().Map()[ServerMainNodeName].(*api.Server).Address, "1. 未指定环境")
+
+	c = newConf()
+	assert.Equal(t, nil, c.Overlay("prod"), "2. 合并toml覆盖配置")
+	main := c.GetAPI().Map()[ServerMainNodeName].(*api.Server)
+	assert.Equal(t, ":9090", main.Address, "2. 合并toml覆盖配置,覆盖主配置的值")
+	assert.Equal(t, 10, main.RTimeout, "2. 合并toml覆盖配置,保留未覆盖的值")
+	assert.Equal(t, map[string]interface{}{"Access-Control-Allow-Origin": "*"}, c.GetAPI().Map()["header"], "2. 合并toml覆盖配置,添加子配置")
+	rds, _ := c.GetVar(redis.TypeNodeName, "redis")
+	assert.Equal(t, []string{"192.168.0.2:6379"}, rds.(*redis.Redis).Addrs, "2. 合并toml覆盖配置,覆盖数组")
+	assert.Equal(t, 1, rds.(*redis.Redis).DbIndex, "2. 合并toml覆盖配置,保留变量未覆盖的值")
+	db, _ := c.GetVar("db", "db")
+	assert.Equal(t, map[string]interface{}{"provider": "mysql"}, db, "2. 合并toml覆盖配置,添加变量")
+
+	c = newConf()
+	assert.Equal(t, nil, c.Overlay("test"), "3. 合并json覆盖配置")
+	assert.Equal(t, "stop", c.GetAPI().Map()[ServerMainNodeName].(*api.Server).Status, "3. 合并json覆盖配置")
+	assert.Equal(t, ":8080", c.GetAPI().Map()[ServerMainNodeName].(*api.Server).Address, "3. 合并json覆盖配置")
+
+	c = newConf()
+	assert.Equal(t, nil, c.Overlay("dev"), "4. 覆盖配置文件不存在时不覆盖")
+	assert.Equal(t, "覆盖配置中的服务器rpc未配置", fmt.Sprint(c.Overlay("err")), "5. 覆盖配置中的服务器未配置")
+	assert.Equal(t, true, c.Overlay("type") != nil, "6. 覆盖配置的类型与原配置不一致")
+}
+
+func Test_merge(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    interface{}
+		overlay interface{}
+		want    interface{}
+	}{
+		{name: "1. 原配置不存在", base: nil, overlay: map[string]interface{}{"a": "1"}, want: map[string]interface{}{"a": "1"}},
+		{name: "2. 原配置为字符串", base: "abc", overlay: map[string]interface{}{"a": "1"}, want: map[string]interface{}{"a": "1"}},
+		{name: "3. 覆盖配置为字符串", base: map[string]interface{}{"a": "1"}, overlay: "abc", want: "abc"},
+		{name: "4. 逐级合并map", base: map[string]interface{}{"a": "1", "b": map[string]interface{}{"c": "2", "d": []interface{}{"3"}}},
+			overlay: map[string]interface{}{"b": map[string]interface{}{"c": "4", "d": []interface{}{"5"}}},
+			want:    map[string]interface{}{"a": "1", "b": map[string]interface{}{"c": "4", "d": []interface{}{"5"}}}},
+		{name: "5. 结构体值保持类型", base: api.Server{Address: ":8080", Status: "start"}, overlay: map[string]interface{}{"status": "stop"},
+			want: api.Server{Address: ":8080", Status: "stop"}},
+	}
+	for _, tt := range tests {
+		got, err := merge(tt.base, tt.overlay)
+		assert.Equal(t, nil, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+}
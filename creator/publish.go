@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/micro-plat/hydra/conf/check"
+	"github.com/micro-plat/hydra/conf/history"
 	"github.com/micro-plat/hydra/conf/server"
 	varpub "github.com/micro-plat/hydra/conf/vars"
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers"
 	"github.com/micro-plat/hydra/registry"
 )
 
@@ -17,6 +20,16 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 		return err
 	}
 
+	//合并当前环境的覆盖配置
+	if err := c.Overlay(global.Def.GetEnv()); err != nil {
+		return err
+	}
+
+	//发布前检查配置
+	if err := c.Check(platName, systemName, clusterName); err != nil {
+		return err
+	}
+
 	//创建注册中心，根据注册中心提供的接口进行配置发布
 	r, err := registry.GetRegistry(registryAddr, global.Def.Log())
 	if err != nil {
@@ -47,6 +60,43 @@ func (c *conf) Pub(platName string, systemName string, clusterName string, regis
 	return nil
 }
 
+//Check 将配置写入独立的内存注册中心,使用各子配置的解析器检查当前程序运行的服务器及变量配置,返回所有错误
+func (c *conf) Check(platName string, systemName string, clusterName string) error {
+	nodes := make(map[string]string)
+	types := make([]string, 0, len(c.data))
+	for _, tp := range servers.GetServerTypes() {
+		subs, ok := c.data[tp]
+		if !ok {
+			continue
+		}
+		types = append(types, tp)
+		pub := server.NewServerPub(platName, systemName, tp, clusterName)
+		for name, v := range subs.Map() {
+			path := pub.GetSubConfPath(name)
+			if name == ServerMainNodeName {
+				path = pub.GetServerPath()
+			}
+			value, err := getJSON(path, v)
+			if err != nil {
+				return err
+			}
+			nodes[path] = value
+		}
+	}
+	for tp, subs := range c.vars {
+		pub := varpub.NewVarPub(platName)
+		for k, v := range subs {
+			path := pub.GetVarPath(tp, k)
+			value, err := getJSON(path, v)
+			if err != nil {
+				return err
+			}
+			nodes[path] = value
+		}
+	}
+	return check.Nodes(nodes, platName, systemName, types, clusterName).Err()
+}
+
 func publish(r registry.IRegistry, path string, v interface{}, cover bool) error {
 	value, err := getJSON(path, v)
 	if err != nil {
@@ -74,6 +124,9 @@ func deleteAll(r registry.IRegistry, path string) error {
 		return err
 	}
 	for _, v := range list {
+		if err := history.Archive(r, v); err != nil {
+			return err
+		}
 		if err := r.Delete(v); err != nil {
 			return err
 		}
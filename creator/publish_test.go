@@ -14,6 +14,7 @@ import (
 	"github.com/micro-plat/hydra/conf/vars/http"
 	"github.com/micro-plat/hydra/conf/vars/rpc"
 	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/servers"
 	"github.com/micro-plat/hydra/registry"
 	_ "github.com/micro-plat/hydra/registry/registry/filesystem"
 	_ "github.com/micro-plat/hydra/registry/registry/localmemory"
@@ -177,6 +178,25 @@ func Test_conf_Pub1(t *testing.T) {
 	}
 }
 
+func Test_conf_PubCheck(t *testing.T) {
+	global.Def.ServerTypes = []string{global.API}
+	defer func() { global.Def.ServerTypes = []string{} }()
+	if len(servers.GetServerTypes()) == 0 {
+		servers.Register(global.API, nil)
+	}
+
+	c := &conf{data: map[string]iCustomerBuilder{global.API: BaseBuilder{ServerMainNodeName: `{"address":"8080"}`, "static": `{"dir":"./notexists"}`}}, vars: map[string]map[string]interface{}{}}
+	err := c.Pub("platNameChk", "systemNameChk", "clusterNameChk", "lm://.", true)
+	assert.Equal(t, nil, err, "1. 发布前检查不检查本地静态文件目录")
+
+	c = &conf{data: map[string]iCustomerBuilder{global.API: BaseBuilder{ServerMainNodeName: "{}"}}, vars: map[string]map[string]interface{}{}}
+	err = c.Pub("platNameChk", "systemNameChk", "clusterNameChk2", "lm://.", true)
+	assert.NotEqual(t, nil, err, "2. 配置检查未通过时不发布")
+	r, _ := registry.GetRegistry("lm://.", global.Def.Log())
+	ok, _ := r.Exists(server.NewServerPub("platNameChk", "systemNameChk", global.API, "clusterNameChk2").GetServerPath())
+	assert.Equal(t, false, ok, "2. 配置检查未通过时不发布")
+}
+
 func checkData(r registry.IRegistry, path string, data map[string]string) error {
 	bd, _, err := r.GetValue(path)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/micro-plat/hydra/conf/server/acl/blacklist"
+	"github.com/micro-plat/hydra/conf/server/acl/canary"
 	"github.com/micro-plat/hydra/conf/server/acl/limiter"
 	"github.com/micro-plat/hydra/conf/server/acl/proxy"
 	"github.com/micro-plat/hydra/conf/server/acl/whitelist"
@@ -103,6 +104,13 @@ func (b *httpBuilder) Proxy(script string) *httpBuilder {
 	return b
 }
 
+//Canary 灰度路由配置
+func (b *httpBuilder) Canary(opts ...canary.Option) *httpBuilder {
+	path := fmt.Sprintf("%s/%s", canary.ParNodeName, canary.SubNodeName)
+	b.BaseBuilder[path] = canary.New(opts...)
+	return b
+}
+
 //Render 响应渲染配置
 func (b *httpBuilder) Render(script string) *httpBuilder {
 	b.BaseBuilder[render.TypeNodeName] = script
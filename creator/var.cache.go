@@ -5,6 +5,7 @@ import (
 	"github.com/micro-plat/hydra/conf/vars/cache/cacheredis"
 	gocache "github.com/micro-plat/hydra/conf/vars/cache/gocache"
 	memcached "github.com/micro-plat/hydra/conf/vars/cache/memcached"
+	"github.com/micro-plat/hydra/conf/vars/cache/tiered"
 )
 
 //Varcache 缓存配置对象
@@ -34,6 +35,11 @@ func (c *Varcache) Memcache(nodeName string, addr string, opts ...memcached.Opti
 	return c.Custom(nodeName, memcached.New(addr, opts...))
 }
 
+//Tiered 添加二级缓存,本地内存作为一级缓存,l2为已配置的缓存名称
+func (c *Varcache) Tiered(nodeName string, l2 string, opts ...tiered.Option) vars {
+	return c.Custom(nodeName, tiered.New(l2, opts...))
+}
+
 //Custom 自定义缓存配置
 func (c *Varcache) Custom(nodeName string, q interface{}) vars {
 	if _, ok := c.vars[cache.TypeNodeName]; !ok {
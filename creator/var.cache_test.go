@@ -1,6 +1,7 @@
 package creator
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/micro-plat/lib4go/assert"
@@ -10,6 +11,7 @@ import (
 	"github.com/micro-plat/hydra/conf/vars/cache/cacheredis"
 	gocache "github.com/micro-plat/hydra/conf/vars/cache/gocache"
 	memcached "github.com/micro-plat/hydra/conf/vars/cache/memcached"
+	"github.com/micro-plat/hydra/conf/vars/cache/tiered"
 )
 
 func TestNewCache(t *testing.T) {
@@ -101,6 +103,38 @@ func TestVarcache_Memcache(t *testing.T) {
 	}
 }
 
+func TestVarcache_Tiered(t *testing.T) {
+	type args struct {
+		name string
+		l2   string
+		opts []tiered.Option
+	}
+	tests := []struct {
+		name    string
+		fields  *Varcache
+		args    args
+		want    vars
+		wantErr string
+	}{
+		{name: "1. 初始化Tiered对象", fields: NewCache(map[string]map[string]interface{}{}), args: args{name: "tiered", l2: "redis", opts: []tiered.Option{tiered.WithExpiration(10)}},
+			want: map[string]map[string]interface{}{cache.TypeNodeName: map[string]interface{}{"tiered": tiered.New("redis", tiered.WithExpiration(10))}}},
+		{name: "2. 未指定二级缓存", fields: NewCache(map[string]map[string]interface{}{}), args: args{name: "tiered", l2: ""},
+			wantErr: "tiered配置数据有误"},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				e := recover()
+				if tt.wantErr != "" {
+					assert.Equal(t, true, strings.Contains(types.GetString(e), tt.wantErr), tt.name+",err")
+				}
+			}()
+			got := tt.fields.Tiered(tt.args.name, tt.args.l2, tt.args.opts...)
+			assert.Equal(t, tt.want, got, tt.name)
+		}()
+	}
+}
+
 func TestVarcache_Custom(t *testing.T) {
 	type args struct {
 		name string
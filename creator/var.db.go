@@ -4,6 +4,8 @@ import (
 	"github.com/micro-plat/hydra/conf/vars/db"
 	"github.com/micro-plat/hydra/conf/vars/db/mysql"
 	"github.com/micro-plat/hydra/conf/vars/db/oracle"
+	"github.com/micro-plat/hydra/conf/vars/db/postgres"
+	"github.com/micro-plat/hydra/conf/vars/db/sqlite"
 )
 
 //Vardb db配置
@@ -38,6 +40,21 @@ func (c *Vardb) MySQLByConnStr(nodeName string, connStr string, opts ...db.Optio
 	return c.Custom(nodeName, mysql.New(connStr, opts...))
 }
 
+//PostgreSQL 添加PostgreSQL
+func (c *Vardb) PostgreSQL(nodeName string, uName string, pwd string, serverIP string, dbName string, opts ...db.Option) vars {
+	return c.Custom(nodeName, postgres.NewBy(uName, pwd, serverIP, dbName, opts...))
+}
+
+//PostgreSQLByConnStr 添加PostgreSQL
+func (c *Vardb) PostgreSQLByConnStr(nodeName string, connStr string, opts ...db.Option) vars {
+	return c.Custom(nodeName, postgres.New(connStr, opts...))
+}
+
+//SQLite 添加SQLite,file为数据库文件路径,":memory:"为内存数据库
+func (c *Vardb) SQLite(nodeName string, file string, opts ...db.Option) vars {
+	return c.Custom(nodeName, sqlite.NewBy(file, opts...))
+}
+
 //Custom 自定义数据库配置
 func (c *Vardb) Custom(nodeName string, q interface{}) vars {
 	if _, ok := c.vars[db.TypeNodeName]; !ok {
@@ -1,6 +1,7 @@
 package creator
 
 import (
+	"github.com/micro-plat/hydra/conf/vars/feature"
 	"github.com/micro-plat/hydra/conf/vars/redis"
 	"github.com/micro-plat/hydra/conf/vars/rpc"
 
@@ -44,6 +45,12 @@ func (v vars) Redis(nodeName string, address string, opts ...redis.Option) vars
 	return v
 }
 
+//Feature 添加功能开关配置
+func (v vars) Feature(name string, opts ...feature.Option) vars {
+	v.Custom(feature.TypeNodeName, name, feature.New(opts...))
+	return v
+}
+
 //Custom 自定义配置
 func (v vars) Custom(typ string, nodeName string, i interface{}) vars {
 	if _, ok := v[typ]; !ok {
@@ -2,6 +2,7 @@ package creator
 
 import (
 	"github.com/micro-plat/hydra/conf/vars/queue"
+	queueamqp "github.com/micro-plat/hydra/conf/vars/queue/amqp"
 	queuelmq "github.com/micro-plat/hydra/conf/vars/queue/lmq"
 	queuemqtt "github.com/micro-plat/hydra/conf/vars/queue/mqtt"
 	"github.com/micro-plat/hydra/conf/vars/queue/queueredis"
@@ -29,6 +30,11 @@ func (c *Varqueue) MQTT(nodeName string, address string, opts ...queuemqtt.Optio
 	return c.Custom(nodeName, queuemqtt.New(address, opts...))
 }
 
+//AMQP 添加AMQP(RabbitMQ)
+func (c *Varqueue) AMQP(nodeName string, address string, opts ...queueamqp.Option) vars {
+	return c.Custom(nodeName, queueamqp.New(address, opts...))
+}
+
 //LMQ 添加本地内存作为消息队列
 func (c *Varqueue) LMQ(nodeName string) vars {
 	return c.Custom(nodeName, queuelmq.New())
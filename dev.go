@@ -1,5 +0,0 @@
-// +build dev
-
-package hydra
-
-import _ "github.com/micro-plat/hydra/hydra/cmds/db"
@@ -32,6 +32,7 @@ type CliFlagObject struct {
 	SysName         string
 	ServerTypeNames string
 	ClusterName     string
+	Env             string
 	IPMask          string
 	IsDebug         bool
 }
@@ -52,6 +52,9 @@ type global struct {
 	//ClusterName 集群名称
 	ClusterName string
 
+	//Env 运行环境,如dev,test,prod,发布配置时合并对应环境的覆盖配置
+	Env string
+
 	//DNSRoot DNS根节点
 	DNSRoot string
 
@@ -146,6 +149,11 @@ func (m *global) GetClusterName() string {
 	return m.ClusterName
 }
 
+//GetEnv 获取运行环境
+func (m *global) GetEnv() string {
+	return m.Env
+}
+
 //GetDNSRoot 获取dns根节点
 func (m *global) GetDNSRoot() string {
 	return m.DNSRoot
@@ -199,6 +207,7 @@ func (m *global) check() (err error) {
 	m.SysName = types.GetString(FlagVal.SysName, m.SysName)
 	m.ServerTypeNames = types.GetString(FlagVal.ServerTypeNames, m.ServerTypeNames)
 	m.ClusterName = types.GetString(FlagVal.ClusterName, m.ClusterName)
+	m.Env = types.GetString(FlagVal.Env, m.Env)
 	m.IPMask = types.GetString(FlagVal.IPMask, m.IPMask)
 
 	IsDebug = types.DecodeBool(FlagVal.IsDebug, true, true, IsDebug)
@@ -55,6 +55,9 @@ type IGlobal interface {
 	//GetClusterName 集群名称
 	GetClusterName() string
 
+	//GetEnv 运行环境
+	GetEnv() string
+
 	//GetTrace 显示请求与响应信息
 	GetTrace() string
 
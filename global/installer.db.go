@@ -1,6 +1,8 @@
 package global
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/micro-plat/lib4go/types"
@@ -8,34 +10,59 @@ import (
 
 //db 数据库处理逻辑
 type db struct {
-	sqls     []string
-	handlers []func() error
+	sqls       []string
+	handlers   []func() error
+	migrations []*Migration
+}
+
+//Migration 版本化的数据库迁移脚本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      []string
+	Down    []string
 }
 
 //AddBSQL 添加执行SQL
 func (d *db) AddBSQL(sqls ...[]byte) {
 	for _, sql := range sqls {
-		nsql := strings.Split(strings.Trim(types.BytesToString(sql), ";"), ";")
-		for _, m := range nsql {
-			if strings.TrimSpace(m) != "" {
-				d.sqls = append(d.sqls, strings.TrimSpace(m))
-			}
-		}
+		d.sqls = append(d.sqls, splitSQL(types.BytesToString(sql))...)
 	}
 }
 
 //AddBSQL 添加执行SQL
 func (d *db) AddSQL(sqls ...string) {
 	for _, sql := range sqls {
-		nsql := strings.Split(strings.Trim(sql, ";"), ";")
-		for _, m := range nsql {
-			if strings.TrimSpace(m) != "" {
-				d.sqls = append(d.sqls, strings.TrimSpace(m))
-			}
-		}
+		d.sqls = append(d.sqls, splitSQL(sql)...)
+	}
+}
 
+//AddMigration 添加版本化的迁移脚本,按版本号从小到大执行,down为回滚脚本,多条语句以;分隔
+func (d *db) AddMigration(version int64, name string, up string, down string) {
+	for _, m := range d.migrations {
+		if m.Version == version {
+			panic(fmt.Sprintf("重复的数据库迁移版本号:%d", version))
+		}
 	}
+	d.migrations = append(d.migrations, &Migration{Version: version, Name: name, Up: splitSQL(up), Down: splitSQL(down)})
+	sort.Slice(d.migrations, func(i, j int) bool {
+		return d.migrations[i].Version < d.migrations[j].Version
+	})
+}
 
+//GetMigrations 获取所有迁移脚本
+func (d *db) GetMigrations() []*Migration {
+	return d.migrations
+}
+
+func splitSQL(sql string) []string {
+	sqls := make([]string, 0, 1)
+	for _, m := range strings.Split(strings.Trim(sql, ";"), ";") {
+		if strings.TrimSpace(m) != "" {
+			sqls = append(sqls, strings.TrimSpace(m))
+		}
+	}
+	return sqls
 }
 
 //AddHandler 添加处理函数
@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/SkyAPM/go2sky v0.6.0
+	github.com/alicebob/miniredis/v2 v2.14.3
 	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef
 	github.com/bradfitz/gomemcache v0.0.0-20190913173617-a41fca850d0b
 	github.com/clbanning/mxj v1.8.4
@@ -19,8 +20,10 @@ require (
 	github.com/golang/protobuf v1.4.3
 	github.com/golang/snappy v0.0.2
 	github.com/gorilla/websocket v1.4.2
+	github.com/lib/pq v1.9.0
 	github.com/lib4dev/cli v1.2.8
 	github.com/manifoldco/promptui v0.8.0
+	github.com/mattn/go-sqlite3 v1.14.5
 	github.com/mholt/archiver v3.1.1+incompatible
 	github.com/micro-plat/gmq v1.0.1
 	github.com/micro-plat/lib4go v1.1.5
@@ -29,6 +32,7 @@ require (
 	github.com/pkg/profile v1.2.1
 	github.com/pkg/sftp v1.12.0
 	github.com/robfig/cron/v3 v3.0.1
+	github.com/streadway/amqp v1.0.0
 	github.com/stretchr/testify v1.6.1
 	github.com/ugorji/go/codec v1.2.2
 	github.com/ulikunitz/xz v0.5.9 // indirect
@@ -0,0 +1,76 @@
+package conf
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/check"
+	"github.com/micro-plat/hydra/conf/snapshot"
+	"github.com/micro-plat/hydra/creator"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/urfave/cli"
+)
+
+func checkNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 检查配置文档或程序中的配置
+	if docFile != "" {
+		err = checkDocument(docFile)
+	} else {
+		err = checkConf()
+	}
+	if err != nil {
+		logs.Log.Error("检查配置:", compatible.FAILED)
+		return err
+	}
+	logs.Log.Info("检查配置:", compatible.SUCCESS)
+	return nil
+}
+
+//checkConf 加载程序中的配置,合并当前环境的覆盖配置后检查
+func checkConf() error {
+	if err := creator.Conf.Load(); err != nil {
+		return err
+	}
+	if err := creator.Conf.Overlay(global.Current().GetEnv()); err != nil {
+		return err
+	}
+	return creator.Conf.Check(global.Current().GetPlatName(),
+		global.Current().GetSysName(),
+		global.Current().GetClusterName())
+}
+
+//checkDocument 读取配置文档并检查
+func checkDocument(path string) error {
+	buff, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文档%s失败:%w", path, err)
+	}
+	doc, err := snapshot.Decode(buff, getFormat())
+	if err != nil {
+		return err
+	}
+	return checkDoc(doc)
+}
+
+//checkDoc 使用当前平台、系统、集群检查配置文档
+func checkDoc(doc *snapshot.Document) error {
+	plat, sys, cluster := global.Current().GetPlatName(), global.Current().GetSysName(), global.Current().GetClusterName()
+	nodes, err := doc.Nodes(plat, sys, cluster)
+	if err != nil {
+		return err
+	}
+	types := make([]string, 0, len(doc.Servers))
+	for tp := range doc.Servers {
+		types = append(types, tp)
+	}
+	return check.Nodes(nodes, plat, sys, types, cluster).Err()
+}
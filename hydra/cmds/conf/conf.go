@@ -14,7 +14,7 @@ func init() {
 	cmds.RegisterFunc(func() cli.Command {
 		return cli.Command{
 			Name:  "conf",
-			Usage: "配置管理, 查看、安装配置信息",
+			Usage: "配置管理, 查看、安装配置信息及管理历史版本",
 			Subcommands: []cli.Command{
 				{
 					Name:   "show",
@@ -28,6 +28,51 @@ func init() {
 					Flags:  getInstallFlags(),
 					Action: installNow,
 				},
+				{
+					Name:      "encrypt",
+					Usage:     "-加密配置值，生成以enc:开头的加密值，可直接写入配置",
+					ArgsUsage: "value [value...]",
+					Flags:     getEncryptFlags(),
+					Action:    encryptNow,
+				},
+				{
+					Name:   "check",
+					Usage:  "-检查配置，使用各子配置的解析器检查程序中的配置或配置文档，显示所有错误",
+					Flags:  getCheckFlags(),
+					Action: checkNow,
+				},
+				{
+					Name:   "export",
+					Usage:  "-导出配置，将注册中心的服务器配置及变量配置导出为toml或json文档",
+					Flags:  getExportFlags(),
+					Action: exportNow,
+				},
+				{
+					Name:   "import",
+					Usage:  "-导入配置，检查配置文档并显示变更后写入注册中心",
+					Flags:  getImportFlags(),
+					Action: importNow,
+				},
+				{
+					Name:   "history",
+					Usage:  "-查看配置的历史版本",
+					Flags:  getHistoryFlags(),
+					Action: historyNow,
+				},
+				{
+					Name:      "diff",
+					Usage:     "-比较配置的历史版本，未指定v2时与当前配置比较",
+					ArgsUsage: "v1 [v2]",
+					Flags:     getHistoryFlags(),
+					Action:    diffNow,
+				},
+				{
+					Name:      "rollback",
+					Usage:     "-将配置恢复为指定的历史版本，恢复前归档当前配置",
+					ArgsUsage: "version",
+					Flags:     getHistoryFlags(),
+					Action:    rollbackNow,
+				},
 			},
 		}
 	})
@@ -0,0 +1,47 @@
+package conf
+
+import (
+	"fmt"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/secret"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/urfave/cli"
+)
+
+func encryptNow(c *cli.Context) (err error) {
+	if keyFile == "" {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return fmt.Errorf("未指定密钥文件")
+	}
+
+	//1. 生成主密钥
+	if genKey {
+		if err := secret.GenerateKeyFile(keyFile); err != nil {
+			return err
+		}
+		logs.Log.Info("生成主密钥:", keyFile, compatible.SUCCESS)
+	}
+	if c.NArg() == 0 {
+		if genKey {
+			return nil
+		}
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return fmt.Errorf("未指定需加密的配置值")
+	}
+
+	//2. 加密配置值
+	p, err := secret.NewKeyFile(keyFile)
+	if err != nil {
+		return err
+	}
+	secret.SetProvider(p)
+	for _, v := range c.Args() {
+		enc, err := secret.Encrypt(v)
+		if err != nil {
+			return err
+		}
+		fmt.Println(enc)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package conf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/history"
+	"github.com/micro-plat/hydra/conf/secret"
+	"github.com/micro-plat/hydra/conf/snapshot"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/urfave/cli"
+)
+
+func exportNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+
+	//2. 导出配置
+	doc, err := snapshot.Export(registry.GetCurrent(),
+		global.Current().GetPlatName(),
+		global.Current().GetSysName(),
+		global.Current().GetServerTypes(),
+		global.Current().GetClusterName())
+	if err != nil {
+		return err
+	}
+	buff, err := doc.Encode(getFormat())
+	if err != nil {
+		return err
+	}
+	if docFile == "" {
+		fmt.Println(string(buff))
+		return nil
+	}
+	if err := ioutil.WriteFile(docFile, buff, 0644); err != nil {
+		return fmt.Errorf("保存配置文档%s失败:%w", docFile, err)
+	}
+	logs.Log.Info("导出配置:", docFile, compatible.SUCCESS)
+	return nil
+}
+
+func importNow(c *cli.Context) (err error) {
+	//1. 绑定应用程序参数
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+	if docFile == "" {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return fmt.Errorf("未指定配置文档")
+	}
+
+	//2. 读取并检查配置文档
+	buff, err := ioutil.ReadFile(docFile)
+	if err != nil {
+		return fmt.Errorf("读取配置文档%s失败:%w", docFile, err)
+	}
+	doc, err := snapshot.Decode(buff, getFormat())
+	if err != nil {
+		return err
+	}
+	if err := checkDoc(doc); err != nil {
+		return err
+	}
+
+	//3. 获取并显示变更
+	r := registry.GetCurrent()
+	changes, err := snapshot.Plan(r, doc,
+		global.Current().GetPlatName(),
+		global.Current().GetSysName(),
+		global.Current().GetClusterName(), pruneNodes)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		logs.Log.Info("配置无变化")
+		return nil
+	}
+	for _, ch := range changes {
+		fmt.Printf("%s %s\n", ch.Action, ch.Path)
+		for _, line := range history.Diff(string(secret.Mask([]byte(ch.From))), string(secret.Mask([]byte(ch.To)))) {
+			if strings.TrimSpace(line) != "" {
+				fmt.Println("  " + line)
+			}
+		}
+	}
+	if dryRun {
+		return nil
+	}
+
+	//4. 执行变更
+	if err := snapshot.Apply(r, changes); err != nil {
+		logs.Log.Error("导入配置:", docFile, compatible.FAILED)
+		return err
+	}
+	logs.Log.Info("导入配置:", docFile, compatible.SUCCESS)
+	return nil
+}
+
+//getFormat 获取文档格式,未指定时根据文件扩展名判断,默认为toml
+func getFormat() string {
+	if docFormat != "" {
+		return strings.ToLower(docFormat)
+	}
+	if strings.ToLower(filepath.Ext(docFile)) == ".json" {
+		return snapshot.JSON
+	}
+	return snapshot.TOML
+}
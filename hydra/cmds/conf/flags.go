@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"github.com/micro-plat/hydra/conf/secret"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
 	"github.com/urfave/cli"
@@ -43,3 +44,103 @@ func getShowFlags() []cli.Flag {
 	flags = append(flags, global.ConfCli.GetFlags()...)
 	return flags
 }
+
+var keyFile string
+var genKey bool
+
+//getEncryptFlags 获取加密配置的参数
+func getEncryptFlags() []cli.Flag {
+	flags := make([]cli.Flag, 0, 2)
+	flags = append(flags, cli.StringFlag{
+		Name:        "keyfile,k",
+		Destination: &keyFile,
+		EnvVar:      secret.KeyFileEnv,
+		Usage:       `-密钥文件路径，未指定时使用环境变量` + secret.KeyFileEnv,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "genkey,g",
+		Destination: &genKey,
+		Usage:       `-生成新的主密钥并作为当前密钥写入密钥文件首行`,
+	})
+	return flags
+}
+
+var histNode string
+
+//getHistoryFlags 获取配置历史版本管理的参数
+func getHistoryFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "node,n",
+		Destination: &histNode,
+		Usage:       `-配置节点。main为服务器主配置，var/类型/名称为变量配置，以/开头为完整路径，其它为服务器子配置名称`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
+var docFile string
+var docFormat string
+var dryRun bool
+var pruneNodes bool
+
+//getExportFlags 获取导出配置的参数
+func getExportFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "file,f",
+		Destination: &docFile,
+		Usage:       `-配置文档路径，未指定时输出到控制台`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "format",
+		Destination: &docFormat,
+		Usage:       `-文档格式，toml或json，未指定时根据文件扩展名判断，默认为toml`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
+//getImportFlags 获取导入配置的参数
+func getImportFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "file,f",
+		Destination: &docFile,
+		Usage:       `-配置文档路径`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "format",
+		Destination: &docFormat,
+		Usage:       `-文档格式，toml或json，未指定时根据文件扩展名判断，默认为toml`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "dry-run",
+		Destination: &dryRun,
+		Usage:       `-只显示变更，不写入注册中心`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "prune",
+		Destination: &pruneNodes,
+		Usage:       `-删除配置文档中不存在的节点,平台变量只删除文档中包含的变量类型下的节点`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
+//getCheckFlags 获取检查配置的参数
+func getCheckFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, cli.StringFlag{
+		Name:        "file,f",
+		Destination: &docFile,
+		Usage:       `-配置文档路径，未指定时检查程序中的配置`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "format",
+		Destination: &docFormat,
+		Usage:       `-文档格式，toml或json，未指定时根据文件扩展名判断，默认为toml`,
+	})
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
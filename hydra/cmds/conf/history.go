@@ -0,0 +1,141 @@
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/history"
+	"github.com/micro-plat/hydra/conf/secret"
+	"github.com/micro-plat/hydra/conf/server"
+	varpub "github.com/micro-plat/hydra/conf/vars"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/urfave/cli"
+)
+
+func historyNow(c *cli.Context) (err error) {
+	path, err := bindHistory(c)
+	if err != nil {
+		return err
+	}
+	versions, err := history.List(registry.GetCurrent(), path)
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	if len(versions) == 0 {
+		fmt.Println("无历史版本")
+		return nil
+	}
+	for _, v := range versions {
+		fmt.Printf("%-8d %s  %s\n", v.Version, v.Time, v.Author)
+	}
+	return nil
+}
+
+func diffNow(c *cli.Context) (err error) {
+	path, err := bindHistory(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() == 0 || c.NArg() > 2 {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return fmt.Errorf("请指定需比较的版本号")
+	}
+
+	//未指定第二个版本时与当前值比较
+	from, err := getVersionData(path, c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	to, err := getVersionData(path, c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	for _, line := range history.Diff(from, to) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func rollbackNow(c *cli.Context) (err error) {
+	path, err := bindHistory(c)
+	if err != nil {
+		return err
+	}
+	if c.NArg() != 1 {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return fmt.Errorf("请指定需恢复的版本号")
+	}
+	version, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("版本号%s有误", c.Args().First())
+	}
+	if err := history.Rollback(registry.GetCurrent(), path, version); err != nil {
+		logs.Log.Error("恢复配置:", path, compatible.FAILED)
+		return err
+	}
+	logs.Log.Info("恢复配置:", path, version, compatible.SUCCESS)
+	return nil
+}
+
+//bindHistory 绑定应用程序参数并获取配置节点路径
+func bindHistory(c *cli.Context) (string, error) {
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return "", err
+	}
+	if histNode == "" {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return "", fmt.Errorf("未指定配置节点")
+	}
+	return getHistoryPath(histNode)
+}
+
+//getHistoryPath 获取配置节点路径,以/开头时为完整路径,var/类型/名称为变量配置,main为服务器主配置,其它为服务器子配置
+func getHistoryPath(node string) (string, error) {
+	if strings.HasPrefix(node, "/") {
+		return registry.Join(node), nil
+	}
+	if strings.HasPrefix(node, "var/") {
+		names := strings.Split(node, "/")
+		if len(names) != 3 {
+			return "", fmt.Errorf("变量配置节点%s格式有误,格式为var/类型/名称", node)
+		}
+		return varpub.NewVarPub(global.Current().GetPlatName()).GetVarPath(names[1], names[2]), nil
+	}
+	types := global.Current().GetServerTypes()
+	if len(types) == 0 {
+		return "", fmt.Errorf("未指定服务类型")
+	}
+	pub := server.NewServerPub(global.Current().GetPlatName(), global.Current().GetSysName(), types[0], global.Current().GetClusterName())
+	if node == "main" {
+		return pub.GetServerPath(), nil
+	}
+	return pub.GetSubConfPath(node), nil
+}
+
+//getVersionData 获取指定版本的配置值,版本号为空时获取当前值,返回脱敏后的内容
+func getVersionData(path string, version string) (string, error) {
+	r := registry.GetCurrent()
+	if version == "" {
+		data, _, err := r.GetValue(path)
+		if err != nil {
+			return "", err
+		}
+		return string(secret.Mask(data)), nil
+	}
+	n, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("版本号%s有误", version)
+	}
+	v, err := history.Get(r, path, n)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Mask([]byte(v.Data))), nil
+}
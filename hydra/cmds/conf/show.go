@@ -8,6 +8,7 @@ import (
 
 	"github.com/micro-plat/hydra/conf"
 	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/secret"
 	"github.com/micro-plat/hydra/registry"
 	"github.com/micro-plat/lib4go/types"
 	"github.com/zkfy/log"
@@ -143,13 +144,13 @@ func getNode(path string, p string, node map[string]interface{}) {
 		}
 	} else {
 		v, _, _ := r.GetValue(path)
-		node[p] = v
+		node[p] = secret.Mask(v)
 	}
 }
 func (s *show) getNodes(path string, v *conf.RawConf, input map[string]interface{}) {
 	li := strings.SplitN(strings.Trim(path, "/"), "/", 2)
 	if len(li) == 1 {
-		input[li[0]] = v.GetRaw()
+		input[li[0]] = secret.Mask(v.GetStoredRaw())
 		return
 	}
 	if len(li) > 1 {
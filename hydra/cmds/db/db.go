@@ -1,18 +1,16 @@
-// +build dev
-
-//数据库安装存在一定的风险，特别是SQL语句中包含有删除表，修改表等指令
-//所以编译项目时只有明确指定tags为"dev"时，才将此功能编译进二进制文件(go install -tags="dev")
-//生成生产环境二进制文件时，建议直接编译不要指定"dev"
+//版本化迁移脚本通过global.Installer.DB.AddMigration注册,执行前需确认,且支持dry-run预览,
+//可编译进生产环境二进制文件。db install仅在指定tags为"dev"时可用
 package db
 
 import (
 	"fmt"
-	"regexp"
 
 	"github.com/lib4dev/cli/cmds"
 	logs "github.com/lib4dev/cli/logger"
 	"github.com/manifoldco/promptui"
 	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/dbs"
+	"github.com/micro-plat/hydra/components/dlock"
 	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/global/compatible"
@@ -22,97 +20,179 @@ import (
 	"github.com/urfave/cli"
 )
 
+//devCommands 仅开发环境可用的子命令
+var devCommands []func() cli.Command
+
 func init() {
 	cmds.RegisterFunc(func() cli.Command {
-		return cli.Command{
-			Name:  "db",
-			Usage: "数据库, 数据库初始化管理",
-			Subcommands: []cli.Command{
-				{
-					Name:   "install",
-					Usage:  "-将数据表等安装到数据库",
-					Flags:  getInstallFlags(),
-					Action: install,
-				},
+		subcmds := []cli.Command{
+			{
+				Name:   "migrate",
+				Usage:  "-按版本号执行未执行的迁移脚本",
+				Flags:  getMigrateFlags(false),
+				Action: migrate,
+			},
+			{
+				Name:   "rollback",
+				Usage:  "-回滚最近执行的迁移脚本",
+				Flags:  getMigrateFlags(true),
+				Action: rollback,
+			},
+			{
+				Name:   "status",
+				Usage:  "-查看迁移脚本执行状态",
+				Flags:  getStatusFlags(),
+				Action: status,
 			},
 		}
+		for _, f := range devCommands {
+			subcmds = append(subcmds, f())
+		}
+		return cli.Command{
+			Name:        "db",
+			Usage:       "数据库, 数据库初始化管理",
+			Subcommands: subcmds,
+		}
 	})
 }
-func install(c *cli.Context) (err error) {
+
+func migrate(c *cli.Context) (err error) {
+	defer func() {
+		logNow(err)
+		err = nil
+	}()
+	m, err := getMigrator(c)
+	if err != nil {
+		return err
+	}
+	if !dryRun && !yes && !checkContinue() {
+		return nil
+	}
+	list, err := m.Migrate()
+	for _, mg := range list {
+		logMigration(mg, mg.Up)
+	}
+	if err == nil && len(list) == 0 {
+		logs.Log.Info("无待执行的迁移脚本")
+	}
+	return err
+}
+
+func rollback(c *cli.Context) (err error) {
 	defer func() {
 		logNow(err)
 		err = nil
 	}()
+	m, err := getMigrator(c)
+	if err != nil {
+		return err
+	}
+	if !dryRun && !yes && !checkContinue() {
+		return nil
+	}
+	list, err := m.Rollback(types.GetMax(steps, 1))
+	for _, mg := range list {
+		logMigration(mg, mg.Down)
+	}
+	if err == nil && len(list) == 0 {
+		logs.Log.Info("无可回滚的迁移脚本")
+	}
+	return err
+}
+
+func status(c *cli.Context) (err error) {
+	defer func() {
+		logNow(err)
+		err = nil
+	}()
+	m, err := getMigrator(c)
+	if err != nil {
+		return err
+	}
+	list, err := m.Status()
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		msg := fmt.Sprintf("%-16d %-32s %s", s.Version, s.Name, s.AppliedAt)
+		switch {
+		case s.Missing:
+			logs.Log.Warn(msg, "未注册")
+		case s.Modified:
+			logs.Log.Error(msg, "已修改")
+		case s.Applied:
+			logs.Log.Info(msg, "已执行")
+		default:
+			logs.Log.Info(msg, "未执行")
+		}
+	}
+	return nil
+}
 
+//getMigrator 绑定应用参数,拉取注册中心配置并构建迁移对象
+func getMigrator(c *cli.Context) (*dbs.Migrator, error) {
 	//1. 绑定应用程序参数
 	global.Current().Log().Pause()
 	if err := global.Def.Bind(c); err != nil {
 		cli.ShowCommandHelp(c, c.Command.Name)
-		return err
+		return nil, err
 	}
 
-	//2. 获取执行参数
-	sqls := global.Installer.DB.GetSQLs()
-	handlers := global.Installer.DB.GetHandlers()
-	if len(sqls) == 0 && len(handlers) == 0 {
-		return fmt.Errorf("未指定SQL或安装程序")
+	migrations := global.Installer.DB.GetMigrations()
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("未注册迁移脚本")
 	}
 
 	//2.检查是否安装注册中心配置
 	if registry.GetProto(global.Current().GetRegistryAddr()) == registry.LocalMemory {
 		if err := pkgs.Pub2Registry(true); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	//3. 拉取注册中心配置
 	if err := app.PullAndSave(); err != nil {
-		return err
+		return nil, err
 	}
 
-	//4. 执行SQL语句
-	if len(sqls) > 0 {
-		db, err := components.Def.DB().GetDB(types.GetString(dbName, "db"))
-		if err != nil {
-			return err
-		}
-		if !checkContinue() {
-			return nil
-		}
-		for _, sql := range sqls {
-			if _, err := db.Execute(sql, nil); err != nil {
-				err = fmt.Errorf("%32s\t%w", getMessage(sql), err)
-				if !skip {
-					return err
-				}
-				logs.Log.Error(err, compatible.FAILED)
-				continue
-			}
-			msg := fmt.Sprintf("%32s", getMessage(sql))
-			logs.Log.Info(msg, compatible.SUCCESS)
-		}
+	//4. 获取数据库
+	name := types.GetString(dbName, "db")
+	db, err := components.Def.DB().GetDB(name)
+	if err != nil {
+		return nil, err
+	}
+	opts := make([]dbs.MigrateOption, 0, 1)
+	if dryRun {
+		opts = append(opts, dbs.WithDryRun())
 	}
 
-	//5. 执行处理函数
-	for _, handle := range handlers {
-		if err := handle(); err != nil {
-			return err
-		}
+	//5. 多个节点同时部署时通过分布式锁依次执行
+	lock, err := dlock.NewLock(registry.Join("migrate", name), global.Current().GetRegistryAddr(), global.Def.Log())
+	if err != nil {
+		return nil, fmt.Errorf("创建数据库迁移锁失败:%w", err)
 	}
-	return nil
+	opts = append(opts, dbs.WithLock(lock))
+	return dbs.NewMigrator(db, migrations, opts...), nil
 }
+
+func logMigration(mg *global.Migration, sqls []string) {
+	msg := fmt.Sprintf("%-16d %-32s", mg.Version, mg.Name)
+	if !dryRun {
+		logs.Log.Info(msg, compatible.SUCCESS)
+		return
+	}
+	logs.Log.Info(msg, "dry-run")
+	for _, sql := range sqls {
+		logs.Log.Info(sql + ";")
+	}
+}
+
 func logNow(err error) {
 	if err != nil {
 		logs.Log.Error(err, compatible.FAILED)
 		return
 	}
 }
-func getMessage(input string) string {
-	raw := input[:types.GetMin(32, len(input))]
-	regx := regexp.MustCompile("[^\\(\\[]+")
-	nstr := regx.FindString(raw)
-	return nstr
-}
 
 func checkContinue() bool {
 	y := "Yes,继续执行"
@@ -126,10 +206,12 @@ func checkContinue() bool {
 }
 
 var dbName = "db"
-var skip bool
+var dryRun bool
+var yes bool
+var steps = 1
 
-//getInstallFlags 获取运行时的参数
-func getInstallFlags() []cli.Flag {
+//getStatusFlags 获取查看迁移状态的参数
+func getStatusFlags() []cli.Flag {
 	flags := pkgs.GetBaseFlags()
 	flags = append(flags, cli.BoolFlag{
 		Name:        "debug,d",
@@ -141,11 +223,30 @@ func getInstallFlags() []cli.Flag {
 		Destination: &dbName,
 		Usage:       `-数据库节点名,注册中配置的数据库节点名`,
 	})
+	flags = append(flags, global.DBCli.GetFlags()...)
+	return flags
+}
+
+//getMigrateFlags 获取执行、回滚迁移脚本的参数
+func getMigrateFlags(rollback bool) []cli.Flag {
+	flags := getStatusFlags()
 	flags = append(flags, cli.BoolFlag{
-		Name:        "skip",
-		Destination: &skip,
-		Usage:       `-跳过执行失败的SQL语句`,
+		Name:        "dry-run",
+		Destination: &dryRun,
+		Usage:       `-只打印待执行的SQL语句,不修改数据库`,
 	})
-	flags = append(flags, global.DBCli.GetFlags()...)
+	flags = append(flags, cli.BoolFlag{
+		Name:        "yes",
+		Destination: &yes,
+		Usage:       `-跳过执行确认,用于自动化部署`,
+	})
+	if rollback {
+		flags = append(flags, cli.IntFlag{
+			Name:        "steps",
+			Value:       1,
+			Destination: &steps,
+			Usage:       `-回滚的脚本数量`,
+		})
+	}
 	return flags
 }
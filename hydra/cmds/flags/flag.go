@@ -0,0 +1,59 @@
+package flags
+
+import (
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/hydra/cmds/pkgs"
+	"github.com/urfave/cli"
+)
+
+var percent int
+var users string
+var clusters string
+var serverTypes string
+var desc string
+
+//getBaseFlags 获取功能开关管理的参数
+func getBaseFlags() []cli.Flag {
+	flags := pkgs.GetBaseFlags()
+	flags = append(flags, global.ConfCli.GetFlags()...)
+	return flags
+}
+
+//getSetFlags 获取设置功能开关的参数
+func getSetFlags() []cli.Flag {
+	flags := getBaseFlags()
+	flags = append(flags, cli.BoolFlag{
+		Name:  "enable",
+		Usage: `-开启功能开关`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:  "disable",
+		Usage: `-关闭功能开关`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "percent",
+		Destination: &percent,
+		Usage:       `-按用户编号放量的比例(0-100)，只对白名单开启时设置为0`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "users",
+		Destination: &users,
+		Usage:       `-白名单用户编号，多个用逗号分隔，白名单中的用户直接开启`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "clusters",
+		Destination: &clusters,
+		Usage:       `-只对指定集群开启，多个用逗号分隔，设置为空时对所有集群开启`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "types",
+		Destination: &serverTypes,
+		Usage:       `-只对指定服务器类型开启，多个用逗号分隔，设置为空时对所有服务器类型开启`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "desc",
+		Destination: &desc,
+		Usage:       `-开关描述`,
+	})
+	return flags
+}
@@ -0,0 +1,260 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib4dev/cli/cmds"
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/conf/history"
+	"github.com/micro-plat/hydra/conf/vars/feature"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	"github.com/micro-plat/hydra/registry"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	cmds.RegisterFunc(func() cli.Command {
+		return cli.Command{
+			Name:  "flags",
+			Usage: "功能开关, 查看、修改保存在注册中心的功能开关",
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "-查看所有功能开关",
+					Flags:  getBaseFlags(),
+					Action: listNow,
+				},
+				{
+					Name:      "set",
+					Usage:     "-设置功能开关，开关不存在时创建，只修改指定的参数",
+					ArgsUsage: "name",
+					Flags:     getSetFlags(),
+					Action:    setNow,
+				},
+				{
+					Name:      "enable",
+					Usage:     "-开启功能开关",
+					ArgsUsage: "name",
+					Flags:     getBaseFlags(),
+					Action:    enableNow,
+				},
+				{
+					Name:      "disable",
+					Usage:     "-关闭功能开关",
+					ArgsUsage: "name",
+					Flags:     getBaseFlags(),
+					Action:    disableNow,
+				},
+				{
+					Name:      "delete",
+					Usage:     "-删除功能开关，删除前归档当前配置",
+					ArgsUsage: "name",
+					Flags:     getBaseFlags(),
+					Action:    deleteNow,
+				},
+			},
+		}
+	})
+}
+
+func listNow(c *cli.Context) (err error) {
+	if err := bind(c); err != nil {
+		return err
+	}
+	r := registry.GetCurrent()
+	root := getRoot()
+	if ok, err := r.Exists(root); err != nil || !ok {
+		fmt.Println("无功能开关")
+		return err
+	}
+	names, _, err := r.GetChildren(root)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("无功能开关")
+		return nil
+	}
+	for _, name := range names {
+		data, _, err := r.GetValue(registry.Join(root, name))
+		if err != nil {
+			return err
+		}
+		flag, err := feature.NewByRaw(name, data)
+		if err != nil {
+			fmt.Printf("%-24s %v\n", name, err)
+			continue
+		}
+		fmt.Printf("%-24s %s\n", name, describe(flag))
+	}
+	return nil
+}
+
+func setNow(c *cli.Context) (err error) {
+	return update(c, func(f *feature.Flag) {
+		if c.IsSet("enable") {
+			f.Enable = true
+		}
+		if c.IsSet("disable") {
+			f.Enable = false
+		}
+		if c.IsSet("percent") {
+			f.Percent = percent
+		}
+		if c.IsSet("users") {
+			f.Users = split(users)
+		}
+		if c.IsSet("clusters") {
+			f.Clusters = split(clusters)
+		}
+		if c.IsSet("types") {
+			f.ServerTypes = split(serverTypes)
+		}
+		if c.IsSet("desc") {
+			f.Desc = desc
+		}
+	})
+}
+
+func enableNow(c *cli.Context) (err error) {
+	return update(c, func(f *feature.Flag) {
+		f.Enable = true
+	})
+}
+
+func disableNow(c *cli.Context) (err error) {
+	return update(c, func(f *feature.Flag) {
+		f.Enable = false
+	})
+}
+
+func deleteNow(c *cli.Context) (err error) {
+	name, err := bindName(c)
+	if err != nil {
+		return err
+	}
+	r := registry.GetCurrent()
+	path := registry.Join(getRoot(), name)
+	if ok, err := r.Exists(path); err != nil || !ok {
+		if err == nil {
+			err = fmt.Errorf("功能开关%s不存在", name)
+		}
+		return err
+	}
+	if err := history.Archive(r, path); err != nil {
+		return err
+	}
+	if err := r.Delete(path); err != nil {
+		logs.Log.Error("删除功能开关:", name, compatible.FAILED)
+		return err
+	}
+	logs.Log.Info("删除功能开关:", name, compatible.SUCCESS)
+	return nil
+}
+
+//update 修改功能开关并保存到注册中心,开关不存在时以默认配置创建,修改前归档当前配置
+func update(c *cli.Context, f func(*feature.Flag)) error {
+	name, err := bindName(c)
+	if err != nil {
+		return err
+	}
+	r := registry.GetCurrent()
+	path := registry.Join(getRoot(), name)
+	ok, err := r.Exists(path)
+	if err != nil {
+		return err
+	}
+	flag := feature.New()
+	if ok {
+		data, _, err := r.GetValue(path)
+		if err != nil {
+			return err
+		}
+		if flag, err = feature.NewByRaw(name, data); err != nil {
+			return err
+		}
+	}
+	f(flag)
+	buff, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	if flag, err = feature.NewByRaw(name, buff); err != nil {
+		return err
+	}
+	if ok {
+		if err = history.Archive(r, path); err == nil {
+			err = r.Update(path, string(buff))
+		}
+	} else {
+		err = r.CreatePersistentNode(path, string(buff))
+	}
+	if err != nil {
+		logs.Log.Error("保存功能开关:", name, compatible.FAILED)
+		return err
+	}
+	logs.Log.Info("保存功能开关:", name, describe(flag), compatible.SUCCESS)
+	return nil
+}
+
+//bind 绑定应用程序参数
+func bind(c *cli.Context) error {
+	global.Current().Log().Pause()
+	if err := global.Def.Bind(c); err != nil {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return err
+	}
+	return nil
+}
+
+//bindName 绑定应用程序参数并获取开关名称
+func bindName(c *cli.Context) (string, error) {
+	if err := bind(c); err != nil {
+		return "", err
+	}
+	name := c.Args().First()
+	if c.NArg() != 1 || name == "" || strings.Contains(name, "/") {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return "", fmt.Errorf("请指定功能开关名称")
+	}
+	return name, nil
+}
+
+func getRoot() string {
+	return registry.Join(global.Current().GetPlatName(), "var", feature.TypeNodeName)
+}
+
+//describe 获取开关的规则描述
+func describe(f *feature.Flag) string {
+	status := "关闭"
+	if f.Enable {
+		status = "开启"
+	}
+	items := []string{status, fmt.Sprintf("放量:%d%%", f.Percent)}
+	if len(f.Users) > 0 {
+		items = append(items, "白名单:"+strings.Join(f.Users, ","))
+	}
+	if len(f.Clusters) > 0 {
+		items = append(items, "集群:"+strings.Join(f.Clusters, ","))
+	}
+	if len(f.ServerTypes) > 0 {
+		items = append(items, "服务器类型:"+strings.Join(f.ServerTypes, ","))
+	}
+	if f.Desc != "" {
+		items = append(items, f.Desc)
+	}
+	return strings.Join(items, " ")
+}
+
+func split(s string) []string {
+	list := make([]string, 0, 1)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
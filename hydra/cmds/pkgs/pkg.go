@@ -42,6 +42,7 @@ func GetBaseFlags() []cli.Flag {
 	flags = append(flags, sysNameFlag)
 	flags = append(flags, serverTypesFlag)
 	flags = append(flags, clusterFlag)
+	flags = append(flags, envFlag)
 	return flags
 }
 
@@ -73,3 +74,9 @@ var clusterFlag = cli.StringFlag{
 	Destination: &global.FlagVal.ClusterName,
 	Usage:       "-集群名称，默认值为：prod",
 }
+var envFlag = cli.StringFlag{
+	Name:        "env,E",
+	Destination: &global.FlagVal.Env,
+	EnvVar:      "HYDRA_ENV",
+	Usage:       "-运行环境，发布配置时合并覆盖配置目录中对应环境的配置文件，如：dev,test,prod",
+}
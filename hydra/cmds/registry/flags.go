@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"github.com/micro-plat/hydra/registry/replica"
+	"github.com/urfave/cli"
+)
+
+var from string
+var to string
+var paths cli.StringSlice
+var excludes cli.StringSlice
+var policy string
+var allNodes bool
+var mirror bool
+var interval int
+var dryRun bool
+
+//getCopyFlags 获取复制节点的参数
+func getCopyFlags() []cli.Flag {
+	flags := make([]cli.Flag, 0, 9)
+	flags = append(flags, cli.StringFlag{
+		Name:        "from,f",
+		Destination: &from,
+		Usage:       `-源注册中心地址。格式：proto://host。如：zk://ip1,ip2`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "to,t",
+		Destination: &to,
+		Usage:       `-目标注册中心地址。格式：proto://host。如：fs://../`,
+	})
+	flags = append(flags, cli.StringSliceFlag{
+		Name:  "path,p",
+		Value: &paths,
+		Usage: `-复制的路径，可指定多个，默认为根路径`,
+	})
+	flags = append(flags, cli.StringSliceFlag{
+		Name:  "exclude,e",
+		Value: &excludes,
+		Usage: `-排除的路径，可指定多个，*匹配一段路径，**匹配多段路径。如：/*/var/**`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "all",
+		Destination: &allNodes,
+		Usage:       `-不排除默认的临时节点(servers、services、dns、dlock等)`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "policy",
+		Destination: &policy,
+		Value:       replica.Skip,
+		Usage:       `-目标节点已存在且值不同时的处理策略，skip:跳过，overwrite:覆盖，fail:报错`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "mirror,m",
+		Destination: &mirror,
+		Usage:       `-镜像模式，复制后持续监控源注册中心的变化并同步到目标注册中心`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "interval",
+		Destination: &interval,
+		Value:       60,
+		Usage:       `-镜像模式下全量同步的间隔(秒)`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "dry-run",
+		Destination: &dryRun,
+		Usage:       `-只显示变更数量，不写入目标注册中心`,
+	})
+	return flags
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lib4dev/cli/cmds"
+	logs "github.com/lib4dev/cli/logger"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/global/compatible"
+	r "github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/replica"
+	"github.com/urfave/cli"
+)
+
+func init() {
+	cmds.RegisterFunc(func() cli.Command {
+		return cli.Command{
+			Name:  "registry",
+			Usage: "注册中心, 在注册中心之间复制、同步节点",
+			Subcommands: []cli.Command{
+				{
+					Name:   "copy",
+					Usage:  "-复制节点，将源注册中心的持久节点复制到目标注册中心，--mirror时持续同步",
+					Flags:  getCopyFlags(),
+					Action: copyNow,
+				},
+			},
+		}
+	})
+}
+
+func copyNow(c *cli.Context) (err error) {
+	if from == "" || to == "" {
+		cli.ShowCommandHelp(c, c.Command.Name)
+		return fmt.Errorf("未指定源注册中心或目标注册中心")
+	}
+	if mirror && interval <= 0 {
+		return fmt.Errorf("全量同步的间隔必须大于0:%d", interval)
+	}
+
+	//1. 创建注册中心
+	src, err := r.CreateRegistry(from, global.Def.Log())
+	if err != nil {
+		return fmt.Errorf("创建源注册中心%s失败:%w", from, err)
+	}
+	defer src.Close()
+	dst, err := r.CreateRegistry(to, global.Def.Log())
+	if err != nil {
+		return fmt.Errorf("创建目标注册中心%s失败:%w", to, err)
+	}
+	defer dst.Close()
+
+	//2. 复制节点
+	opts := []replica.Option{replica.WithPolicy(policy), replica.WithExcludes(excludes...), replica.WithInterval(time.Duration(interval) * time.Second)}
+	if allNodes {
+		opts = append(opts, replica.WithoutDefExcludes())
+	}
+	if dryRun {
+		opts = append(opts, replica.WithDryRun())
+	}
+	rp := replica.New(src, dst, opts...)
+	if !mirror {
+		result, err := rp.Copy(paths...)
+		if err != nil {
+			logs.Log.Error("复制节点:", result, compatible.FAILED)
+			return err
+		}
+		logs.Log.Info("复制节点:", result, compatible.SUCCESS)
+		return nil
+	}
+
+	//3. 持续同步直到收到退出信号
+	done := make(chan struct{})
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+		<-ch
+		close(done)
+	}()
+	logs.Log.Info("开始同步节点:", from, "->", to)
+	return rp.Mirror(done, paths...)
+}
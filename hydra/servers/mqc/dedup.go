@@ -0,0 +1,62 @@
+package mqc
+
+import (
+	"fmt"
+
+	"github.com/micro-plat/hydra/components"
+	"github.com/micro-plat/hydra/components/caches"
+	"github.com/micro-plat/hydra/components/pkgs"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//dedup 根据消息编号对消息去重
+type dedup struct {
+	cache  caches.ICache
+	key    string
+	owner  string
+	expire int
+}
+
+//newDedup 构建去重处理器,未开启去重或消息未包含编号时返回nil
+func newDedup(req *Request) *dedup {
+	id := req.header[pkgs.HeaderMessageID]
+	if id == "" {
+		return nil
+	}
+	cnf, err := app.Cache.GetAPPConf(MQC)
+	if err != nil {
+		return nil
+	}
+	server, err := cnf.GetMQCMainConf()
+	if err != nil || server.Dedup == "" {
+		return nil
+	}
+	c, err := components.Def.Cache().GetCache(server.Dedup)
+	if err != nil {
+		global.Def.Log().Errorf("获取消息去重缓存失败:%v", err)
+		return nil
+	}
+	return &dedup{
+		cache:  c,
+		key:    fmt.Sprintf("%s:mqc:dedup:%s:%s", global.Def.GetPlatName(), req.queue.Queue, id),
+		owner:  utility.GetGUID(),
+		expire: server.DedupExpire,
+	}
+}
+
+//Acquire 原子写入标记,消息已被其它消费者接收或已处理过时返回false,缓存不可用时不去重
+func (d *dedup) Acquire() bool {
+	ok, err := caches.TryLock(d.cache, d.key, d.owner, d.expire)
+	if err != nil {
+		global.Def.Log().Errorf("标记消息已接收失败:%v", err)
+		return true
+	}
+	return ok
+}
+
+//Release 清除当前消费者写入的标记,处理失败的消息重新投递后可再次处理
+func (d *dedup) Release() {
+	caches.Unlock(d.cache, d.key, d.owner)
+}
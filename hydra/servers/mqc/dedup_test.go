@@ -0,0 +1,56 @@
+package mqc
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/micro-plat/hydra/components/caches/cache/redis"
+	varredis "github.com/micro-plat/hydra/conf/vars/redis"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestDedup(t *testing.T) {
+	m, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer m.Close()
+	c, err := redis.NewByOpts(varredis.WithAddrs(m.Addr()))
+	assert.Equal(t, nil, err, "创建redis缓存")
+	defer c.Close()
+
+	//多个消费者同时收到重复投递的消息,只有一个消费者处理
+	ds := make([]*dedup, 20)
+	for i := range ds {
+		ds[i] = &dedup{cache: c, key: "dedup:msg", owner: fmt.Sprint(i), expire: 60}
+	}
+	var n int32
+	var wg sync.WaitGroup
+	var winner *dedup
+	for _, d := range ds {
+		wg.Add(1)
+		go func(d *dedup) {
+			defer wg.Done()
+			if d.Acquire() {
+				atomic.AddInt32(&n, 1)
+				winner = d
+			}
+		}(d)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), n, "1. 只有一个消费者处理重复消息")
+
+	//其它消费者释放时不清除处理者的标记
+	for _, d := range ds {
+		if d != winner {
+			d.Release()
+		}
+	}
+	assert.Equal(t, true, c.Exists("dedup:msg"), "2. 不清除其它消费者的标记")
+
+	//处理失败后清除标记,重新投递的消息可再次处理
+	winner.Release()
+	assert.Equal(t, false, c.Exists("dedup:msg"), "3. 处理失败后清除标记")
+	assert.Equal(t, true, ds[0].Acquire(), "3. 重新投递的消息可再次处理")
+}
@@ -0,0 +1,196 @@
+package mqc
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	"github.com/micro-plat/hydra/conf/app"
+	"github.com/micro-plat/hydra/conf/server/mqc"
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/services"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//lags 当前进程中各队列最近一次的积压信息
+var lags = cmap.New(4)
+
+//LagService 队列积压信息管理服务的默认名称
+const LagService = "/mqc/lag"
+
+//LagHandler 获取当前进程各队列的积压信息,不自动注册,需要时由应用注册到api服务器并配置认证:
+//app.API(mqc.LagService, mqc.LagHandler)
+func LagHandler(ctx context.IContext) interface{} {
+	items := lags.Items()
+	list := make([]*services.MQCLag, 0, len(items))
+	for _, v := range items {
+		list = append(list, v.(*services.MQCLag))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Queue < list[j].Queue
+	})
+	return list
+}
+
+//defLagInterval 默认采样间隔(秒)
+const defLagInterval = 10
+
+//monitor 定时采样队列积压数与处理速度,积压持续超过阈值时触发告警
+type monitor struct {
+	p         *Processor
+	processed cmap.ConcurrentMap
+	states    map[string]*lagState
+	closeCh   chan struct{}
+	once      sync.Once
+	log       logger.ILogger
+}
+
+type lagState struct {
+	processed int64
+	time      time.Time
+	since     time.Time
+	alerted   bool
+}
+
+func newMonitor(p *Processor) *monitor {
+	return &monitor{
+		p:         p,
+		processed: cmap.New(4),
+		states:    make(map[string]*lagState),
+		closeCh:   make(chan struct{}),
+		log:       logger.New("mqc.lag"),
+	}
+}
+
+//Done 记录队列已处理一条消息
+func (m *monitor) Done(name string) {
+	_, v := m.processed.SetIfAbsent(name, new(int64))
+	atomic.AddInt64(v.(*int64), 1)
+}
+
+//Run 定时采样,直到关闭
+func (m *monitor) Run() {
+	defer m.clear()
+	for {
+		interval := defLagInterval
+		cnf, lag := m.getConf()
+		if lag != nil && lag.Interval > 0 {
+			interval = lag.Interval
+		}
+		select {
+		case <-m.closeCh:
+			return
+		case <-time.After(time.Duration(interval) * time.Second):
+			if lag == nil || m.p.status != running {
+				continue
+			}
+			if err := m.sample(cnf, lag); err != nil {
+				m.log.Errorf("队列积压采样失败:%v", err)
+			}
+		}
+	}
+}
+
+//Close 关闭监控
+func (m *monitor) Close() {
+	m.once.Do(func() {
+		close(m.closeCh)
+	})
+}
+
+func (m *monitor) clear() {
+	for name := range m.states {
+		lags.Remove(name)
+	}
+}
+
+func (m *monitor) getConf() (app.IAPPConf, *mqc.Lag) {
+	cnf, err := app.Cache.GetAPPConf(MQC)
+	if err != nil {
+		return nil, nil
+	}
+	server, err := cnf.GetMQCMainConf()
+	if err != nil {
+		return nil, nil
+	}
+	return cnf, server.Lag
+}
+
+func (m *monitor) sample(cnf app.IAPPConf, lag *mqc.Lag) error {
+	registry, collect, err := m.p.metric.GetRegistry(cnf)
+	if err != nil {
+		return err
+	}
+	list := m.collect(time.Now(), lag.Threshold, time.Duration(lag.Duration)*time.Second)
+	if !collect {
+		return nil
+	}
+	serverName := cnf.GetServerConf().GetServerName()
+	ip := global.LocalIP()
+	for _, l := range list {
+		backlogName := metrics.MakeName("mqc.server.queue.backlog", metrics.GAUGE, "server", serverName, "host", ip, "queue", l.Queue)
+		rateName := metrics.MakeName("mqc.server.queue.rate", metrics.GAUGEFLOAST64, "server", serverName, "host", ip, "queue", l.Queue)
+		metrics.GetOrRegisterGauge(backlogName, registry).Update(l.Backlog)
+		metrics.GetOrRegisterGaugeFloat64(rateName, registry).Update(l.Rate)
+	}
+	return nil
+}
+
+//collect 采样各队列积压数与处理速度,积压数持续duration超过threshold时执行告警勾子
+func (m *monitor) collect(now time.Time, threshold int64, duration time.Duration) []*services.MQCLag {
+	items := m.p.queues.Items()
+	for name := range m.states {
+		if _, ok := items[name]; !ok {
+			delete(m.states, name)
+			lags.Remove(name)
+		}
+	}
+	list := make([]*services.MQCLag, 0, len(items))
+	for _, v := range items {
+		name := v.(*queue.Queue).Queue
+		backlog, err := m.p.count(name)
+		if err != nil {
+			m.log.Errorf("获取队列%s积压数失败:%v", name, err)
+			continue
+		}
+		var processed int64
+		if v, ok := m.processed.Get(name); ok {
+			processed = atomic.LoadInt64(v.(*int64))
+		}
+		state, ok := m.states[name]
+		if !ok {
+			state = &lagState{processed: processed, time: now}
+			m.states[name] = state
+		}
+		lag := &services.MQCLag{Queue: name, Backlog: backlog, Time: now}
+		if elapsed := now.Sub(state.time).Seconds(); elapsed > 0 {
+			lag.Rate = float64(processed-state.processed) / elapsed
+		}
+		state.processed, state.time = processed, now
+
+		//积压数超过阈值开始计时,持续指定时长后告警,恢复后重置
+		if threshold > 0 && backlog > threshold {
+			if state.since.IsZero() {
+				state.since = now
+			}
+			lag.Exceeded = true
+			lag.Since = state.since
+			if !state.alerted && now.Sub(state.since) >= duration {
+				state.alerted = true
+				m.log.Warnf("队列%s积压%d条,已持续%v超过阈值%d", name, backlog, now.Sub(state.since), threshold)
+				services.MQC.DoLag(lag)
+			}
+		} else {
+			state.since = time.Time{}
+			state.alerted = false
+		}
+		lags.Set(name, lag)
+		list = append(list, lag)
+	}
+	return list
+}
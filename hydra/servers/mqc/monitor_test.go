@@ -0,0 +1,94 @@
+package mqc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/hydra/services"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+type countMQP struct {
+	backlog int64
+}
+
+func (c *countMQP) Push(key string, value string) error { return nil }
+func (c *countMQP) Pop(key string) (string, error)      { return "", nil }
+func (c *countMQP) Count(key string) (int64, error)     { return atomic.LoadInt64(&c.backlog), nil }
+func (c *countMQP) Close() error                        { return nil }
+
+func newTestMonitor(name string, mqp *countMQP) *monitor {
+	p := &Processor{queues: cmap.New(4), mqp: mqp}
+	p.queues.Set(name, &queue.Queue{Queue: name})
+	return newMonitor(p)
+}
+
+func TestMonitor_Collect(t *testing.T) {
+	mqp := &countMQP{backlog: 10}
+	m := newTestMonitor("monitor:collect", mqp)
+	defer m.clear()
+
+	now := time.Now()
+	list := m.collect(now, 0, 0)
+	assert.Equal(t, 1, len(list), "1. 采样所有队列")
+	assert.Equal(t, int64(10), list[0].Backlog, "1. 采样积压数")
+	assert.Equal(t, float64(0), list[0].Rate, "1. 首次采样处理速度为0")
+
+	for i := 0; i < 20; i++ {
+		m.Done("monitor:collect")
+	}
+	atomic.StoreInt64(&mqp.backlog, 5)
+	list = m.collect(now.Add(time.Second*10), 0, 0)
+	assert.Equal(t, int64(5), list[0].Backlog, "2. 积压数更新")
+	assert.Equal(t, float64(2), list[0].Rate, "2. 按采样间隔计算处理速度")
+	assert.Equal(t, false, list[0].Exceeded, "2. 未设置阈值时不告警")
+
+	v, ok := lags.Get("monitor:collect")
+	assert.Equal(t, true, ok, "3. 管理服务可获取积压信息")
+	assert.Equal(t, list[0], v, "3. 管理服务返回最近一次采样")
+
+	m.p.queues.Remove("monitor:collect")
+	list = m.collect(now.Add(time.Second*20), 0, 0)
+	_, ok = lags.Get("monitor:collect")
+	assert.Equal(t, 0, len(list), "4. 队列移除后不再采样")
+	assert.Equal(t, false, ok, "4. 队列移除后清除积压信息")
+}
+
+func TestMonitor_Alert(t *testing.T) {
+	name := "monitor:alert"
+	var alerts int32
+	services.MQC.OnLag(func(l *services.MQCLag) {
+		if l.Queue == name {
+			atomic.AddInt32(&alerts, 1)
+		}
+	})
+	mqp := &countMQP{backlog: 200}
+	m := newTestMonitor(name, mqp)
+	defer m.clear()
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		backlog  int64
+		offset   time.Duration
+		exceeded bool
+		alerts   int32
+	}{
+		{name: "1. 积压超过阈值开始计时,未达持续时长不告警", backlog: 200, offset: 0, exceeded: true, alerts: 0},
+		{name: "2. 未达持续时长不告警", backlog: 200, offset: time.Second * 20, exceeded: true, alerts: 0},
+		{name: "3. 持续超过阈值达到指定时长时告警", backlog: 200, offset: time.Second * 30, exceeded: true, alerts: 1},
+		{name: "4. 已告警后持续超过阈值不重复告警", backlog: 200, offset: time.Second * 60, exceeded: true, alerts: 1},
+		{name: "5. 积压恢复后重置计时", backlog: 50, offset: time.Second * 70, exceeded: false, alerts: 1},
+		{name: "6. 再次超过阈值重新计时", backlog: 200, offset: time.Second * 80, exceeded: true, alerts: 1},
+		{name: "7. 再次持续超过阈值达到指定时长时告警", backlog: 200, offset: time.Second * 110, exceeded: true, alerts: 2},
+	}
+	for _, tt := range tests {
+		atomic.StoreInt64(&mqp.backlog, tt.backlog)
+		list := m.collect(now.Add(tt.offset), 100, time.Second*30)
+		assert.Equal(t, tt.exceeded, list[0].Exceeded, tt.name)
+		assert.Equal(t, tt.alerts, atomic.LoadInt32(&alerts), tt.name)
+	}
+}
@@ -0,0 +1,86 @@
+package mqc
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+//partitioner 按分区键将消息分配到固定分区,每个分区由单独协程顺序处理
+type partitioner struct {
+	chans   []chan *Request
+	handle  func(*Request)
+	next    uint32
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+	closeCh chan struct{}
+}
+
+//newPartitioner 构建分区处理器
+func newPartitioner(partitions int, capacity int, handle func(*Request)) *partitioner {
+	p := &partitioner{
+		chans:   make([]chan *Request, partitions),
+		handle:  handle,
+		closeCh: make(chan struct{}),
+	}
+	for i := range p.chans {
+		p.chans[i] = make(chan *Request, capacity)
+		p.wg.Add(1)
+		go p.run(p.chans[i])
+	}
+	return p
+}
+
+//Dispatch 将请求放入分区队列,分区队列已满时阻塞等待,已关闭时返回false
+func (p *partitioner) Dispatch(key string, req *Request) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	p.chans[p.index(key)] <- req
+	return true
+}
+
+//Close 关闭分区处理器,等待正在分配及已分配的消息处理完成
+func (p *partitioner) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+	close(p.closeCh)
+	p.wg.Wait()
+}
+
+func (p *partitioner) run(ch chan *Request) {
+	defer p.wg.Done()
+	for {
+		select {
+		case req := <-ch:
+			p.handle(req)
+		case <-p.closeCh:
+			for {
+				select {
+				case req := <-ch:
+					p.handle(req)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+//index 获取分区编号,未指定分区键时轮询分配
+func (p *partitioner) index(key string) int {
+	if key == "" {
+		return int(atomic.AddUint32(&p.next, 1) % uint32(len(p.chans)))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.chans)))
+}
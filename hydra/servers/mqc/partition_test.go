@@ -0,0 +1,139 @@
+package mqc
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/components/pkgs"
+	"github.com/micro-plat/hydra/components/queues/mq"
+	"github.com/micro-plat/hydra/components/queues/mq/lmq"
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/concurrent/cmap"
+)
+
+func TestPartitioner_Dispatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		partitions int
+		keys       []string
+		count      int
+	}{
+		{name: "1. 单分区单分区键", partitions: 1, keys: []string{"a"}, count: 100},
+		{name: "2. 多分区单分区键", partitions: 4, keys: []string{"a"}, count: 100},
+		{name: "3. 多分区多分区键", partitions: 4, keys: []string{"a", "b", "c", "d", "e"}, count: 100},
+	}
+	for _, tt := range tests {
+		var lock sync.Mutex
+		got := map[string][]int{}
+		p := newPartitioner(tt.partitions, 10, func(req *Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			key := req.header["key"]
+			got[key] = append(got[key], req.form["index"].(int))
+		})
+		for i := 0; i < tt.count; i++ {
+			for _, key := range tt.keys {
+				req := &Request{
+					queue:  queue.NewQueue("queue", "/service"),
+					form:   map[string]interface{}{"index": i},
+					header: map[string]string{"key": key},
+				}
+				assert.Equal(t, true, p.Dispatch(key, req), tt.name)
+			}
+		}
+		p.Close()
+		for _, key := range tt.keys {
+			assert.Equal(t, tt.count, len(got[key]), tt.name, key)
+			for i, v := range got[key] {
+				assert.Equal(t, i, v, tt.name, fmt.Sprintf("%s顺序", key))
+			}
+		}
+	}
+}
+
+func TestPartitioner_Close(t *testing.T) {
+	p := newPartitioner(2, 1, func(req *Request) {})
+	p.Close()
+	assert.Equal(t, false, p.Dispatch("a", &Request{}), "关闭后不能再分配消息")
+	assert.Equal(t, true, p.index("a") == p.index("a"), "相同分区键分配到相同分区")
+}
+
+func TestPartitioner_CloseWhileDispatch(t *testing.T) {
+	for n := 0; n < 20; n++ {
+		var lock sync.Mutex
+		handled := 0
+		p := newPartitioner(2, 1, func(req *Request) {
+			lock.Lock()
+			defer lock.Unlock()
+			handled++
+		})
+		var wg sync.WaitGroup
+		var dispatched int32
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if p.Dispatch(fmt.Sprint(i, j), &Request{}) {
+						atomic.AddInt32(&dispatched, 1)
+					}
+				}
+			}(i)
+		}
+		p.Close()
+		wg.Wait()
+		assert.Equal(t, int(atomic.LoadInt32(&dispatched)), handled, "已分配的消息均已处理")
+	}
+}
+
+func TestPartitioner_LMQ(t *testing.T) {
+	name := "mqc:partition:lmq"
+	keys := []string{"a", "b", "c", "d", "e"}
+	count := 200
+	consumer, err := mq.NewMQC("lmq", "")
+	assert.Equal(t, nil, err, "创建本地队列消费者")
+	defer consumer.Close()
+
+	var lock sync.Mutex
+	got := map[string][]string{}
+	total := 0
+	s := &Processor{handlers: cmap.New(4), customer: consumer}
+	p := newPartitioner(4, 10, func(req *Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		key := req.header[context.XPartitionKey]
+		got[key] = append(got[key], req.form["__body__"].(string))
+		total++
+	})
+	assert.Equal(t, nil, s.consumeBy(queue.NewQueue(name, "/service"), 1, p), "订阅队列")
+
+	producer, _ := lmq.New()
+	for i := 0; i < count; i++ {
+		for _, key := range keys {
+			e := pkgs.NewEnvelope(fmt.Sprint(i), "text/plain")
+			e.Header[context.XPartitionKey] = key
+			assert.Equal(t, nil, producer.Push(name, e.Marshal()), "发送消息")
+		}
+	}
+	for i := 0; i < 100; i++ {
+		lock.Lock()
+		n := total
+		lock.Unlock()
+		if n == count*len(keys) {
+			break
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	s.unconsume(queue.NewQueue(name, "/service"))
+	for _, key := range keys {
+		assert.Equal(t, count, len(got[key]), "本地队列按分区键处理全部消息", key)
+		for i, v := range got[key] {
+			assert.Equal(t, fmt.Sprint(i), v, "本地队列相同分区键的消息按发送顺序处理", key)
+		}
+	}
+}
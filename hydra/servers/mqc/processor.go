@@ -2,14 +2,19 @@ package mqc
 
 import (
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/micro-plat/hydra/components/pkgs"
 	"github.com/micro-plat/hydra/components/queues/mq"
 	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/hydra/context"
+	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/adapter"
 	"github.com/micro-plat/hydra/hydra/servers/pkg/middleware"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
+	"github.com/micro-plat/lib4go/types"
 )
 
 const (
@@ -18,6 +23,12 @@ const (
 	running   = 4
 )
 
+//dispatcher 消息分发处理器,由分区处理器或自适应协程池实现
+type dispatcher interface {
+	Dispatch(key string, req *Request) bool
+	Close()
+}
+
 //Processor cron管理程序，用于管理多个任务的执行，暂停，恢复，动态添加，移除
 type Processor struct {
 	//*dispatcher.Engine
@@ -25,7 +36,13 @@ type Processor struct {
 	done      bool
 	closeChan chan struct{}
 	queues    cmap.ConcurrentMap
+	handlers  cmap.ConcurrentMap
 	metric    *middleware.Metric
+	monitor   *monitor
+	proto     string
+	raw       string
+	mqp       mq.IMQP
+	mqpLock   sync.Mutex
 	startTime time.Time
 	customer  mq.IMQC
 	status    int
@@ -39,13 +56,17 @@ func NewProcessor(proto string, confRaw string) (p *Processor, err error) {
 		closeChan: make(chan struct{}),
 		startTime: time.Now(),
 		queues:    cmap.New(4),
+		handlers:  cmap.New(4),
 		metric:    middleware.NewMetric(),
+		proto:     proto,
+		raw:       confRaw,
 	}
 
 	p.customer, err = mq.NewMQC(proto, confRaw)
 	if err != nil {
 		return nil, fmt.Errorf("构建mqc服务失败(proto:%s,raw:%s) %v", proto, confRaw, err)
 	}
+	p.monitor = newMonitor(p)
 	p.engine = adapter.NewDispatcherEngine(MQC)
 
 	p.engine.Use(middleware.Recovery(true))
@@ -73,6 +94,7 @@ func (s *Processor) Start(wait ...bool) error {
 	if err := s.customer.Connect(); err != nil {
 		return err
 	}
+	go s.monitor.Run()
 	if len(wait) > 0 && !wait[0] {
 		_, err := s.Resume()
 		return err
@@ -95,7 +117,7 @@ func (s *Processor) Add(queues ...*queue.Queue) error {
 //Remove 除移队列信息
 func (s *Processor) Remove(queues ...*queue.Queue) error {
 	for _, queue := range queues {
-		s.customer.UnConsume(queue.Queue)
+		s.unconsume(queue)
 		s.queues.Remove(queue.Queue)
 	}
 	return nil
@@ -110,7 +132,7 @@ func (s *Processor) Pause() (bool, error) {
 		items := s.queues.Items()
 		for _, v := range items {
 			queue := v.(*queue.Queue)
-			s.customer.UnConsume(queue.Queue) //取消服务订阅
+			s.unconsume(queue) //取消服务订阅
 		}
 		return true, nil
 	}
@@ -138,12 +160,72 @@ func (s *Processor) consume(queue *queue.Queue) error {
 	if !s.engine.Find(queue.Service) {
 		s.engine.Handle(DefMethod, queue.Service, middleware.ExecuteHandler())
 	}
+	if queue.Partitions > 0 {
+		return s.consumeByPartition(queue)
+	}
+	if queue.IsAdaptive() {
+		return s.consumeByScaler(queue)
+	}
 	if err := s.customer.Consume(queue.Queue, queue.Concurrency, s.handle(queue)); err != nil {
 		return err
 	}
 	return nil
 }
 
+//consumeByPartition 按分区消费,使用单协程拉取消息保证入队顺序,再按分区键分配给分区协程处理
+func (s *Processor) consumeByPartition(queue *queue.Queue) error {
+	p := newPartitioner(queue.Partitions, types.GetMax(queue.Concurrency, 10), s.execute)
+	return s.consumeBy(queue, 1, p)
+}
+
+//consumeByScaler 自适应并发消费,按最大并发数拉取消息,由协程池根据积压数调整处理协程数
+func (s *Processor) consumeByScaler(queue *queue.Queue) error {
+	sc := newScaler(queue.Min, queue.Max, s.execute)
+	if err := s.consumeBy(queue, queue.Max, sc); err != nil {
+		return err
+	}
+	go sc.Watch(func() (int64, error) {
+		return s.count(queue.Queue)
+	})
+	return nil
+}
+
+func (s *Processor) consumeBy(queue *queue.Queue, concurrency int, d dispatcher) error {
+	if ok, _ := s.handlers.SetIfAbsent(queue.Queue, d); !ok {
+		d.Close()
+		return nil
+	}
+	if err := s.customer.Consume(queue.Queue, concurrency, s.handleBy(queue, d)); err != nil {
+		s.handlers.Remove(queue.Queue)
+		d.Close()
+		return err
+	}
+	return nil
+}
+
+//unconsume 取消订阅并关闭分发处理器
+func (s *Processor) unconsume(queue *queue.Queue) {
+	s.customer.UnConsume(queue.Queue)
+	if d, ok := s.handlers.Get(queue.Queue); ok {
+		s.handlers.Remove(queue.Queue)
+		d.(dispatcher).Close()
+	}
+}
+
+//count 获取队列中未处理的消息数
+func (s *Processor) count(name string) (int64, error) {
+	s.mqpLock.Lock()
+	defer s.mqpLock.Unlock()
+	if s.mqp == nil {
+		mqp, err := mq.NewMQP(s.proto, s.raw)
+		if err != nil {
+			return 0, fmt.Errorf("创建队列积压采样客户端失败:%w", err)
+		}
+		s.mqp = mqp
+	}
+	return s.mqp.Count(name)
+}
+
 //Close 退出
 func (s *Processor) Close() {
 	defer s.metric.Stop()
@@ -152,8 +234,19 @@ func (s *Processor) Close() {
 	if !s.done {
 		s.done = true
 		close(s.closeChan)
+		s.monitor.Close()
 		s.queues.Clear()
 		s.customer.Close()
+		s.handlers.RemoveIterCb(func(key string, v interface{}) bool {
+			v.(dispatcher).Close()
+			return true
+		})
+		s.mqpLock.Lock()
+		if s.mqp != nil {
+			s.mqp.Close()
+			s.mqp = nil
+		}
+		s.mqpLock.Unlock()
 	}
 }
 
@@ -163,6 +256,38 @@ func (s *Processor) handle(queue *queue.Queue) func(mq.IMQCMessage) {
 		if err != nil {
 			panic(err)
 		}
-		s.engine.HandleRequest(req)
+		s.execute(req)
+	}
+}
+
+//execute 处理请求,处理成功后确认消息,失败时取消消息
+func (s *Processor) execute(req *Request) {
+	d := newDedup(req)
+	if d != nil && !d.Acquire() {
+		global.Def.Log().Warnf("消息已处理,忽略重复消息:%s(%s)", req.queue.Queue, req.header[pkgs.HeaderMessageID])
+		req.Ack()
+		return
+	}
+	w, err := s.engine.HandleRequest(req)
+	if err != nil || w.Status() >= http.StatusBadRequest {
+		if d != nil {
+			d.Release()
+		}
+		req.Nack()
+		return
+	}
+	req.Ack()
+	s.monitor.Done(req.queue.Queue)
+}
+
+func (s *Processor) handleBy(queue *queue.Queue, d dispatcher) func(mq.IMQCMessage) {
+	return func(m mq.IMQCMessage) {
+		req, err := NewRequest(queue, m)
+		if err != nil {
+			panic(err)
+		}
+		if !d.Dispatch(req.GetHeader()[context.XPartitionKey], req) {
+			m.Nack()
+		}
 	}
 }
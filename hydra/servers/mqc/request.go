@@ -2,9 +2,6 @@ package mqc
 
 import (
 	"encoding/json"
-	"fmt"
-
-	"github.com/micro-plat/lib4go/encoding/base64"
 
 	"github.com/micro-plat/hydra/components/pkgs"
 	"github.com/micro-plat/hydra/components/queues/mq"
@@ -64,29 +61,22 @@ func newRequest(queue *queue.Queue, m mq.IMQCMessage) (r *Request, err error) {
 		header:      make(map[string]string),
 	}
 
-	//将消息原串转换为map
-	input := make(map[string]interface{})
 	message := m.GetMessage()
-	json.Unmarshal(types.StringToBytes(message), &input)
-
-	//检查是否包含头信息
 	r.form["__body__"] = message
-	if v, ok := input["__header__"].(map[string]interface{}); ok {
-		for n, m := range v {
-			r.header[n] = fmt.Sprint(m)
+	r.header["__all__"] = message
+
+	//解析消息信封,获取头信息与消息内容
+	if e, err := pkgs.ParseEnvelope(message); err == nil {
+		for k, v := range e.Headers() {
+			r.header[k] = v
+		}
+		if e.Data != nil {
+			r.form["__body__"] = string(e.Data)
 		}
 	}
-
-	//处理头信息
-	r.header["__all__"] = message
 	if _, ok := r.header["Content-Type"]; !ok {
 		r.header["Content-Type"] = "application/json"
 	}
-
-	if v, ok := input["__data__"].(string); ok {
-		buff, _ := base64.DecodeBytes(v)
-		r.form["__body__"] = string(buff)
-	}
 	return r, nil
 }
 
@@ -0,0 +1,184 @@
+package mqc
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//defScaleInterval 自适应并发数的调整间隔
+const defScaleInterval = time.Second * 3
+
+//scaler 自适应处理协程池,根据队列积压数与平均处理时长在min与max之间调整协程数
+type scaler struct {
+	min     int
+	max     int
+	size    int32
+	target  int
+	ch      chan *Request
+	stopCh  chan struct{}
+	handle  func(*Request)
+	count   int64
+	latency int64
+	wg      sync.WaitGroup
+	lk      sync.Mutex
+	mu      sync.RWMutex
+	closed  bool
+	closeCh chan struct{}
+	log     logger.ILogger
+}
+
+//newScaler 构建自适应处理协程池,初始协程数为min
+func newScaler(min int, max int, handle func(*Request)) *scaler {
+	if min <= 0 {
+		min = 1
+	}
+	s := &scaler{
+		min:     min,
+		max:     max,
+		ch:      make(chan *Request, max),
+		stopCh:  make(chan struct{}),
+		handle:  handle,
+		closeCh: make(chan struct{}),
+		log:     logger.New("mqc.scaler"),
+	}
+	s.resize(min)
+	return s
+}
+
+//Dispatch 将请求放入处理队列,队列已满时阻塞等待,已关闭时返回false
+func (s *scaler) Dispatch(key string, req *Request) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+	s.ch <- req
+	return true
+}
+
+//Size 当前处理协程数
+func (s *scaler) Size() int {
+	return int(atomic.LoadInt32(&s.size))
+}
+
+//Adjust 根据积压数与上一周期的平均处理时长调整协程数,扩容立即生效,缩容每次最多减少四分之一
+func (s *scaler) Adjust(backlog int64, interval time.Duration) int {
+	count := atomic.SwapInt64(&s.count, 0)
+	latency := atomic.SwapInt64(&s.latency, 0)
+	current := s.getTarget()
+	desired := current
+	switch {
+	case backlog == 0 && count == 0:
+		desired = s.min
+	case count == 0:
+		desired = current * 2
+	default:
+		avg := float64(latency) / float64(count)
+		desired = int(math.Ceil(float64(backlog+count) * avg / float64(interval)))
+	}
+	if desired < current {
+		step := current / 4
+		if step < 1 {
+			step = 1
+		}
+		if current-desired > step {
+			desired = current - step
+		}
+	}
+	if desired < s.min {
+		desired = s.min
+	}
+	if desired > s.max {
+		desired = s.max
+	}
+	s.resize(desired)
+	return desired
+}
+
+//Close 关闭协程池,等待正在放入及已放入的请求处理完成
+func (s *scaler) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+	close(s.closeCh)
+	s.wg.Wait()
+}
+
+//getTarget 获取目标协程数,已通知退出的协程不再计入
+func (s *scaler) getTarget() int {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	return s.target
+}
+
+//resize 按目标协程数启动或通知退出处理协程,协程数由目标值同步记录,不依赖协程实际退出
+func (s *scaler) resize(n int) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for ; s.target < n; s.target++ {
+		atomic.AddInt32(&s.size, 1)
+		s.wg.Add(1)
+		go s.run()
+	}
+	for ; s.target > n; s.target-- {
+		select {
+		case s.stopCh <- struct{}{}:
+		case <-s.closeCh:
+			return
+		}
+	}
+}
+
+func (s *scaler) run() {
+	defer s.wg.Done()
+	defer atomic.AddInt32(&s.size, -1)
+	for {
+		select {
+		case req := <-s.ch:
+			s.execute(req)
+		case <-s.stopCh:
+			return
+		case <-s.closeCh:
+			for {
+				select {
+				case req := <-s.ch:
+					s.execute(req)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *scaler) execute(req *Request) {
+	start := time.Now()
+	s.handle(req)
+	atomic.AddInt64(&s.latency, int64(time.Since(start)))
+	atomic.AddInt64(&s.count, 1)
+}
+
+//Watch 定时获取队列积压数并调整协程数,直到协程池关闭
+func (s *scaler) Watch(backlog func() (int64, error)) {
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case <-time.After(defScaleInterval):
+			n, err := backlog()
+			if err != nil {
+				s.log.Errorf("获取队列积压数失败,暂不调整协程数:%v", err)
+				continue
+			}
+			s.Adjust(n, defScaleInterval)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package mqc
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/conf/server/queue"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestScaler_Adjust(t *testing.T) {
+	s := newScaler(2, 10, func(req *Request) {})
+	defer s.Close()
+	tests := []struct {
+		name    string
+		backlog int64
+		count   int64
+		latency time.Duration
+		want    int
+	}{
+		{name: "1. 无积压无处理时保持最小协程数", backlog: 0, count: 0, want: 2},
+		{name: "2. 有积压无处理时协程数翻倍", backlog: 100, count: 0, want: 4},
+		{name: "3. 按积压数与平均处理时长计算协程数", backlog: 290, count: 10, latency: time.Millisecond * 100, want: 10},
+		{name: "4. 计算值超过最大协程数", backlog: 10000, count: 10, latency: time.Second, want: 10},
+		{name: "5. 缩容每次最多减少四分之一", backlog: 0, count: 10, latency: time.Millisecond, want: 8},
+		{name: "6. 无积压无处理时按步长缩容", backlog: 0, count: 0, want: 6},
+		{name: "7. 持续无积压时继续按步长缩容", backlog: 0, count: 0, want: 5},
+		{name: "8. 持续无积压时继续按步长缩容", backlog: 0, count: 0, want: 4},
+		{name: "9. 持续无积压时继续按步长缩容", backlog: 0, count: 0, want: 3},
+		{name: "10. 持续无积压时缩容至最小协程数", backlog: 0, count: 0, want: 2},
+		{name: "11. 已达最小协程数时不再缩容", backlog: 0, count: 0, want: 2},
+	}
+	for _, tt := range tests {
+		atomic.StoreInt64(&s.count, tt.count)
+		atomic.StoreInt64(&s.latency, int64(tt.latency)*tt.count)
+		assert.Equal(t, tt.want, s.Adjust(tt.backlog, time.Second*3), tt.name)
+		for i := 0; i < 100 && s.Size() != tt.want; i++ {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equal(t, tt.want, s.Size(), tt.name)
+	}
+}
+
+func TestScaler_GrowAfterShrink(t *testing.T) {
+	s := newScaler(2, 10, func(req *Request) {})
+	defer s.Close()
+	atomic.StoreInt64(&s.count, 10)
+	atomic.StoreInt64(&s.latency, int64(time.Second)*10)
+	assert.Equal(t, 10, s.Adjust(10000, time.Second*3), "1. 有积压时扩容至最大协程数")
+
+	atomic.StoreInt64(&s.count, 10)
+	atomic.StoreInt64(&s.latency, int64(time.Millisecond)*10)
+	assert.Equal(t, 8, s.Adjust(0, time.Second*3), "2. 缩容每次最多减少四分之一")
+	assert.Equal(t, 8, s.getTarget(), "2. 缩容后目标协程数同步更新")
+
+	//缩容后立即扩容,退出中的协程不计入当前协程数
+	atomic.StoreInt64(&s.count, 10)
+	atomic.StoreInt64(&s.latency, int64(time.Second)*10)
+	assert.Equal(t, 10, s.Adjust(10000, time.Second*3), "3. 缩容后立即扩容")
+	for i := 0; i < 100 && s.Size() != 10; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(time.Millisecond * 10)
+	assert.Equal(t, 10, s.Size(), "3. 扩容后协程数达到目标值")
+}
+
+func TestScaler_Dispatch(t *testing.T) {
+	var n int32
+	s := newScaler(1, 4, func(req *Request) {
+		atomic.AddInt32(&n, 1)
+	})
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, true, s.Dispatch("", &Request{queue: queue.NewQueue("queue", "/service")}), "放入处理队列")
+	}
+	s.Close()
+	assert.Equal(t, int32(100), atomic.LoadInt32(&n), "关闭前放入的消息全部处理")
+	assert.Equal(t, false, s.Dispatch("", &Request{}), "关闭后不能再放入消息")
+	assert.Equal(t, 0, s.Size(), "关闭后协程全部退出")
+}
+
+func TestScaler_CloseWhileDispatch(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		var handled int32
+		s := newScaler(1, 2, func(req *Request) {
+			atomic.AddInt32(&handled, 1)
+		})
+		var wg sync.WaitGroup
+		var dispatched int32
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if s.Dispatch("", &Request{}) {
+						atomic.AddInt32(&dispatched, 1)
+					}
+				}
+			}()
+		}
+		s.Close()
+		wg.Wait()
+		assert.Equal(t, atomic.LoadInt32(&dispatched), atomic.LoadInt32(&handled), "已放入的消息均已处理")
+	}
+}
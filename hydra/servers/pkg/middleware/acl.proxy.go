@@ -6,39 +6,85 @@ import (
 	"net/http/httputil"
 	"strings"
 
+	"github.com/micro-plat/hydra/components/pkgs/metrics"
 	"github.com/micro-plat/hydra/conf/server/acl/proxy"
+	"github.com/micro-plat/hydra/global"
 )
 
 //Proxy 代理配置
 func Proxy() Handler {
 	return func(ctx IMiddleContext) {
-		proxy, err := ctx.APPConf().GetProxyConf()
+
+		//检查当前请求是否需要灰度
+		canary, err := ctx.APPConf().GetCanaryConf()
 		if err != nil {
 			ctx.Response().Abort(http.StatusNotExtended, err)
 			return
 		}
-		if proxy.Disable {
-			ctx.Next()
+		if !canary.Match(ctx.Request().Path().GetRequestPath()) {
+			checkProxy(ctx)
 			return
 		}
 
-		//检查当前请求是否需要进行代理
-		cluster, need, err := proxy.Check()
-		if err != nil {
-			ctx.Response().AddSpecial("proxy")
-			ctx.Response().Abort(http.StatusBadGateway, err)
+		//未分配到灰度集群时由当前集群处理
+		current := ctx.APPConf().GetServerConf().GetClusterName()
+		target := canary.Route(ctx.Request().GetHTTPRequest())
+		if target == "" || target == current {
+			checkProxy(ctx)
+			markCanary(ctx, current)
 			return
 		}
-		if !need {
-			ctx.Next()
-			return
+
+		//转到灰度集群
+		ctx.Response().AddSpecial("canary")
+		cluster, err := proxy.GetUpCluster(ctx.APPConf().GetServerConf(), target)
+		if err != nil {
+			ctx.Response().Abort(http.StatusBadGateway, err)
+		} else {
+			useProxy(ctx, cluster)
 		}
+		markCanary(ctx, target)
+	}
+}
 
-		//获取当前http信息
+//checkProxy 根据代理脚本检查当前请求是否需要转到上游集群
+func checkProxy(ctx IMiddleContext) {
+	proxy, err := ctx.APPConf().GetProxyConf()
+	if err != nil {
+		ctx.Response().Abort(http.StatusNotExtended, err)
+		return
+	}
+	if proxy.Disable {
+		ctx.Next()
+		return
+	}
+
+	//检查当前请求是否需要进行代理
+	cluster, need, err := proxy.Check()
+	if err != nil {
 		ctx.Response().AddSpecial("proxy")
-		useProxy(ctx, cluster)
+		ctx.Response().Abort(http.StatusBadGateway, err)
+		return
 	}
+	if !need {
+		ctx.Next()
+		return
+	}
+
+	//获取当前http信息
+	ctx.Response().AddSpecial("proxy")
+	useProxy(ctx, cluster)
 }
+
+//markCanary 按处理请求的集群及状态码统计灰度请求数
+func markCanary(ctx IMiddleContext, cluster string) {
+	statusCode, _, _ := ctx.Response().GetRawResponse()
+	name := metrics.MakeName(ctx.APPConf().GetServerConf().GetServerType()+".server.canary", metrics.METER,
+		"server", ctx.APPConf().GetServerConf().GetServerName(), "host", global.LocalIP(),
+		"cluster", cluster, "status", fmt.Sprintf("%d", statusCode))
+	metrics.GetOrRegisterMeter(name, metrics.DefaultRegistry).Mark(1)
+}
+
 func useProxy(ctx IMiddleContext, cluster *proxy.UpCluster) {
 
 	//检查当前请求
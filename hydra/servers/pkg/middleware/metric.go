@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/micro-plat/hydra/components/pkgs/metrics"
+	"github.com/micro-plat/hydra/conf/app"
 	"github.com/micro-plat/lib4go/logger"
 	"github.com/micro-plat/hydra/global"
 )
@@ -16,19 +17,24 @@ type Metric struct {
 	currentRegistry metrics.Registry
 	needCollect     bool
 	once            sync.Once
+	err             error
 	ip              string
 }
 
+//defReporter 上报metrics.DefaultRegistry中的组件统计(如数据库语句耗时),进程内只启动一次
+var defReporter sync.Once
+
 //NewMetric new metric
 func NewMetric() *Metric {
 	return &Metric{}
 
 }
-func (m *Metric) onceDo(ctx IMiddleContext) {
+func (m *Metric) onceDo(cnf app.IAPPConf) error {
 	m.once.Do(func() {
-		metric, err := ctx.APPConf().GetMetricConf()
+		metric, err := cnf.GetMetricConf()
 		if err != nil {
-			panic(fmt.Errorf("metric配置获取失败:%w", err))
+			m.err = fmt.Errorf("metric配置获取失败:%w", err)
+			return
 		}
 
 		if metric.Disable {
@@ -47,13 +53,30 @@ func (m *Metric) onceDo(ctx IMiddleContext) {
 			metric.UserName,
 			metric.Password, m.logger)
 		if err != nil {
-			panic(fmt.Errorf("初始化metric失败:%w", err))
+			m.err = fmt.Errorf("初始化metric失败:%w", err)
+			return
 		}
 		m.needCollect = true
 		//定时上报
 		go m.reporter.Run()
 
+		//3. 上报组件统计信息
+		defReporter.Do(func() {
+			reporter, err := metrics.InfluxDB(metrics.DefaultRegistry,
+				metric.Cron,
+				metric.Host,
+				metric.DataBase,
+				metric.UserName,
+				metric.Password, m.logger)
+			if err != nil {
+				m.logger.Errorf("初始化组件metric失败:%v", err)
+				return
+			}
+			go reporter.Run()
+		})
+
 	})
+	return m.err
 }
 
 //Handle 处理请求
@@ -61,7 +84,9 @@ func (m *Metric) Handle() Handler {
 	return func(ctx IMiddleContext) {
 
 		//执行首次初始化
-		m.onceDo(ctx)
+		if err := m.onceDo(ctx.APPConf()); err != nil {
+			panic(err)
+		}
 		if !m.needCollect {
 			ctx.Next()
 			return
@@ -100,6 +125,14 @@ func (m *Metric) Handle() Handler {
 
 }
 
+//GetRegistry 获取统计注册器,未启用统计时返回false
+func (m *Metric) GetRegistry(cnf app.IAPPConf) (metrics.Registry, bool, error) {
+	if err := m.onceDo(cnf); err != nil {
+		return nil, false, err
+	}
+	return m.currentRegistry, m.needCollect, nil
+}
+
 //Stop stop metric
 func (m *Metric) Stop() {
 	if m.reporter != nil {
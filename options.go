@@ -53,6 +53,13 @@ func WithClusterName(clusterName string) Option {
 	}
 }
 
+//WithEnv 设置运行环境,发布配置时合并对应环境的覆盖配置
+func WithEnv(env string) Option {
+	return func() {
+		global.Def.Env = env
+	}
+}
+
 //WithDebug 设置dubug模式
 func WithDebug() Option {
 	return func() {
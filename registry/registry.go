@@ -2,6 +2,7 @@ package registry
 
 import (
 	"fmt"
+	"net/url"
 
 	"strings"
 
@@ -134,8 +135,8 @@ func GetAddrs(addr string) []string {
 	return addrs
 }
 
-//Parse 解析地址
-//如:zk://192.168.0.155:2181 或 fs://../
+//Parse 解析地址,?后的参数作为元数据
+//如:zk://192.168.0.155:2181 或 fs://../ 或 redis://192.168.0.1:26379,192.168.0.2:26379?master=mymaster&db=1
 func Parse(address string) (proto string, raddr []string, u string, p string, mt map[string]string, err error) {
 	if strings.Count(address, "://") != 1 {
 		return "", nil, "", "", nil, fmt.Errorf("%s，包含多个://。格式:[proto]://[address]", address)
@@ -152,6 +153,12 @@ func Parse(address string) (proto string, raddr []string, u string, p string, mt
 		return "", nil, "", "", nil, fmt.Errorf("%s，地址不能为空。格式:[proto]://[address]", address)
 	}
 	proto = addr[0]
+	if i := strings.Index(addr[1], "?"); i >= 0 {
+		if mt, err = parseQuery(addr[1][i+1:]); err != nil {
+			return "", nil, "", "", nil, fmt.Errorf("%s，参数格式有误:%v", address, err)
+		}
+		addr[1] = addr[1][:i]
+	}
 	raddr = strings.Split(addr[1], ",")
 	var addr0 string
 	u, p, addr0, err = getAddrByUserPass(raddr[0])
@@ -216,3 +223,15 @@ func Close() {
 		return true
 	})
 }
+
+func parseQuery(query string) (map[string]string, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	mt := make(map[string]string, len(values))
+	for k := range values {
+		mt[k] = values.Get(k)
+	}
+	return mt, nil
+}
@@ -38,8 +38,11 @@ type fs struct {
 	valueWatcherMaps    map[string]*fsValueWatcher
 	childrenWatcherMaps map[string]*fsChildrenWatcher
 	watchLock           sync.Mutex
-	tempNodes           map[string]bool
+	tempNodes           map[string]string
 	tempNodeLock        sync.Mutex
+	session             *session
+	tmpExpiration       time.Duration
+	checkTicker         time.Duration
 	closeCh             chan struct{}
 	rootDir             string
 	done                bool
@@ -61,14 +64,17 @@ func NewFileSystem(rootDir string) (*fs, error) {
 		watcher:             w,
 		valueWatcherMaps:    make(map[string]*fsValueWatcher),
 		childrenWatcherMaps: make(map[string]*fsChildrenWatcher),
-		tempNodes:           make(map[string]bool),
+		tempNodes:           make(map[string]string),
+		tmpExpiration:       time.Second * 10,
+		checkTicker:         time.Second * 2,
 		closeCh:             make(chan struct{}),
 	}
 	return registryfs, nil
 }
 
-//Start 启动文件监控
+//Start 启动文件监控及临时节点的心跳检查
 func (l *fs) Start() {
+	go l.keepalive()
 	go func() {
 		for {
 			select {
@@ -191,7 +197,7 @@ func (l *fs) GetChildren(path string) (paths []string, version int32, err error)
 	}
 	paths = make([]string, 0, len(children))
 	for _, f := range children {
-		if strings.HasSuffix(f.Name(), ".swp") || strings.HasPrefix(f.Name(), "~") || strings.HasPrefix(f.Name(), ".init") {
+		if strings.HasSuffix(f.Name(), ".swp") || strings.HasPrefix(f.Name(), "~") || strings.HasPrefix(f.Name(), ".") {
 			continue
 		}
 		paths = append(paths, l.restoreColon(f.Name()))
@@ -337,7 +343,20 @@ func (l *fs) WatchChildren(path string) (data chan registry.ChildrenWatcher, err
 }
 
 func (l *fs) Delete(path string) error {
-	return os.RemoveAll(l.replaceColon(l.formatPath(path)))
+	realPath := l.replaceColon(l.formatPath(path))
+	l.tempNodeLock.Lock()
+	defer l.tempNodeLock.Unlock()
+	changed := false
+	for path := range l.tempNodes {
+		if path == realPath || strings.HasPrefix(path, realPath+"/") {
+			delete(l.tempNodes, path)
+			changed = true
+		}
+	}
+	if changed {
+		l.saveTempNodes()
+	}
+	return os.RemoveAll(realPath)
 }
 
 func (l *fs) CreatePersistentNode(path string, data string) (err error) {
@@ -368,19 +387,20 @@ func (l *fs) createNodeData(path, data string) error {
 	return ioutil.WriteFile(dataPath, []byte(data), fileMode)
 }
 
+//CreateTempNode 创建临时节点,节点绑定到当前进程的会话,进程退出后由其它进程清理
 func (l *fs) CreateTempNode(path string, data string) (err error) {
-	path = l.replaceColon(path)
-	if err = l.CreatePersistentNode(path, data); err != nil {
-		return err
-	}
 	l.tempNodeLock.Lock()
 	defer l.tempNodeLock.Unlock()
-	l.tempNodes[l.formatPath(path)] = true
-	return nil
+	return l.createTempNode(l.replaceColon(path), data)
 }
+
+//CreateSeqNode 创建序列节点,序列号在父节点下多进程间唯一且递增
 func (l *fs) CreateSeqNode(path string, data string) (rpath string, err error) {
-	nid := time.Now().UnixNano()
-	rpath = fmt.Sprintf("%s_%d", path, nid)
+	nid, err := l.nextSeq(filepath.Dir(l.replaceColon(l.formatPath(path))))
+	if err != nil {
+		return "", err
+	}
+	rpath = fmt.Sprintf("%s_%010d", path, nid)
 	return rpath, l.CreateTempNode(rpath, data)
 }
 
@@ -399,6 +419,12 @@ func (l *fs) Close() error {
 	for path := range l.tempNodes {
 		os.RemoveAll(path)
 	}
+	if l.session != nil {
+		os.Remove(filepath.Join(l.rootDir, sessionDir, l.session.id+".nodes"))
+		os.Remove(l.session.file.Name())
+		unlockFile(l.session.file)
+		l.session.file.Close()
+	}
 	return nil
 }
 
@@ -0,0 +1,27 @@
+// +build !windows
+
+package filesystem
+
+import (
+	"os"
+	"syscall"
+)
+
+//lockFile 获取文件的排它锁,进程退出时由系统释放
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+//tryLockFile 尝试获取文件的排它锁,文件已被其它进程锁定时返回false
+func tryLockFile(f *os.File) (bool, error) {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//unlockFile 释放文件锁
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,37 @@
+// +build windows
+
+package filesystem
+
+import (
+	"os"
+	"time"
+)
+
+//staleLock 锁目录超过该时长未释放时视为持有者已异常退出
+const staleLock = time.Second * 10
+
+//lockFile 通过创建锁目录获取排它锁
+func lockFile(f *os.File) error {
+	lock := f.Name() + ".lock"
+	for {
+		err := os.Mkdir(lock, dirMode)
+		if err == nil || !os.IsExist(err) {
+			return err
+		}
+		if fi, err := os.Stat(lock); err == nil && time.Since(fi.ModTime()) > staleLock {
+			os.Remove(lock)
+			continue
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+//tryLockFile 不支持检查其它进程持有的锁,进程存活状态只根据心跳时间判断
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+//unlockFile 删除锁目录
+func unlockFile(f *os.File) error {
+	return os.Remove(f.Name() + ".lock")
+}
@@ -0,0 +1,233 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro-plat/lib4go/utility"
+)
+
+//sessionDir 会话目录,保存各进程的会话文件及临时节点列表
+const sessionDir = ".sessions"
+
+//sessionFile 临时节点目录中记录所属会话的文件
+const sessionFile = ".session"
+
+//seqFile 父节点目录中保存序列号的文件
+const seqFile = ".seq"
+
+//session 进程会话,会话文件在进程存活期间保持文件锁并定时更新修改时间(心跳),
+//进程异常退出后文件锁释放且心跳超时,由其它进程清理该会话创建的临时节点
+type session struct {
+	id   string
+	file *os.File
+}
+
+//getSession 获取当前进程的会话,首次创建临时节点时创建,调用前须获取tempNodeLock
+func (l *fs) getSession() (*session, error) {
+	if l.session != nil {
+		return l.session, nil
+	}
+	dir := filepath.Join(l.rootDir, sessionDir)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return nil, fmt.Errorf("创建会话目录失败:%w", err)
+	}
+	id := fmt.Sprintf("%d_%s", os.Getpid(), utility.GetGUID()[:8])
+	f, err := os.OpenFile(filepath.Join(dir, id+".session"), os.O_CREATE|os.O_RDWR, fileMode)
+	if err != nil {
+		return nil, fmt.Errorf("创建会话文件失败:%w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("锁定会话文件失败:%w", err)
+	}
+	l.session = &session{id: id, file: f}
+	return l.session, nil
+}
+
+//createTempNode 创建临时节点并记录到会话,调用前须获取tempNodeLock
+func (l *fs) createTempNode(path string, data string) error {
+	s, err := l.getSession()
+	if err != nil {
+		return err
+	}
+	if err := l.CreatePersistentNode(path, data); err != nil {
+		return err
+	}
+	realPath := l.formatPath(path)
+	if err := ioutil.WriteFile(filepath.Join(realPath, sessionFile), []byte(s.id), fileMode); err != nil {
+		return err
+	}
+	l.tempNodes[realPath] = data
+	return l.saveTempNodes()
+}
+
+//saveTempNodes 保存当前会话的临时节点列表,调用前须获取tempNodeLock
+func (l *fs) saveTempNodes() error {
+	if l.session == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(l.tempNodes))
+	for path := range l.tempNodes {
+		paths = append(paths, path)
+	}
+	buff, err := json.Marshal(paths)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(l.rootDir, sessionDir, l.session.id+".nodes")
+	if err := ioutil.WriteFile(file+".tmp", buff, fileMode); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+//keepalive 定时更新会话心跳,检查当前进程的临时节点,并清理已退出进程的临时节点
+func (l *fs) keepalive() {
+	tk := time.NewTicker(l.checkTicker)
+	defer tk.Stop()
+	for {
+		select {
+		case <-l.closeCh:
+			return
+		case <-tk.C:
+			l.heartbeat()
+			l.reap()
+		}
+	}
+}
+
+//heartbeat 更新会话文件的修改时间,临时节点已被其它进程删除时不再维护;
+//当前会话已过期被清理时使用新会话重新创建仍未被其它会话占用的临时节点
+func (l *fs) heartbeat() {
+	l.tempNodeLock.Lock()
+	defer l.tempNodeLock.Unlock()
+	if l.session == nil || l.done {
+		return
+	}
+	if _, err := os.Stat(l.session.file.Name()); os.IsNotExist(err) {
+		l.renewSession()
+		return
+	}
+	now := time.Now()
+	os.Chtimes(l.session.file.Name(), now, now)
+	changed := false
+	for path := range l.tempNodes {
+		if l.nodeOwner(path) != l.session.id {
+			delete(l.tempNodes, path)
+			changed = true
+		}
+	}
+	if changed {
+		l.saveTempNodes()
+	}
+}
+
+//renewSession 当前会话已过期,创建新会话并重新创建已被清理的临时节点,调用前须获取tempNodeLock
+func (l *fs) renewSession() {
+	id := l.session.id
+	unlockFile(l.session.file)
+	l.session.file.Close()
+	l.session = nil
+	nodes := l.tempNodes
+	l.tempNodes = make(map[string]string)
+	for path, data := range nodes {
+		if owner := l.nodeOwner(path); owner != "" && owner != id {
+			continue
+		}
+		l.createTempNode(path, data)
+	}
+}
+
+//nodeOwner 获取临时节点所属的会话,节点不存在时返回空
+func (l *fs) nodeOwner(path string) string {
+	id, err := ioutil.ReadFile(filepath.Join(path, sessionFile))
+	if err != nil {
+		return ""
+	}
+	return string(id)
+}
+
+//reap 清理已退出进程的会话:会话文件未被锁定且心跳超时,只删除仍属于该会话的临时节点
+func (l *fs) reap() {
+	dir := filepath.Join(l.rootDir, sessionDir)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range files {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".session") {
+			continue
+		}
+		id := strings.TrimSuffix(fi.Name(), ".session")
+		if l.session != nil && id == l.session.id {
+			continue
+		}
+		if time.Since(fi.ModTime()) <= l.tmpExpiration {
+			continue
+		}
+		l.reapSession(dir, id)
+	}
+}
+
+func (l *fs) reapSession(dir string, id string) {
+	name := filepath.Join(dir, id+".session")
+	f, err := os.OpenFile(name, os.O_RDWR, fileMode)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	//会话文件仍被锁定,进程存活
+	if ok, err := tryLockFile(f); err != nil || !ok {
+		return
+	}
+	nodes := filepath.Join(dir, id+".nodes")
+	if buff, err := ioutil.ReadFile(nodes); err == nil {
+		paths := make([]string, 0, 1)
+		json.Unmarshal(buff, &paths)
+		for _, path := range paths {
+			if l.nodeOwner(path) == id {
+				os.RemoveAll(path)
+			}
+		}
+	}
+	os.Remove(nodes)
+	os.Remove(name)
+	os.Remove(name + ".lock")
+}
+
+//nextSeq 获取父节点的下一个序列号,通过文件锁保证多个进程获取的序列号唯一且递增
+func (l *fs) nextSeq(parent string) (int64, error) {
+	if err := os.MkdirAll(parent, dirMode); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(filepath.Join(parent, seqFile), os.O_CREATE|os.O_RDWR, fileMode)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return 0, fmt.Errorf("锁定序列号文件失败:%w", err)
+	}
+	defer unlockFile(f)
+	buff, err := ioutil.ReadAll(f)
+	if err != nil {
+		return 0, err
+	}
+	seq, _ := strconv.ParseInt(strings.TrimSpace(string(buff)), 10, 64)
+	seq++
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := f.WriteAt([]byte(strconv.FormatInt(seq, 10)), 0); err != nil {
+		return 0, err
+	}
+	return seq, f.Sync()
+}
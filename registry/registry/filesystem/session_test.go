@@ -0,0 +1,126 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func newTestFS(t *testing.T, root string) *fs {
+	l, err := NewFileSystem(root)
+	assert.Equal(t, nil, err, "创建注册中心")
+	return l
+}
+
+//expire 模拟进程异常退出:释放会话文件锁且心跳超时
+func expire(t *testing.T, l *fs) {
+	name := l.session.file.Name()
+	unlockFile(l.session.file)
+	l.session.file.Close()
+	old := time.Now().Add(-time.Minute)
+	assert.Equal(t, nil, os.Chtimes(name, old, old), "会话心跳超时")
+}
+
+func TestFS_Reap(t *testing.T) {
+	root := t.TempDir()
+	a := newTestFS(t, root)
+	b := newTestFS(t, root)
+	defer b.Close()
+
+	assert.Equal(t, nil, a.CreateTempNode("/reap/node", "1"), "1. 创建临时节点")
+	assert.Equal(t, nil, b.CreateTempNode("/reap/alive", "1"), "1. 创建临时节点")
+	b.reap()
+	ok, _ := b.Exists("/reap/node")
+	assert.Equal(t, true, ok, "1. 会话存活时不清理临时节点")
+
+	expire(t, a)
+	b.reap()
+	ok, _ = b.Exists("/reap/node")
+	assert.Equal(t, false, ok, "2. 清理已退出进程的临时节点")
+	ok, _ = b.Exists("/reap/alive")
+	assert.Equal(t, true, ok, "2. 不清理存活会话的临时节点")
+
+	a.heartbeat()
+	ok, _ = a.Exists("/reap/node")
+	assert.Equal(t, true, ok, "3. 会话过期后使用新会话重新创建临时节点")
+	b.reap()
+	ok, _ = b.Exists("/reap/node")
+	assert.Equal(t, true, ok, "3. 新会话的临时节点不被清理")
+	a.Close()
+	ok, _ = b.Exists("/reap/node")
+	assert.Equal(t, false, ok, "4. 关闭时删除临时节点")
+}
+
+func TestFS_Delete(t *testing.T) {
+	root := t.TempDir()
+	a := newTestFS(t, root)
+	defer a.Close()
+	b := newTestFS(t, root)
+	defer b.Close()
+
+	assert.Equal(t, nil, a.CreateTempNode("/delete/lock", "1"), "1. 创建临时节点")
+	assert.Equal(t, nil, b.Delete("/delete/lock"), "1. 其它进程删除临时节点")
+	a.heartbeat()
+	ok, _ := a.Exists("/delete/lock")
+	assert.Equal(t, false, ok, "1. 其它进程删除的临时节点不重新创建")
+	_, ok = a.tempNodes[a.formatPath("/delete/lock")]
+	assert.Equal(t, false, ok, "1. 其它进程删除的临时节点不再维护")
+
+	assert.Equal(t, nil, a.CreateTempNode("/delete/lock", "1"), "2. 重新创建临时节点")
+	assert.Equal(t, nil, b.Delete("/delete/lock"), "2. 其它进程删除临时节点")
+	assert.Equal(t, nil, b.CreateTempNode("/delete/lock", "2"), "2. 其它进程创建同名临时节点")
+	a.heartbeat()
+	data, _, _ := a.GetValue("/delete/lock")
+	assert.Equal(t, "2", string(data), "2. 不覆盖其它进程的临时节点")
+	_, ok = a.tempNodes[a.formatPath("/delete/lock")]
+	assert.Equal(t, false, ok, "2. 其它进程的临时节点不再维护")
+
+	assert.Equal(t, nil, a.CreateTempNode("/delete/parent/c1", "1"), "3. 创建子节点")
+	assert.Equal(t, nil, a.CreateTempNode("/delete/parent/c2", "1"), "3. 创建子节点")
+	assert.Equal(t, nil, a.CreateTempNode("/delete/parent2", "1"), "3. 创建同前缀节点")
+	assert.Equal(t, nil, a.Delete("/delete/parent"), "3. 删除父节点")
+	assert.Equal(t, 1, len(a.tempNodes), "3. 删除父节点时移除子临时节点")
+	_, ok = a.tempNodes[a.formatPath("/delete/parent2")]
+	assert.Equal(t, true, ok, "3. 不移除同前缀的其它节点")
+}
+
+func TestFS_SeqNode(t *testing.T) {
+	root := t.TempDir()
+	n := 4
+	count := 20
+	clients := make([]*fs, n)
+	for i := range clients {
+		clients[i] = newTestFS(t, root)
+		defer clients[i].Close()
+	}
+
+	var lk sync.Mutex
+	paths := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *fs) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				path, err := c.CreateSeqNode("/seq/node", "1")
+				assert.Equal(t, nil, err, "1. 创建序列节点")
+				lk.Lock()
+				paths[path] = true
+				lk.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+	assert.Equal(t, n*count, len(paths), "1. 多进程创建的序列号唯一")
+	for i := 1; i <= n*count; i++ {
+		assert.Equal(t, true, paths[fmt.Sprintf("/seq/node_%010d", i)], "2. 序列号连续递增")
+	}
+	children, _, err := clients[0].GetChildren(filepath.Join("/", "seq"))
+	assert.Equal(t, nil, err, "3. 获取子节点")
+	assert.Equal(t, n*count, len(children), "3. 序列文件不作为子节点")
+}
@@ -3,32 +3,29 @@ package redis
 import (
 	"fmt"
 
+	"github.com/micro-plat/hydra/global"
 	"github.com/micro-plat/hydra/registry/registry/redis/internal"
 )
 
 //CreatePersistentNode 创建永久节点
 func (r *Redis) CreatePersistentNode(path string, data string) (err error) {
 	key := internal.SwapKey(path)
-	value := newValue(data, false)
+	version, err := r.nextVersion()
+	if err != nil {
+		return err
+	}
+	value := newValue(data, "", version)
 	_, err = r.client.Set(key, value.String(), r.maxExpiration).Result()
 	if err != nil {
 		return err
 	}
-	r.notifyParentChange(key, value.Version)
-	return nil
+	return r.notify(key, version)
 }
 
-//CreateTempNode 创建临时节点
+//CreateTempNode 创建临时节点,节点绑定到当前实例的租约
 func (r *Redis) CreateTempNode(path string, data string) (err error) {
-	key := internal.SwapKey(path)
-	value := newValue(data, true)
-	_, err = r.client.Set(key, value.String(), r.tmpExpiration).Result()
-	if err != nil {
-		return err
-	}
-	r.tmpNodes.Set(key, 0)
-	r.notifyParentChange(key, value.Version)
-	return nil
+	_, err = r.setTemp(internal.SwapKey(path), data)
+	return err
 }
 
 //CreateSeqNode 创建序列节点
@@ -39,13 +36,9 @@ func (r *Redis) CreateSeqNode(path string, data string) (rpath string, err error
 		return "", err
 	}
 	key := fmt.Sprintf("%s_%d", internal.SwapKey(path), nid)
-	value := newValue(data, true)
-	_, err = r.client.Set(key, value.String(), r.tmpExpiration).Result()
-	if err != nil {
+	if _, err = r.setTemp(key, data); err != nil {
 		return internal.SwapPath(key), err
 	}
-	r.tmpNodes.Set(key, 0)
-	r.notifyParentChange(key, value.Version)
 	return internal.SwapPath(key), nil
 }
 
@@ -63,3 +56,8 @@ func (r *Redis) getSeq() (int64, error) {
 	return nid, nil
 
 }
+
+//Incr 将路径对应的计数器加1并返回新值,计数器不回绕,用于生成单调递增的编号
+func (r *Redis) Incr(path string) (int64, error) {
+	return r.client.Incr(internal.SwapKey(fmt.Sprintf("hydra/%s", global.Version), "counter", path)).Result()
+}
@@ -2,24 +2,22 @@ package redis
 
 import (
 	"encoding/json"
-	"sync/atomic"
-	"time"
 )
 
 type value struct {
 	IsTemp  bool   `json:"is_temp"`
+	Lease   string `json:"lease,omitempty"`
 	Data    []byte `json:"data"`
-	Version int32  `json:"version"`
+	Version int64  `json:"version"`
 }
 
-var s20200101, _ = time.Parse("20060102", "20200101")
-var start int32 = 100
-
-func newValue(data string, tmp bool) *value {
+//newValue 构建节点值,lease不为空时为绑定到租约的临时节点
+func newValue(data string, lease string, version int64) *value {
 	return &value{
 		Data:    []byte(data),
-		IsTemp:  tmp,
-		Version: int32(time.Now().Unix()-s20200101.Unix()) + atomic.AddInt32(&start, 1),
+		IsTemp:  lease != "",
+		Lease:   lease,
+		Version: version,
 	}
 }
 func newValueByJSON(d string) (*value, error) {
@@ -24,7 +24,7 @@ func (r *Redis) GetValue(path string) (data []byte, version int32, err error) {
 	if err != nil {
 		return nil, 0, err
 	}
-	return []byte(value.Data), value.Version, nil
+	return []byte(value.Data), int32(value.Version), nil
 }
 
 //GetChildren 获取所有子节点
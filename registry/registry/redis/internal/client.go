@@ -3,6 +3,8 @@ package internal
 import (
 	"encoding/json"
 	"errors"
+	"sync"
+	"sync/atomic"
 
 	//	"fmt"
 	"time"
@@ -30,8 +32,21 @@ type ClientConf struct {
 	RTimeout    int      `json:"read_timeout"`
 	WTimeout    int      `json:"write_timeout"`
 	PoolSize    int      `json:"pool_size"`
+	Mode        string   `json:"mode"`
 }
 
+//部署模式
+const (
+	//ModeSingle 单机
+	ModeSingle = "single"
+
+	//ModeSentinel 哨兵,Address为哨兵地址
+	ModeSentinel = "sentinel"
+
+	//ModeCluster 集群
+	ModeCluster = "cluster"
+)
+
 //Client redis client
 type Client struct {
 	redis.UniversalClient
@@ -68,6 +83,13 @@ func WithDialTimeout(timeout int) ClientOption {
 	}
 }
 
+//WithMode 设置部署模式(single,sentinel,cluster),未设置时根据MasterName与地址数自动判断
+func WithMode(mode string) ClientOption {
+	return func(o *ClientConf) {
+		o.Mode = mode
+	}
+}
+
 //WithRTimeout 设置读写超时时长
 func WithRTimeout(timeout int) ClientOption {
 	return func(o *ClientConf) {
@@ -120,12 +142,43 @@ func NewClientByConf(conf *ClientConf) (client *Client, err error) {
 		WriteTimeout: time.Duration(conf.WTimeout) * time.Second,
 		PoolSize:     conf.PoolSize,
 	}
-
-	client.UniversalClient = redis.NewUniversalClient(opts)
+	switch conf.Mode {
+	case ModeSentinel:
+		if conf.MasterName == "" {
+			return nil, errors.New("redis哨兵模式必须指定master")
+		}
+	case ModeCluster:
+		client.UniversalClient = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        opts.Addrs,
+			Password:     opts.Password,
+			DialTimeout:  opts.DialTimeout,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+			PoolSize:     opts.PoolSize,
+		})
+	case ModeSingle:
+		opts.MasterName = ""
+		if len(opts.Addrs) > 1 {
+			opts.Addrs = opts.Addrs[:1]
+		}
+	}
+	if client.UniversalClient == nil {
+		client.UniversalClient = redis.NewUniversalClient(opts)
+	}
 	_, err = client.UniversalClient.Ping().Result()
 	return
 }
 
+//forEachNode 对每个节点执行操作,集群模式时遍历所有主节点
+func (c *Client) forEachNode(fn func(client redis.Cmdable) error) error {
+	if cluster, ok := c.UniversalClient.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(func(client *redis.Client) error {
+			return fn(client)
+		})
+	}
+	return fn(c.UniversalClient)
+}
+
 //HasConnectionError 是否包含连接错误
 func HasConnectionError(err error) bool {
 	str := err.Error()
@@ -137,54 +190,49 @@ func HasConnectionError(err error) bool {
 
 //ExistsChildren ExistsChildren
 func (c *Client) ExistsChildren(path string) (exists bool, err error) {
-
-	defer func() {
-		//fmt.Println("ExistsChildren:", path,exists)
-
-	}()
-
-	cur := uint64(0)
-	for {
-		var keys []string
-		scanCmd := c.Scan(cur, path, 50)
-		keys, cur, err = scanCmd.Result()
-		if err != nil {
-			return
+	var found int32
+	err = c.forEachNode(func(node redis.Cmdable) error {
+		cur := uint64(0)
+		for {
+			keys, next, err := node.Scan(cur, path, 50).Result()
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				atomic.StoreInt32(&found, 1)
+				return nil
+			}
+			if cur = next; cur <= 0 || atomic.LoadInt32(&found) == 1 {
+				return nil
+			}
 		}
-		if cur <= 0 {
-			break
-		}
-		if len(keys) > 0 {
-			exists = true
-			break
-		}
-	}
-	return
+	})
+	return atomic.LoadInt32(&found) == 1, err
 }
 
 //SearchChildren 根据路径查找
 func (c *Client) SearchChildren(path string) (children []string, err error) {
-	defer func() {
-		//	fmt.Println("SearchChildren:", path,children,err)
-
-	}()
-
-	cur := uint64(0)
+	var lock sync.Mutex
 	children = []string{}
-	for {
-		var keys []string
-		scanCmd := c.Scan(cur, path, 50)
-		keys, cur, err = scanCmd.Result()
-		if err != nil {
-			return
-		}
-		if len(keys) > 0 {
-			children = append(children, keys...)
-		}
-		if cur <= 0 {
-			break
+	err = c.forEachNode(func(node redis.Cmdable) error {
+		cur := uint64(0)
+		for {
+			keys, next, err := node.Scan(cur, path, 50).Result()
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				lock.Lock()
+				children = append(children, keys...)
+				lock.Unlock()
+			}
+			if cur = next; cur <= 0 {
+				return nil
+			}
 		}
-
+	})
+	if err != nil {
+		return nil, err
 	}
 	return children, nil
 }
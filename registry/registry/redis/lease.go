@@ -0,0 +1,127 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/registry/registry/redis/internal"
+)
+
+//keepalive 定时续期当前实例的租约,并清理其它实例已过期的租约
+func (r *Redis) keepalive() {
+	tk := time.NewTicker(r.checkTicker)
+	defer tk.Stop()
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		case <-tk.C:
+			r.renew()
+			r.reap()
+		}
+	}
+}
+
+//renew 续期租约及所有临时节点。当前租约已过期或已被清理时重新创建不存在的临时节点;
+//租约未过期时节点不存在说明已被其它客户端删除,不再续期也不重新创建
+func (r *Redis) renew() error {
+	now := time.Now()
+	score, err := r.client.ZScore(r.leasesPath, r.leaseID).Result()
+	if err != nil && err != internal.Nil {
+		return fmt.Errorf("获取租约失败:%w", err)
+	}
+	expired := err == internal.Nil || int64(score) < now.UnixNano()/int64(time.Millisecond)
+	expireAt := now.Add(r.tmpExpiration).UnixNano() / int64(time.Millisecond)
+	if _, err := r.client.ZAdd(r.leasesPath, rds.Z{Score: float64(expireAt), Member: r.leaseID}).Result(); err != nil {
+		return fmt.Errorf("续期租约失败:%w", err)
+	}
+	for key, data := range r.tmpNodes.Items() {
+		ok, err := r.client.PExpire(key, r.tmpExpiration).Result()
+		if err != nil || ok {
+			continue
+		}
+		if !expired {
+			r.tmpNodes.Remove(key)
+			r.client.SRem(r.getLeasePath(r.leaseID), key)
+			continue
+		}
+		if _, err := r.setTemp(key, data.(string)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//reap 清理过期租约的临时节点并发送删除通知,多个实例同时清理时只有移除租约成功的实例执行清理
+func (r *Redis) reap() {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	ids, err := r.client.ZRangeByScore(r.leasesPath, rds.ZRangeBy{Min: "-inf", Max: fmt.Sprint(now)}).Result()
+	if err != nil {
+		return
+	}
+	for _, id := range ids {
+		if id == r.leaseID {
+			continue
+		}
+		if n, err := r.client.ZRem(r.leasesPath, id).Result(); err != nil || n == 0 {
+			continue
+		}
+		leasePath := r.getLeasePath(id)
+		keys, err := r.client.SMembers(leasePath).Result()
+		if err != nil {
+			continue
+		}
+		for _, key := range keys {
+			v, err := r.getValue(key)
+			if err != nil || (v != nil && v.Lease != id) {
+				continue
+			}
+			if v != nil {
+				r.client.Del(key)
+			}
+			if version, err := r.nextVersion(); err == nil {
+				r.notify(key, version)
+			}
+		}
+		r.client.Del(leasePath)
+	}
+}
+
+//setTemp 创建绑定到当前租约的临时节点
+func (r *Redis) setTemp(key string, data string) (*value, error) {
+	version, err := r.nextVersion()
+	if err != nil {
+		return nil, err
+	}
+	value := newValue(data, r.leaseID, version)
+	if _, err = r.client.Set(key, value.String(), r.tmpExpiration).Result(); err != nil {
+		return nil, err
+	}
+	if _, err = r.client.SAdd(r.getLeasePath(r.leaseID), key).Result(); err != nil {
+		return nil, err
+	}
+	r.tmpNodes.Set(key, data)
+	return value, r.notify(key, version)
+}
+
+//remove 删除节点并发送通知
+func (r *Redis) remove(key string) error {
+	if _, err := r.client.Del(key).Result(); err != nil {
+		return err
+	}
+	if _, ok := r.tmpNodes.Get(key); ok {
+		r.tmpNodes.Remove(key)
+		r.client.SRem(r.getLeasePath(r.leaseID), key)
+	}
+	version, err := r.nextVersion()
+	if err != nil {
+		return err
+	}
+	return r.notify(key, version)
+}
+
+//getLeasePath 获取租约下临时节点列表的key
+func (r *Redis) getLeasePath(id string) string {
+	return r.leasesPath + ":" + id
+}
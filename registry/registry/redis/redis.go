@@ -10,11 +10,22 @@ import (
 	"github.com/micro-plat/hydra/registry/registry/redis/internal"
 	"github.com/micro-plat/lib4go/concurrent/cmap"
 	"github.com/micro-plat/lib4go/types"
+	"github.com/micro-plat/lib4go/utility"
 )
 
+//maxEvents 变更流保留的最大记录数
+const maxEvents = 10000
+
+//Redis 基于redis的注册中心。节点值保存在路径对应的key中,版本号由全局修订号生成;
+//节点的所有变更写入变更流,监控者从最后读取的位置继续读取,连接中断时不丢失通知;
+//临时节点绑定到当前实例的租约,租约由心跳续期,实例异常退出后由其它实例清理过期租约的节点并发送删除通知
 type Redis struct {
 	closeCh       chan struct{}
 	seqPath       string
+	revPath       string
+	eventsPath    string
+	leasesPath    string
+	leaseID       string
 	once          sync.Once
 	maxSeq        int64
 	maxExpiration time.Duration
@@ -23,6 +34,7 @@ type Redis struct {
 	tmpNodes      cmap.ConcurrentMap
 	client        *internal.Client
 
+	wlock               sync.Mutex
 	valueWatcherMaps    map[string]*valueWatcher
 	childrenWatcherMaps map[string]*childrenWatcher
 }
@@ -44,6 +56,7 @@ func NewRedis(c *internal.ClientConf) (*Redis, error) {
 	if err != nil {
 		return nil, err
 	}
+	root := fmt.Sprintf("hydra/%s", global.Version)
 	redis := &Redis{
 		client:        rds,
 		maxExpiration: -1, //time.Hour * 24 * 365 * 10,
@@ -52,46 +65,62 @@ func NewRedis(c *internal.ClientConf) (*Redis, error) {
 		maxSeq:        9999999999,
 		tmpNodes:      cmap.New(4),
 		closeCh:       make(chan struct{}),
-		seqPath:       internal.SwapKey(fmt.Sprintf("hydra/%s/seq", global.Version)),
+		seqPath:       internal.SwapKey(root, "seq"),
+		revPath:       internal.SwapKey(root, "revision"),
+		eventsPath:    internal.SwapKey(root, "events"),
+		leasesPath:    internal.SwapKey(root, "leases"),
+		leaseID:       utility.GetGUID(),
 	}
 	redis.valueWatcherMaps = make(map[string]*valueWatcher)
 	redis.childrenWatcherMaps = make(map[string]*childrenWatcher)
 
+	lastID, lastVersion, err := redis.lastEventID()
+	if err != nil {
+		rds.Close()
+		return nil, err
+	}
+	if err := redis.renew(); err != nil {
+		rds.Close()
+		return nil, err
+	}
 	go redis.keepalive()
+	go redis.listen(lastID, lastVersion)
 	return redis, nil
 }
 
-
-//Close 关闭当前服务
+//Close 关闭当前服务,删除当前实例创建的临时节点并释放租约
 func (r *Redis) Close() error {
 	r.once.Do(func() {
+		for key := range r.tmpNodes.Items() {
+			r.remove(key)
+		}
+		r.client.ZRem(r.leasesPath, r.leaseID)
+		r.client.Del(r.getLeasePath(r.leaseID))
 		close(r.closeCh)
 		r.client.Close()
-		r.tmpNodes.Clear()
 	})
 	return nil
 }
 
-func (r *Redis) keepalive() {
-	tk := time.NewTicker(r.checkTicker)
-	for {
-		select {
-		case <-r.closeCh:
-			r.tmpNodes.RemoveIterCb(func(key string, v interface{}) bool {
-				r.Delete(key)
-				return true
-			})
-			return
-		case <-tk.C:
-			items := r.tmpNodes.Items()
-			for k := range items {
-				exists, err := r.client.Exists(k).Result()
-				if exists > 0 && err == nil {
-					r.client.Expire(k, r.tmpExpiration).Result()
-				}
-			}
-		}
+//nextVersion 获取新的修订号作为节点版本号
+func (r *Redis) nextVersion() (int64, error) {
+	rev, err := r.client.Incr(r.revPath).Result()
+	if err != nil {
+		return 0, fmt.Errorf("获取修订号失败:%w", err)
+	}
+	return rev, nil
+}
+
+//getValue 获取节点值,节点不存在时返回nil
+func (r *Redis) getValue(key string) (*value, error) {
+	buff, err := r.client.Get(key).Result()
+	if err == internal.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return newValueByJSON(buff)
 }
 
 //redisFactory 基于redis的注册中心
@@ -107,9 +136,10 @@ func (z *redisFactory) Create(opts ...r.Option) (r.IRegistry, error) {
 	conf := &internal.ClientConf{
 		Address:    z.opts.Addrs,
 		Password:   z.opts.Auth.Password,
-		MasterName: z.opts.Auth.Username,
+		MasterName: types.GetString(z.opts.Metadata["master"], z.opts.Auth.Username),
 		Db:         types.GetInt(z.opts.Metadata["db"]),
 		PoolSize:   types.GetMax(types.GetInt(z.opts.Metadata["pool_size"]), 10),
+		Mode:       z.opts.Metadata["mode"],
 	}
 	return NewRedis(conf)
 }
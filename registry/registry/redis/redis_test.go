@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rds "github.com/go-redis/redis"
+	"github.com/micro-plat/hydra/registry/registry/redis/internal"
+	"github.com/micro-plat/lib4go/assert"
+	"github.com/micro-plat/lib4go/registry"
+)
+
+func newTestRedis(t *testing.T, addr string) *Redis {
+	r, err := NewRedisBy("", "", []string{addr}, 0, 10)
+	assert.Equal(t, nil, err, "创建注册中心")
+	return r
+}
+
+func waitValue(ch chan registry.ValueWatcher, timeout time.Duration) (registry.ValueWatcher, bool) {
+	select {
+	case v := <-ch:
+		return v, true
+	case <-time.After(timeout):
+		return nil, false
+	}
+}
+
+func TestRedis_Lease(t *testing.T) {
+	m, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer m.Close()
+	a := newTestRedis(t, m.Addr())
+	defer a.Close()
+	b := newTestRedis(t, m.Addr())
+	defer b.Close()
+
+	//其它客户端删除的临时节点不重新创建
+	assert.Equal(t, nil, a.CreateTempNode("/lease/deleted", "1"), "1. 创建临时节点")
+	assert.Equal(t, nil, b.Delete("/lease/deleted"), "1. 其它客户端删除临时节点")
+	assert.Equal(t, nil, a.renew(), "1. 续期租约")
+	ok, _ := a.Exists("/lease/deleted")
+	assert.Equal(t, false, ok, "1. 已删除的临时节点不重新创建")
+	_, ok = a.tmpNodes.Get(internal.SwapKey("/lease/deleted"))
+	assert.Equal(t, false, ok, "1. 已删除的临时节点不再续期")
+
+	//租约过期的临时节点由其它实例清理
+	assert.Equal(t, nil, a.CreateTempNode("/lease/expired", "1"), "2. 创建临时节点")
+	_, err = a.client.ZAdd(a.leasesPath, rds.Z{Score: 1, Member: a.leaseID}).Result()
+	assert.Equal(t, nil, err, "2. 租约过期")
+	b.reap()
+	ok, _ = b.Exists("/lease/expired")
+	assert.Equal(t, false, ok, "2. 清理过期租约的临时节点")
+	_, err = b.client.ZScore(b.leasesPath, a.leaseID).Result()
+	assert.Equal(t, internal.Nil, err, "2. 移除过期租约")
+
+	//租约过期后恢复时重新创建临时节点
+	assert.Equal(t, nil, a.renew(), "3. 续期租约")
+	ok, _ = a.Exists("/lease/expired")
+	assert.Equal(t, true, ok, "3. 租约过期后重新创建临时节点")
+	ok, _ = a.Exists("/lease/deleted")
+	assert.Equal(t, false, ok, "3. 已删除的临时节点不重新创建")
+}
+
+func TestRedis_StreamResume(t *testing.T) {
+	m, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer m.Close()
+	a := newTestRedis(t, m.Addr())
+	defer a.Close()
+	b := newTestRedis(t, m.Addr())
+	defer b.Close()
+
+	assert.Equal(t, nil, b.CreatePersistentNode("/stream/value", "1"), "1. 创建节点")
+	ch, err := a.WatchValue("/stream/value")
+	assert.Equal(t, nil, err, "1. 监控节点")
+	assert.Equal(t, nil, b.Update("/stream/value", "2"), "1. 修改节点")
+	v, ok := waitValue(ch, time.Second*3)
+	assert.Equal(t, true, ok, "1. 通知节点变更")
+	data, _ := v.GetValue()
+	assert.Equal(t, "2", string(data), "1. 通知节点变更")
+
+	//连接中断恢复后从最后读取的位置继续读取
+	ch, err = a.WatchValue("/stream/value")
+	assert.Equal(t, nil, err, "2. 监控节点")
+	m.Close()
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, nil, m.Restart(), "2. 恢复连接")
+	for i := 0; i < 10; i++ {
+		if _, err = b.client.Ping().Result(); err == nil {
+			break
+		}
+	}
+	assert.Equal(t, nil, err, "2. 恢复连接")
+	assert.Equal(t, nil, b.Update("/stream/value", "3"), "2. 修改节点")
+	v, ok = waitValue(ch, time.Second*5)
+	assert.Equal(t, true, ok, "2. 连接恢复后通知节点变更")
+	data, _ = v.GetValue()
+	assert.Equal(t, "3", string(data), "2. 连接恢复后通知节点变更")
+}
+
+func TestRedis_StreamResync(t *testing.T) {
+	m, err := miniredis.Run()
+	assert.Equal(t, nil, err, "启动redis")
+	defer m.Close()
+	a := newTestRedis(t, m.Addr())
+	defer a.Close()
+	b := newTestRedis(t, m.Addr())
+	defer b.Close()
+
+	assert.Equal(t, nil, b.CreatePersistentNode("/resync/value", "1"), "1. 创建节点")
+	ch, err := a.WatchValue("/resync/value")
+	assert.Equal(t, nil, err, "1. 监控节点")
+	time.Sleep(time.Millisecond * 200)
+
+	//修改节点的变更记录已被截断,监控者只能读取到后续变更
+	key := internal.SwapKey("/resync/value")
+	version, err := b.nextVersion()
+	assert.Equal(t, nil, err, "2. 获取修订号")
+	_, err = b.client.Set(key, newValue("2", "", version).String(), b.maxExpiration).Result()
+	assert.Equal(t, nil, err, "2. 修改节点且变更记录被截断")
+	assert.Equal(t, nil, b.CreatePersistentNode("/resync/other", "1"), "2. 其它节点变更")
+
+	v, ok := waitValue(ch, time.Second*3)
+	assert.Equal(t, true, ok, "3. 修订号不连续时核对监控并通知变更")
+	data, _ := v.GetValue()
+	assert.Equal(t, "2", string(data), "3. 修订号不连续时核对监控并通知变更")
+}
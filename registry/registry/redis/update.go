@@ -10,41 +10,36 @@ import (
 func (r *Redis) Update(path string, data string) (err error) {
 
 	//获取原数据
-	exists, err := r.client.Exists(path).Result()
+	key := internal.SwapKey(path)
+	ovalue, err := r.getValue(key)
 	if err != nil {
 		return fmt.Errorf("检查节点出错:%w", err)
 	}
-	if exists > 0 {
-		return fmt.Errorf("节点不存在%s", internal.SwapKey(path))
+	if ovalue == nil {
+		return fmt.Errorf("节点不存在%s", path)
 	}
 
-	//获取原数据
-	buff, err := r.client.Get(internal.SwapKey(path)).Result()
-	if err != nil {
-		return err
-	}
-
-	//解析并判断节点类型
-	ovalue, err := newValueByJSON(buff)
-	if err != nil {
-		return err
-	}
-
-	//超时时长
+	//临时节点保持原租约
 	exp := r.maxExpiration
 	if ovalue.IsTemp {
 		exp = r.tmpExpiration
 	}
+	version, err := r.nextVersion()
+	if err != nil {
+		return err
+	}
 
 	//构建新对象，并修改
-	value := newValue(data, ovalue.IsTemp)
-	_, err = r.client.Set(internal.SwapKey(path), value.String(), exp).Result() //? timeout
+	value := newValue(data, ovalue.Lease, version)
+	value.IsTemp = ovalue.IsTemp
+	_, err = r.client.Set(key, value.String(), exp).Result()
 	if err != nil {
 		return err
 	}
+	if _, ok := r.tmpNodes.Get(key); ok {
+		r.tmpNodes.Set(key, data)
+	}
 
 	//通知变更
-	r.notifyValueChange(path, value)
-	return nil
-
+	return r.notify(key, version)
 }
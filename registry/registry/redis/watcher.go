@@ -1,113 +1,196 @@
 package redis
 
 import (
-	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
+	rds "github.com/go-redis/redis"
 	"github.com/micro-plat/hydra/registry/registry/redis/internal"
 	"github.com/micro-plat/lib4go/registry"
+	"github.com/micro-plat/lib4go/types"
 )
 
+//valueWatcher 值监控,version为开始监控时节点的版本号
 type valueWatcher struct {
+	version int64
 	watcher chan registry.ValueWatcher
 }
 
+//childrenWatcher 子节点监控,children为开始监控时的子节点
 type childrenWatcher struct {
-	watcher chan registry.ChildrenWatcher
+	children []string
+	watcher  chan registry.ChildrenWatcher
 }
 
-//WatchValue 监控值变化
+//WatchValue 监控值变化,节点版本号与开始监控时不同时通知一次
 func (r *Redis) WatchValue(path string) (data chan registry.ValueWatcher, err error) {
-	watchkey := internal.SwapKey(path, "watch")
-
-	if watcher, ok := r.valueWatcherMaps[watchkey]; ok {
+	key := internal.SwapKey(path)
+	value, err := r.getValue(key)
+	if err != nil {
+		return nil, err
+	}
+	r.wlock.Lock()
+	defer r.wlock.Unlock()
+	if watcher, ok := r.valueWatcherMaps[key]; ok {
 		return watcher.watcher, nil
 	}
-	watcher := make(chan registry.ValueWatcher, 1)
+	watcher := &valueWatcher{watcher: make(chan registry.ValueWatcher, 1)}
+	if value != nil {
+		watcher.version = value.Version
+	}
+	r.valueWatcherMaps[key] = watcher
+	return watcher.watcher, nil
+}
 
-	r.valueWatcherMaps[watchkey] = &valueWatcher{
-		watcher: watcher,
+//WatchChildren 监控子节点变化,子节点与开始监控时不同时通知一次
+func (r *Redis) WatchChildren(path string) (data chan registry.ChildrenWatcher, err error) {
+	key := internal.SwapKey(path)
+	children, _, err := r.GetChildren(path)
+	if err != nil {
+		return nil, err
 	}
+	r.wlock.Lock()
+	defer r.wlock.Unlock()
+	if watcher, ok := r.childrenWatcherMaps[key]; ok {
+		return watcher.watcher, nil
+	}
+	sort.Strings(children)
+	watcher := &childrenWatcher{children: children, watcher: make(chan registry.ChildrenWatcher, 1)}
+	r.childrenWatcherMaps[key] = watcher
+	return watcher.watcher, nil
+}
 
-	pubsub := r.client.Subscribe(watchkey)
-	msgChan := pubsub.Channel()
+//notify 将节点变更写入变更流
+func (r *Redis) notify(key string, version int64) error {
+	_, err := r.client.XAdd(&rds.XAddArgs{
+		Stream:       r.eventsPath,
+		MaxLenApprox: maxEvents,
+		Values:       map[string]interface{}{"path": key, "version": version},
+	}).Result()
+	return err
+}
 
-	//等待值变化
+//lastEventID 获取变更流中最后一条记录的编号及修订号
+func (r *Redis) lastEventID() (string, int64, error) {
+	msgs, err := r.client.XRevRangeN(r.eventsPath, "+", "-", 1).Result()
+	if err != nil {
+		return "", 0, err
+	}
+	if len(msgs) == 0 {
+		return "0-0", 0, nil
+	}
+	return msgs[0].ID, types.GetInt64(msgs[0].Values["version"]), nil
+}
 
-	go func() {
-		for {
+//listen 从lastID之后读取变更流并通知监控者。读取出错时从最后读取的位置继续读取;
+//每次变更的修订号连续递增,修订号不连续时(变更流被截断或读取中断)与当前数据核对所有监控,避免丢失通知
+func (r *Redis) listen(lastID string, lastVersion int64) {
+	broken := false
+	for {
+		select {
+		case <-r.closeCh:
+			return
+		default:
+		}
+		streams, err := r.client.XRead(&rds.XReadArgs{
+			Streams: []string{r.eventsPath, lastID},
+			Count:   100,
+			Block:   r.checkTicker,
+		}).Result()
+		if err != nil && err != internal.Nil {
+			broken = true
 			select {
-			case <-r.closeCh: //系统退出
+			case <-r.closeCh:
 				return
-			case msg := <-msgChan:
-				nv, err := newValueByJSON(msg.Payload)
-				if err != nil { //有错误，退出
-					watcher <- &valueEntity{Err: err}
-					continue
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				version := types.GetInt64(msg.Values["version"])
+				if lastVersion > 0 && version != lastVersion+1 {
+					broken = true
 				}
-				watcher <- &valueEntity{path: path, version: nv.Version, Value: nv.Data, Err: err}
+				lastID, lastVersion = msg.ID, version
+				r.dispatch(types.GetString(msg.Values["path"]), version)
 			}
 		}
-
-	}()
-	return watcher, nil
-}
-
-//notifyValueChange 通知订阅者值已发生变化
-func (r *Redis) notifyValueChange(path string, value *value) {
-	key := internal.SwapKey(path, "watch") //保存所有watcher编号
-	r.client.Publish(key, value.String())
+		if broken {
+			broken = false
+			r.resync()
+		}
+	}
 }
 
-//WatchChildren 监控子节点变化，保存订阅者信息
-func (r *Redis) WatchChildren(path string) (data chan registry.ChildrenWatcher, err error) {
-
-	watchkey := internal.SwapKey(path, "watch") //保存所有watcher编号
-
-	if watcher, ok := r.childrenWatcherMaps[watchkey]; ok {
-		return watcher.watcher, nil
+//dispatch 通知节点的值监控及所有上级节点的子节点监控
+func (r *Redis) dispatch(key string, version int64) {
+	r.wlock.Lock()
+	defer r.wlock.Unlock()
+	if w, ok := r.valueWatcherMaps[key]; ok && w.version != version {
+		r.fireValue(key, w)
 	}
-	//等待值变化
-	watcher := make(chan registry.ChildrenWatcher, 1)
-
-	r.childrenWatcherMaps[watchkey] = &childrenWatcher{
-		watcher: watcher,
+	names := internal.SplitKey(key)
+	for i := 1; i < len(names); i++ {
+		parent := strings.Join(names[:i], ":")
+		if w, ok := r.childrenWatcherMaps[parent]; ok {
+			r.checkChildren(parent, w)
+		}
 	}
+}
 
-	pubsub := r.client.Subscribe(watchkey)
-	msgChan := pubsub.Channel()
-
-	go func() {
-		for {
-			select {
-			case <-r.closeCh: //系统退出
-				return
-			case <-msgChan:
-				children, ver, err := r.GetChildren(path)
-				if err != nil { //有错误，退出
-					watcher <- &childrenEntity{Err: err}
-					continue
-				}
-				watcher <- &childrenEntity{path: path, version: ver, children: children, Err: err}
-			}
+//resync 核对所有监控的节点版本号及子节点,发生变化时通知
+func (r *Redis) resync() {
+	r.wlock.Lock()
+	defer r.wlock.Unlock()
+	for key, w := range r.valueWatcherMaps {
+		value, err := r.getValue(key)
+		if err != nil {
+			continue
+		}
+		if value == nil || value.Version != w.version {
+			r.fireValue(key, w)
 		}
+	}
+	for key, w := range r.childrenWatcherMaps {
+		r.checkChildren(key, w)
+	}
+}
 
-	}()
-	return watcher, nil
+//fireValue 发送节点当前值并移除监控,调用前须获取wlock
+func (r *Redis) fireValue(key string, w *valueWatcher) {
+	path := internal.SwapPath(key)
+	entity := &valueEntity{path: path}
+	value, err := r.getValue(key)
+	switch {
+	case err != nil:
+		entity.Err = err
+	case value != nil:
+		entity.Value, entity.version = value.Data, int32(value.Version)
+	}
+	select {
+	case w.watcher <- entity:
+	default:
+	}
+	delete(r.valueWatcherMaps, key)
 }
 
-//notifyParentChange 通知订阅者值已发生变化
-func (r *Redis) notifyParentChange(path string, version int32) {
-	npath := internal.SplitKey(path)
-	parent := internal.SwapKey(npath[:len(npath)-1]...)
-	_, ok := r.childrenWatcherMaps[parent]
-	if !ok {
+//checkChildren 子节点发生变化时发送当前子节点并移除监控,调用前须获取wlock
+func (r *Redis) checkChildren(key string, w *childrenWatcher) {
+	path := internal.SwapPath(key)
+	children, version, err := r.GetChildren(path)
+	if err != nil {
 		return
 	}
-
-	bytes, _ := json.Marshal(map[string]string{
-		"path": parent,
-	})
-
-	r.client.Publish(parent, string(bytes))
-	return
+	sort.Strings(children)
+	if reflect.DeepEqual(children, w.children) {
+		return
+	}
+	select {
+	case w.watcher <- &childrenEntity{path: path, version: version, children: children}:
+	default:
+	}
+	delete(r.childrenWatcherMaps, key)
 }
@@ -0,0 +1,85 @@
+package replica
+
+import (
+	"time"
+
+	"github.com/micro-plat/lib4go/logger"
+)
+
+//冲突处理策略,目标节点已存在且值不同时的处理方式
+const (
+	//Skip 保留目标节点的值
+	Skip = "skip"
+
+	//Overwrite 使用源节点的值覆盖
+	Overwrite = "overwrite"
+
+	//Fail 返回错误
+	Fail = "fail"
+)
+
+//defExcludes 默认排除的临时节点:服务器及服务发布节点、dns、分布式锁及zookeeper系统节点
+var defExcludes = []string{
+	"/*/*/*/*/servers/**",
+	"/*/services/**",
+	"/dns/**",
+	"/dlock/**",
+	"/zookeeper/**",
+}
+
+//defInterval 镜像模式全量同步的默认间隔
+const defInterval = time.Minute
+
+type options struct {
+	policy       string
+	excludes     []string
+	noDefExclude bool
+	interval     time.Duration
+	dryRun       bool
+	log          logger.ILogging
+}
+
+//Option 配置选项
+type Option func(*options)
+
+//WithPolicy 设置冲突处理策略,默认为Skip
+func WithPolicy(policy string) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+//WithExcludes 添加排除的路径,支持*匹配一段路径,**匹配多段路径
+func WithExcludes(patterns ...string) Option {
+	return func(o *options) {
+		o.excludes = append(o.excludes, patterns...)
+	}
+}
+
+//WithoutDefExcludes 不排除默认的临时节点
+func WithoutDefExcludes() Option {
+	return func(o *options) {
+		o.noDefExclude = true
+	}
+}
+
+//WithInterval 设置镜像模式全量同步的间隔
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+//WithDryRun 只检查变更,不写入目标注册中心
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+//WithLogger 设置日志组件
+func WithLogger(log logger.ILogging) Option {
+	return func(o *options) {
+		o.log = log
+	}
+}
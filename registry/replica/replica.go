@@ -0,0 +1,334 @@
+package replica
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-plat/hydra/global"
+	"github.com/micro-plat/hydra/registry"
+)
+
+//Result 复制结果
+type Result struct {
+	Created   int
+	Updated   int
+	Deleted   int
+	Skipped   int
+	Unchanged int
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("创建:%d 更新:%d 删除:%d 跳过:%d 未变化:%d", r.Created, r.Updated, r.Deleted, r.Skipped, r.Unchanged)
+}
+
+//Replicator 在注册中心之间复制节点,通过GetChildren/GetValue遍历源注册中心,在目标注册中心创建持久节点,
+//镜像模式下通过WatchChildren/WatchValue监控源注册中心的变化并同步到目标注册中心
+type Replicator struct {
+	from     registry.IRegistry
+	to       registry.IRegistry
+	opts     *options
+	mu       sync.Mutex
+	copied   map[string]bool
+	watching map[string]bool
+}
+
+//New 构建注册中心复制器
+func New(from registry.IRegistry, to registry.IRegistry, opts ...Option) *Replicator {
+	o := &options{policy: Skip, interval: defInterval, log: global.Def.Log()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !o.noDefExclude {
+		o.excludes = append(append([]string{}, defExcludes...), o.excludes...)
+	}
+	return &Replicator{
+		from:     from,
+		to:       to,
+		opts:     o,
+		copied:   make(map[string]bool),
+		watching: make(map[string]bool),
+	}
+}
+
+//Copy 复制指定路径(默认为根路径)下的所有节点
+func (r *Replicator) Copy(roots ...string) (*Result, error) {
+	switch r.opts.policy {
+	case Skip, Overwrite, Fail:
+	default:
+		return nil, fmt.Errorf("不支持的冲突处理策略:%s", r.opts.policy)
+	}
+	result := &Result{}
+	for _, root := range getRoots(roots) {
+		if err := r.copyTree(root, r.opts.policy, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+//Mirror 复制节点后持续监控源注册中心的变化并同步到目标注册中心,直到done关闭。
+//源节点的修改直接覆盖目标节点,源节点删除时只删除由当前复制器创建或更新的目标节点
+func (r *Replicator) Mirror(done <-chan struct{}, roots ...string) error {
+	if r.opts.interval <= 0 {
+		return fmt.Errorf("全量同步的间隔必须大于0:%v", r.opts.interval)
+	}
+	roots = getRoots(roots)
+	if _, err := r.Copy(roots...); err != nil {
+		return err
+	}
+	events := make(chan string, 64)
+	for _, root := range roots {
+		r.watchTree(root, events, done)
+	}
+	ticker := time.NewTicker(r.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return nil
+		case p := <-events:
+			if err := r.sync(p); err != nil {
+				r.opts.log.Errorf("同步节点%s失败:%v", p, err)
+			}
+			r.watchTree(p, events, done)
+		case <-ticker.C:
+			for _, root := range roots {
+				if err := r.sync(root); err != nil {
+					r.opts.log.Errorf("同步节点%s失败:%v", root, err)
+				}
+				r.watchTree(root, events, done)
+			}
+		}
+	}
+}
+
+//sync 以源注册中心为准同步节点及所有子节点
+func (r *Replicator) sync(p string) error {
+	result := &Result{}
+	ok, err := r.from.Exists(p)
+	if err != nil {
+		return err
+	}
+	if ok {
+		err = r.copyTree(p, Overwrite, result)
+	}
+	if err == nil {
+		err = r.prune(p, result)
+	}
+	if result.Created+result.Updated+result.Deleted > 0 {
+		r.opts.log.Infof("同步节点%s %s", p, result)
+	}
+	return err
+}
+
+//copyTree 复制节点及所有子节点,父节点先于子节点创建
+func (r *Replicator) copyTree(p string, policy string, result *Result) error {
+	if r.excluded(p) {
+		return nil
+	}
+	if p != "/" {
+		data, _, err := r.from.GetValue(p)
+		if err != nil {
+			return fmt.Errorf("获取节点%s的值失败:%w", p, err)
+		}
+		if err := r.copyNode(p, string(data), policy, result); err != nil {
+			return err
+		}
+	}
+	children, _, err := r.from.GetChildren(p)
+	if err != nil {
+		return fmt.Errorf("获取节点%s的子节点失败:%w", p, err)
+	}
+	sort.Strings(children)
+	for _, c := range children {
+		if err := r.copyTree(registry.Join(p, c), policy, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Replicator) copyNode(p string, data string, policy string, result *Result) error {
+	ok, err := r.to.Exists(p)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		result.Created++
+		r.opts.log.Debugf("创建节点:%s", p)
+		if r.opts.dryRun {
+			return nil
+		}
+		if err := r.to.CreatePersistentNode(p, data); err != nil {
+			return fmt.Errorf("创建节点%s失败:%w", p, err)
+		}
+		r.markCopied(p, true)
+		return nil
+	}
+	current, _, err := r.to.GetValue(p)
+	if err != nil {
+		return err
+	}
+	if string(current) == data {
+		result.Unchanged++
+		return nil
+	}
+	switch policy {
+	case Skip:
+		result.Skipped++
+		r.opts.log.Debugf("跳过已存在的节点:%s", p)
+		return nil
+	case Fail:
+		return fmt.Errorf("目标注册中心已存在节点%s且值不同", p)
+	}
+	result.Updated++
+	r.opts.log.Debugf("更新节点:%s", p)
+	if r.opts.dryRun {
+		return nil
+	}
+	if err := r.to.Update(p, data); err != nil {
+		return fmt.Errorf("更新节点%s失败:%w", p, err)
+	}
+	r.markCopied(p, true)
+	return nil
+}
+
+//prune 删除源注册中心已不存在的由当前复制器创建或更新的节点,子节点先于父节点删除
+func (r *Replicator) prune(p string, result *Result) error {
+	r.mu.Lock()
+	paths := make([]string, 0, len(r.copied))
+	for c := range r.copied {
+		if c == p || strings.HasPrefix(c, strings.TrimSuffix(p, "/")+"/") {
+			paths = append(paths, c)
+		}
+	}
+	r.mu.Unlock()
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	for _, c := range paths {
+		ok, err := r.from.Exists(c)
+		if err != nil || ok {
+			continue
+		}
+		result.Deleted++
+		r.opts.log.Debugf("删除节点:%s", c)
+		if err := r.to.Delete(c); err != nil {
+			return fmt.Errorf("删除节点%s失败:%w", c, err)
+		}
+		r.markCopied(c, false)
+	}
+	return nil
+}
+
+//watchTree 监控节点及所有子节点的值及子节点变化,已在监控中的节点不重复监控
+func (r *Replicator) watchTree(p string, events chan<- string, done <-chan struct{}) {
+	if r.excluded(p) {
+		return
+	}
+	r.mu.Lock()
+	watching := r.watching[p]
+	r.watching[p] = true
+	r.mu.Unlock()
+	if !watching {
+		if err := r.watch(p, events, done); err != nil {
+			r.opts.log.Warnf("监控节点%s失败:%v", p, err)
+			r.mu.Lock()
+			delete(r.watching, p)
+			r.mu.Unlock()
+		}
+	}
+	children, _, err := r.from.GetChildren(p)
+	if err != nil {
+		return
+	}
+	for _, c := range children {
+		r.watchTree(registry.Join(p, c), events, done)
+	}
+}
+
+//watch 监控节点变化,收到通知后发送节点路径并取消监控标记,由同步后的watchTree重新监控
+func (r *Replicator) watch(p string, events chan<- string, done <-chan struct{}) error {
+	values, err := r.from.WatchValue(p)
+	if err != nil {
+		return err
+	}
+	children, err := r.from.WatchChildren(p)
+	if err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-values:
+		case <-children:
+		case <-done:
+			return
+		}
+		r.mu.Lock()
+		delete(r.watching, p)
+		r.mu.Unlock()
+		select {
+		case events <- p:
+		case <-done:
+		}
+	}()
+	return nil
+}
+
+func (r *Replicator) markCopied(p string, copied bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if copied {
+		r.copied[p] = true
+		return
+	}
+	delete(r.copied, p)
+}
+
+//excluded 节点是否被排除
+func (r *Replicator) excluded(p string) bool {
+	for _, pattern := range r.opts.excludes {
+		if match(registry.Split(pattern), registry.Split(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+//match 按段匹配路径,*匹配一段,**匹配零或多段
+func match(patterns []string, paths []string) bool {
+	if len(patterns) == 0 {
+		return len(paths) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(paths); i++ {
+			if match(patterns[1:], paths[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(paths) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(patterns[0], paths[0]); !ok {
+		return false
+	}
+	return match(patterns[1:], paths[1:])
+}
+
+func getRoots(roots []string) []string {
+	if len(roots) == 0 {
+		return []string{"/"}
+	}
+	list := make([]string, 0, len(roots))
+	for _, root := range roots {
+		if root = registry.Join(root); root == "" {
+			root = "/"
+		}
+		list = append(list, root)
+	}
+	return list
+}
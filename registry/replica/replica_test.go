@@ -0,0 +1,113 @@
+package replica
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro-plat/hydra/registry"
+	"github.com/micro-plat/hydra/registry/registry/localmemory"
+	"github.com/micro-plat/lib4go/assert"
+)
+
+func TestCopy(t *testing.T) {
+	from := localmemory.NewLocalMemory()
+	from.CreatePersistentNode("/p/s/api/t/conf", `{"address":":8080"}`)
+	from.CreatePersistentNode("/p/s/api/t/conf/router", `{"routers":[]}`)
+	from.CreatePersistentNode("/p/s/api/t/servers/192.168.0.1", `{}`)
+	from.CreatePersistentNode("/p/var/db/db", `{"provider":"mysql"}`)
+
+	to := localmemory.NewLocalMemory()
+	to.CreatePersistentNode("/p/var/db/db", `{"provider":"oracle"}`)
+
+	result, err := New(from, to, WithDryRun()).Copy()
+	assert.Equal(t, nil, err, "1. 只检查变更")
+	assert.Equal(t, 1, result.Skipped, "1. 只检查变更")
+	ok, _ := to.Exists("/p/s/api/t/conf")
+	assert.Equal(t, false, ok, "1. 只检查变更不写入")
+
+	result, err = New(from, to).Copy()
+	assert.Equal(t, nil, err, "2. 复制节点")
+	assert.Equal(t, 1, result.Skipped, "2. 已存在的节点默认跳过")
+	data, _, _ := to.GetValue("/p/s/api/t/conf/router")
+	assert.Equal(t, `{"routers":[]}`, string(data), "2. 复制节点")
+	data, _, _ = to.GetValue("/p/var/db/db")
+	assert.Equal(t, `{"provider":"oracle"}`, string(data), "2. 已存在的节点默认跳过")
+	ok, _ = to.Exists("/p/s/api/t/servers/192.168.0.1")
+	assert.Equal(t, false, ok, "2. 默认排除临时节点")
+
+	_, err = New(from, to, WithPolicy(Fail)).Copy("/p/var")
+	assert.NotEqual(t, nil, err, "3. 已存在的节点返回错误")
+
+	result, err = New(from, to, WithPolicy(Overwrite), WithExcludes("/p/s/**")).Copy("/p")
+	assert.Equal(t, nil, err, "4. 覆盖已存在的节点")
+	assert.Equal(t, 1, result.Updated, "4. 覆盖已存在的节点")
+	data, _, _ = to.GetValue("/p/var/db/db")
+	assert.Equal(t, `{"provider":"mysql"}`, string(data), "4. 覆盖已存在的节点")
+
+	_, err = New(from, to, WithPolicy("merge")).Copy()
+	assert.NotEqual(t, nil, err, "5. 不支持的冲突处理策略")
+}
+
+func TestMirror(t *testing.T) {
+	from := localmemory.NewLocalMemory()
+	from.CreatePersistentNode("/p/var/db/db", `{"provider":"mysql"}`)
+	to := localmemory.NewLocalMemory()
+
+	done := make(chan struct{})
+	defer close(done)
+	go New(from, to, WithInterval(time.Millisecond*200)).Mirror(done, "/p")
+	waitValue(t, to, "/p/var/db/db", `{"provider":"mysql"}`, "1. 复制节点")
+
+	from.Update("/p/var/db/db", `{"provider":"oracle"}`)
+	waitValue(t, to, "/p/var/db/db", `{"provider":"oracle"}`, "2. 同步修改")
+
+	from.CreatePersistentNode("/p/var/cache/redis", `{"proto":"redis"}`)
+	waitValue(t, to, "/p/var/cache/redis", `{"proto":"redis"}`, "3. 同步新增")
+
+	from.Delete("/p/var/cache/redis")
+	waitValue(t, to, "/p/var/cache/redis", "", "4. 同步删除")
+}
+
+func TestMirror_Interval(t *testing.T) {
+	from := localmemory.NewLocalMemory()
+	to := localmemory.NewLocalMemory()
+	done := make(chan struct{})
+	defer close(done)
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := New(from, to, WithInterval(interval)).Mirror(done, "/p")
+		assert.Equal(t, true, err != nil, "全量同步间隔不大于0时返回错误", interval)
+	}
+}
+
+func waitValue(t *testing.T, r interface {
+	GetValue(path string) ([]byte, int32, error)
+}, path string, value string, name string) {
+	deadline := time.Now().Add(time.Second * 3)
+	for time.Now().Before(deadline) {
+		data, _, _ := r.GetValue(path)
+		if string(data) == value {
+			return
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+	t.Errorf("%s:超时未同步节点%s", name, path)
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{name: "1. 完全匹配", pattern: "/p/var", path: "/p/var", want: true},
+		{name: "2. *匹配一段", pattern: "/*/services/*", path: "/p/services/api", want: true},
+		{name: "3. *不匹配多段", pattern: "/*/services/*", path: "/p/services/api/a", want: false},
+		{name: "4. **匹配零段", pattern: "/dlock/**", path: "/dlock", want: true},
+		{name: "5. **匹配多段", pattern: "/*/*/*/*/servers/**", path: "/p/s/api/t/servers/192.168.0.1", want: true},
+		{name: "6. 不匹配", pattern: "/*/*/*/*/servers/**", path: "/p/s/api/t/conf", want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, match(registry.Split(tt.pattern), registry.Split(tt.path)), tt.name)
+	}
+}
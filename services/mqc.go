@@ -2,6 +2,7 @@ package services
 
 import (
 	"sync"
+	"time"
 
 	"github.com/micro-plat/hydra/conf/server/queue"
 	"github.com/micro-plat/hydra/global"
@@ -12,6 +13,19 @@ import (
 type IMQC interface {
 	Add(mqName string, service string, concurrency ...int) IMQC
 	Remove(mqName string, service string) IMQC
+
+	//OnLag 消息积压告警勾子，队列积压持续超过阈值时执行
+	OnLag(h func(*MQCLag)) IMQC
+}
+
+//MQCLag 消息队列积压信息
+type MQCLag struct {
+	Queue    string    `json:"queue"`
+	Backlog  int64     `json:"backlog"`
+	Rate     float64   `json:"rate"`
+	Exceeded bool      `json:"exceeded"`
+	Since    time.Time `json:"since,omitempty"`
+	Time     time.Time `json:"time"`
 }
 type mqcSubscriber struct {
 	callback  func(t *queue.Queue)
@@ -28,6 +42,7 @@ type mqc struct {
 	lock          sync.Mutex
 	staticLock    sync.Mutex
 	signalChan    chan struct{}
+	lagHooks      []func(*MQCLag)
 }
 
 func newMQC() *mqc {
@@ -56,6 +71,24 @@ func (c *mqc) Remove(mqName string, service string) IMQC {
 	return c.add(mqName, service, true, 0)
 }
 
+//OnLag 添加消息积压告警勾子
+func (c *mqc) OnLag(h func(*MQCLag)) IMQC {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lagHooks = append(c.lagHooks, h)
+	return c
+}
+
+//DoLag 执行消息积压告警勾子
+func (c *mqc) DoLag(lag *MQCLag) {
+	c.lock.Lock()
+	hooks := c.lagHooks
+	c.lock.Unlock()
+	for _, h := range hooks {
+		h(lag)
+	}
+}
+
 //Subscribe 订阅任务
 func (c *mqc) Subscribe(f func(t *queue.Queue)) {
 	subscriber := &mqcSubscriber{